@@ -1,6 +1,9 @@
 package utils
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+)
 
 func Respond(c *gin.Context, status int, body interface{}) {
 	if c.GetHeader("Accept") == "application/xml"{
@@ -11,9 +14,5 @@ func Respond(c *gin.Context, status int, body interface{}) {
 }
 
 func RespondError(c *gin.Context, err *ApplicationError) {
-	if c.GetHeader("Accept") == "application/xml"{
-		c.XML(err.StatusCode, err)
-		return
-	}
-	c.JSON(err.StatusCode, err)
-}
\ No newline at end of file
+	errors.RespondError(c, err.ApiError())
+}
@@ -1,8 +1,13 @@
 package utils
 
+import "github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
 
 type ApplicationError struct{
 	Message 	string `json:"message"`
 	StatusCode	int    `json:"status"`
 	Code		string `json:"code"`
-}
\ No newline at end of file
+}
+
+func (e *ApplicationError) ApiError() errors.ApiError {
+	return errors.NewApiErrorWithCode(e.StatusCode, e.Code, e.Message)
+}
@@ -12,12 +12,12 @@ func CreateAccessToken(c *gin.Context) {
 	var request oauth.AccessTokenRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		apiErr := errors.NewBadRequestError("invalid json body")
-		c.JSON(apiErr.Status(), apiErr)
+		errors.RespondError(c, apiErr)
 		return
 	}
 	token, err := services.OauthService.CreateAccessToken(request)
 	if err != nil {
-		c.JSON(err.Status(), err)
+		errors.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, token)
@@ -26,7 +26,7 @@ func CreateAccessToken(c *gin.Context) {
 func GetAccessToken(c *gin.Context) {
 	token, err := services.OauthService.GetAccessToken(c.Param("token_id"))
 	if err != nil {
-		c.JSON(err.Status(), err)
+		errors.RespondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, token)
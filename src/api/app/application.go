@@ -1,7 +1,10 @@
 package app
 
 import (
+	"context"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
 	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_a"
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,9 +17,13 @@ func init() {
 }
 
 func StartApp() {
+	if _, err := config.GetSecretStore(); err != nil {
+		panic(err)
+	}
 	option_a.Info("about to map the urls","step:01", "status:pending")
 	mapUrls()
 	option_a.Info("urls successfully mapped", "step:02", "status:success")
+	services.StartDriftReconciler(context.Background(), config.GetDriftCheckInterval())
 	if err := router.Run(":8080"); err != nil {
 		panic(err)
 	}
@@ -1,12 +1,55 @@
 package app
 
 import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/controllers/approvals"
+	"github.com/evertonkopec/golang-microservices-main/src/api/controllers/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/controllers/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/controllers/drift"
+	"github.com/evertonkopec/golang-microservices-main/src/api/controllers/jobs"
+	"github.com/evertonkopec/golang-microservices-main/src/api/controllers/manifests"
 	"github.com/evertonkopec/golang-microservices-main/src/api/controllers/polo"
+	"github.com/evertonkopec/golang-microservices-main/src/api/controllers/releases"
 	"github.com/evertonkopec/golang-microservices-main/src/api/controllers/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/idempotency"
 )
 
 func mapUrls(){
+	idempotencyStore := idempotency.NewStore(config.GetIdempotencyKeyTTL())
+
 	router.GET("/marco", polo.Marco)
-	router.POST("/repository", repositories.CreateRepo)
+	router.POST("/repository", idempotency.Middleware(idempotencyStore), repositories.CreateRepo)
 	router.POST("/repositories", repositories.CreateRepos)
-}
\ No newline at end of file
+	router.PATCH("/repositories/:owner/:name", repositories.UpdateRepo)
+	router.GET("/repositories/:owner/:name/topics", repositories.GetTopics)
+	router.PUT("/repositories/:owner/:name/topics", repositories.ReplaceTopics)
+	router.POST("/repositories/:owner/:name/labels", repositories.CreateLabels)
+	router.POST("/repositories/:owner/:name/transfer", repositories.TransferRepo)
+	router.POST("/repositories/:owner/:name/keys", repositories.AddDeployKey)
+	router.PUT("/repositories/:owner/:name/secrets/:secret_name", repositories.SetSecret)
+
+	router.POST("/repositories/:owner/:name/releases", releases.CreateRelease)
+	router.GET("/repositories/:owner/:name/releases", releases.GetReleases)
+	router.GET("/repositories/:owner/:name/releases/:release_id", releases.GetRelease)
+	router.GET("/repositories/:owner/:name/releases/tags/:tag", releases.GetReleaseByTag)
+	router.POST("/repositories/:owner/:name/releases/:release_id/assets", releases.UploadAsset)
+	router.GET("/repositories/:owner/:name/tags", releases.GetTags)
+	router.POST("/repositories/:owner/:name/tags", releases.CreateTag)
+
+	router.GET("/jobs/:job_id", jobs.GetJob)
+	router.DELETE("/jobs/:job_id", jobs.CancelJob)
+
+	router.GET("/audit/repositories", audit.GetRepositoryRecords)
+
+	router.POST("/manifests/plan", manifests.Plan)
+	router.POST("/manifests/apply", manifests.Apply)
+
+	router.GET("/drift/reports", drift.GetReports)
+	router.POST("/drift/check", drift.Check)
+
+	router.GET("/clients/:client_id/usage", clients.GetUsage)
+
+	router.GET("/approvals", approvals.GetApprovals)
+	router.GET("/approvals/:approval_id", approvals.GetApproval)
+	router.POST("/approvals/:approval_id", approvals.Decide)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -23,9 +24,17 @@ type Mock struct {
 	Err        error
 }
 
+// GetResponse returns a copy of the mocked response so that concurrent
+// requests hitting the same mock each get their own body.
 func (m *Mock) GetResponse() *http.Response {
-	m.Response.Body = ioutil.NopCloser(strings.NewReader(m.BodyText))
-	return m.Response
+	if m.Response == nil {
+		return nil
+	}
+	response := *m.Response
+	if m.BodyText != "" || response.Body == nil {
+		response.Body = ioutil.NopCloser(strings.NewReader(m.BodyText))
+	}
+	return &response
 }
 
 func GetMockId(httpMethod string, url string) string {
@@ -48,22 +57,57 @@ func AddMockups(mock Mock) {
 	mocks[GetMockId(mock.HttpMethod, mock.Url)] = &mock
 }
 
+func Get(url string, headers http.Header) (*http.Response, error) {
+	return Do(http.MethodGet, url, nil, headers)
+}
+
 func Post(url string, body interface{}, headers http.Header) (*http.Response, error) {
+	return Do(http.MethodPost, url, body, headers)
+}
+
+func Put(url string, body interface{}, headers http.Header) (*http.Response, error) {
+	return Do(http.MethodPut, url, body, headers)
+}
+
+func Patch(url string, body interface{}, headers http.Header) (*http.Response, error) {
+	return Do(http.MethodPatch, url, body, headers)
+}
+
+func Delete(url string, headers http.Header) (*http.Response, error) {
+	return Do(http.MethodDelete, url, nil, headers)
+}
+
+// Do sends a request with body encoded as JSON, or without a body when nil.
+func Do(method string, url string, body interface{}, headers http.Header) (*http.Response, error) {
+	if body == nil {
+		return DoRaw(method, url, nil, 0, headers)
+	}
+	jsonBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return DoRaw(method, url, bytes.NewReader(jsonBytes), int64(len(jsonBytes)), headers)
+}
+
+// DoRaw sends size bytes read from body as they are, for uploads that must
+// not be encoded and must announce their length.
+func DoRaw(method string, url string, body io.Reader, size int64, headers http.Header) (*http.Response, error) {
 	if enabledMocks {
-		mock := mocks[GetMockId(http.MethodPost, url)]
+		mock := mocks[GetMockId(method, url)]
 		if mock == nil {
 			return nil, errors.New("no mockup found for give request")
 		}
 		return mock.GetResponse(), mock.Err
 	}
 
-	jsonBytes, err := json.Marshal(body)
+	request, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
-
-	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(jsonBytes))
 	request.Header = headers
+	if body != nil {
+		request.ContentLength = size
+	}
 
 	client := http.Client{}
 	return client.Do(request)
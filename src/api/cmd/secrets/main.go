@@ -0,0 +1,84 @@
+// Command secrets maintains the encrypted secret store the service reads its
+// provider tokens and client credentials from.
+//
+//	secrets -new-key
+//	SECRET_VALUE=... secrets -set github_access_token
+//	SECRET_VALUE='{"type":"pat","token":"..."}' secrets -set client_credentials/team-a
+//	secrets -delete github_access_token
+//	SECRETS_NEW_MASTER_KEY=... secrets -rotate
+//
+// The store and master key are read from SECRETS_PATH and SECRETS_MASTER_KEY
+// or SECRETS_MASTER_KEY_FILE, like the service does. Rotating without
+// SECRETS_NEW_MASTER_KEY generates the new key and prints it; the service
+// must be restarted with it afterwards.
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/crypto"
+	"os"
+)
+
+const (
+	envValue        = "SECRET_VALUE"
+	envNewMasterKey = "SECRETS_NEW_MASTER_KEY"
+)
+
+func main() {
+	newKey := flag.Bool("new-key", false, "print a new random master key and exit")
+	set := flag.String("set", "", "name of the secret to store, its value is read from SECRET_VALUE")
+	remove := flag.String("delete", "", "name of the secret to delete")
+	rotate := flag.Bool("rotate", false, "encrypt every secret again under a new master key")
+	flag.Parse()
+
+	if *newKey {
+		key, err := crypto.NewKey()
+		exitOnError(err)
+		fmt.Println(base64.StdEncoding.EncodeToString(key))
+		return
+	}
+
+	store, err := config.OpenSecretStore()
+	exitOnError(err)
+	if store == nil {
+		exitOnError(fmt.Errorf("SECRETS_PATH is required"))
+	}
+
+	switch {
+	case *set != "":
+		value := os.Getenv(envValue)
+		if value == "" {
+			exitOnError(fmt.Errorf("%s is required", envValue))
+		}
+		exitOnError(store.Set(*set, value))
+		fmt.Printf("secret %s saved\n", *set)
+	case *remove != "":
+		exitOnError(store.Delete(*remove))
+		fmt.Printf("secret %s deleted\n", *remove)
+	case *rotate:
+		encoded := os.Getenv(envNewMasterKey)
+		key, err := crypto.DecodeKey(encoded)
+		if encoded == "" {
+			key, err = crypto.NewKey()
+		}
+		exitOnError(err)
+		exitOnError(store.Rotate(key))
+		if encoded == "" {
+			fmt.Println(base64.StdEncoding.EncodeToString(key))
+		}
+		fmt.Fprintln(os.Stderr, "secrets encrypted under the new master key, restart the service with it")
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
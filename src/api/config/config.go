@@ -1,7 +1,12 @@
 package config
 
 import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/secrets"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -9,16 +14,238 @@ const (
 	LogLevel                = "info"
 	goEnvironment           = "GO_ENVIRONMENT"
 	production              = "production"
+	repoNamePolicy          = "REPO_NAME_POLICY"
+	idempotencyKeyTTL       = "IDEMPOTENCY_KEY_TTL"
+	repoBatchConcurrency    = "REPO_BATCH_CONCURRENCY"
+	repoBatchMaxSize        = "REPO_BATCH_MAX_SIZE"
+	auditStorePath          = "AUDIT_STORE_PATH"
+	driftCheckInterval      = "DRIFT_CHECK_INTERVAL"
+	driftRemediateFields    = "DRIFT_REMEDIATE_FIELDS"
+	driftStorePath          = "DRIFT_STORE_PATH"
+	clientRegistryPath      = "CLIENT_REGISTRY_PATH"
+	clientUsagePath         = "CLIENT_USAGE_PATH"
+	clientReposPerDay       = "CLIENT_REPOS_PER_DAY"
+	clientMaxConcurrentJobs = "CLIENT_MAX_CONCURRENT_JOBS"
+	jobRetention            = "JOB_RETENTION"
+	clientCredentials       = "CLIENT_CREDENTIALS"
+	secretsPath             = "SECRETS_PATH"
+	policyRulesPath         = "POLICY_RULES_PATH"
+	repoPresetsPath         = "REPO_PRESETS_PATH"
+	scaffoldTemplatesDir    = "SCAFFOLD_TEMPLATES_DIR"
+	repoStandardLabelsPath  = "REPO_STANDARD_LABELS_PATH"
+	releaseAssetMaxSize     = "RELEASE_ASSET_MAX_SIZE"
+	secretsMasterKey        = "SECRETS_MASTER_KEY"
+	secretsMasterKeyFile    = "SECRETS_MASTER_KEY_FILE"
+
+	// SecretGithubAccessToken is the name the shared GitHub token is stored
+	// under in the secret store.
+	SecretGithubAccessToken = "github_access_token"
+	// SecretClientCredentialPrefix is followed by the client id in the name
+	// the GitHub credential of each client is stored under.
+	SecretClientCredentialPrefix = "client_credentials/"
+
+	defaultIdempotencyKeyTTL    = 24 * time.Hour
+	defaultRepoBatchConcurrency = 10
+	defaultRepoBatchMaxSize     = 100
+	defaultDriftCheckInterval   = time.Hour
+	defaultClientReposPerDay    = 100
+	defaultClientConcurrentJobs = 5
+	defaultJobRetention         = 24 * time.Hour
+	defaultReleaseAssetMaxSize  = 100 << 20
 )
 
 var (
-	githubAccessToken = os.Getenv(secretGithubAccessToken)
+	secretStoreOnce  sync.Once
+	secretStore      secrets.Store
+	secretStoreError error
 )
 
-func GetGithubAccessToken() string {
-	return githubAccessToken
+// GetGithubAccessToken returns the GitHub token shared by every client. It is
+// read from the secret store when one is configured and from
+// SECRET_GITHUB_ACCESS_TOKEN otherwise, or when the store does not hold it.
+// Errors opening the store or reading the token are returned so requests fail
+// instead of going out unauthenticated.
+func GetGithubAccessToken() (string, error) {
+	store, err := GetSecretStore()
+	if err != nil {
+		return "", err
+	}
+	if store != nil {
+		token, err := store.Get(SecretGithubAccessToken)
+		if err == nil {
+			return token, nil
+		}
+		if err != secrets.ErrNotFound {
+			return "", err
+		}
+	}
+	return os.Getenv(secretGithubAccessToken), nil
+}
+
+// GetSecretStore returns the encrypted store at SECRETS_PATH, opened with the
+// master key in SECRETS_MASTER_KEY or SECRETS_MASTER_KEY_FILE. It is opened
+// once; nil without an error means no store is configured.
+func GetSecretStore() (secrets.Store, error) {
+	secretStoreOnce.Do(func() {
+		secretStore, secretStoreError = OpenSecretStore()
+	})
+	return secretStore, secretStoreError
+}
+
+// OpenSecretStore opens the configured secret store again, for tools that
+// change it while the service keeps its own copy.
+func OpenSecretStore() (secrets.Store, error) {
+	path := os.Getenv(secretsPath)
+	if path == "" {
+		return nil, nil
+	}
+	key, err := secrets.LoadMasterKey(os.Getenv(secretsMasterKey), os.Getenv(secretsMasterKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	return secrets.NewFileStore(path, key)
 }
 
 func IsProduction() bool {
 	return os.Getenv(goEnvironment) == production
 }
+
+// GetRepoNamePolicy returns the regular expression every repository name
+// must match on top of GitHub's own rules. Empty means no extra policy.
+func GetRepoNamePolicy() string {
+	return os.Getenv(repoNamePolicy)
+}
+
+// GetIdempotencyKeyTTL returns how long the outcome of a request sent with an
+// Idempotency-Key header is kept for replays.
+func GetIdempotencyKeyTTL() time.Duration {
+	return getDuration(idempotencyKeyTTL, defaultIdempotencyKeyTTL)
+}
+
+// GetRepoBatchConcurrency returns how many repositories of a batch are
+// created against GitHub at the same time.
+func GetRepoBatchConcurrency() int {
+	return getInt(repoBatchConcurrency, defaultRepoBatchConcurrency)
+}
+
+// GetRepoBatchMaxSize returns the maximum number of repositories accepted in
+// a single batch.
+func GetRepoBatchMaxSize() int {
+	return getInt(repoBatchMaxSize, defaultRepoBatchMaxSize)
+}
+
+// GetAuditStorePath returns the file audit records are persisted to. Empty
+// means records are only kept in memory.
+func GetAuditStorePath() string {
+	return os.Getenv(auditStorePath)
+}
+
+// GetDriftCheckInterval returns how often managed repositories are compared
+// with their expected configuration.
+func GetDriftCheckInterval() time.Duration {
+	return getDuration(driftCheckInterval, defaultDriftCheckInterval)
+}
+
+// GetDriftRemediateFields returns the fields whose drift is reverted
+// automatically, such as "description" or "collaborators". Empty means drift
+// is only reported.
+func GetDriftRemediateFields() []string {
+	result := make([]string, 0)
+	for _, field := range strings.Split(os.Getenv(driftRemediateFields), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// GetDriftStorePath returns the file managed repositories and the
+// configuration they are expected to keep are persisted to. Empty means they
+// are rebuilt from the audit records on restart.
+func GetDriftStorePath() string {
+	return os.Getenv(driftStorePath)
+}
+
+// GetClientRegistryPath returns the file listing the known clients and their
+// quotas. Empty means every client gets the default quotas.
+func GetClientRegistryPath() string {
+	return os.Getenv(clientRegistryPath)
+}
+
+// GetClientUsagePath returns the file usage counters are persisted to. Empty
+// means counters are lost on restart.
+func GetClientUsagePath() string {
+	return os.Getenv(clientUsagePath)
+}
+
+// GetClientReposPerDay returns how many repositories a client without its own
+// quota can create per UTC day.
+func GetClientReposPerDay() int {
+	return getInt(clientReposPerDay, defaultClientReposPerDay)
+}
+
+// GetJobRetention returns how long a finished job can still be polled before
+// it is forgotten.
+func GetJobRetention() time.Duration {
+	return getDuration(jobRetention, defaultJobRetention)
+}
+
+// GetClientMaxConcurrentJobs returns how many asynchronous jobs a client
+// without its own quota can have running at once.
+func GetClientMaxConcurrentJobs() int {
+	return getInt(clientMaxConcurrentJobs, defaultClientConcurrentJobs)
+}
+
+// GetClientCredentials reports whether every client authenticates against
+// GitHub with its own credential, kept in the secret store. When false every
+// client shares the token returned by GetGithubAccessToken.
+func GetClientCredentials() bool {
+	value, err := strconv.ParseBool(os.Getenv(clientCredentials))
+	return err == nil && value
+}
+
+// GetPolicyRulesPath returns the file with the rules repository requests are
+// checked against before hitting GitHub. Empty means every request is allowed.
+func GetPolicyRulesPath() string {
+	return os.Getenv(policyRulesPath)
+}
+
+// GetRepoPresetsPath returns the file with the named presets repository
+// requests can refer to. Empty means there are no presets.
+func GetRepoPresetsPath() string {
+	return os.Getenv(repoPresetsPath)
+}
+
+// GetScaffoldTemplatesDir returns the directory with the templates of the
+// files new repositories can be scaffolded with. Empty means there are none.
+func GetScaffoldTemplatesDir() string {
+	return os.Getenv(scaffoldTemplatesDir)
+}
+
+// GetRepoStandardLabelsPath returns the file with the labels created when a
+// request does not list its own. Empty means a set of common labels.
+func GetRepoStandardLabelsPath() string {
+	return os.Getenv(repoStandardLabelsPath)
+}
+
+// GetReleaseAssetMaxSize returns the largest release asset, in bytes, that
+// can be uploaded through the service.
+func GetReleaseAssetMaxSize() int64 {
+	return int64(getInt(releaseAssetMaxSize, defaultReleaseAssetMaxSize))
+}
+
+func getInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -1,8 +1,15 @@
 package config
 
 import (
+	"encoding/base64"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/crypto"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/secrets"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestConstants(t *testing.T){
@@ -10,5 +17,109 @@ func TestConstants(t *testing.T){
 }
 
 func TestGetGithubAccessToken(t *testing.T)  {
-	assert.EqualValues(t, "", GetGithubAccessToken())
-}
\ No newline at end of file
+	token, err := GetGithubAccessToken()
+	assert.Nil(t, err)
+	assert.EqualValues(t, "", token)
+
+	os.Setenv(secretGithubAccessToken, "from-env")
+	defer os.Unsetenv(secretGithubAccessToken)
+	token, err = GetGithubAccessToken()
+	assert.Nil(t, err)
+	assert.EqualValues(t, "from-env", token)
+}
+
+func TestGetGithubAccessTokenFromSecretStore(t *testing.T) {
+	key, _ := crypto.NewKey()
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	store, _ := secrets.NewFileStore(path, key)
+	assert.Nil(t, store.Set(SecretGithubAccessToken, "from-store"))
+
+	os.Setenv(secretsPath, path)
+	os.Setenv(secretsMasterKey, base64.StdEncoding.EncodeToString(key))
+	os.Setenv(secretGithubAccessToken, "from-env")
+	defer func() {
+		os.Unsetenv(secretsPath)
+		os.Unsetenv(secretsMasterKey)
+		os.Unsetenv(secretGithubAccessToken)
+		secretStoreOnce = sync.Once{}
+	}()
+
+	secretStoreOnce = sync.Once{}
+	token, err := GetGithubAccessToken()
+	assert.Nil(t, err)
+	assert.EqualValues(t, "from-store", token)
+
+	os.Setenv(secretsMasterKey, "invalid")
+	secretStoreOnce = sync.Once{}
+	_, err = GetSecretStore()
+	assert.NotNil(t, err)
+	token, err = GetGithubAccessToken()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "", token)
+}
+func TestGetRepoNamePolicy(t *testing.T) {
+	assert.EqualValues(t, "REPO_NAME_POLICY", repoNamePolicy)
+	assert.EqualValues(t, "", GetRepoNamePolicy())
+
+	os.Setenv(repoNamePolicy, "^team-")
+	defer os.Unsetenv(repoNamePolicy)
+	assert.EqualValues(t, "^team-", GetRepoNamePolicy())
+}
+
+func TestGetIdempotencyKeyTTL(t *testing.T) {
+	assert.EqualValues(t, 24*time.Hour, GetIdempotencyKeyTTL())
+
+	os.Setenv(idempotencyKeyTTL, "90m")
+	defer os.Unsetenv(idempotencyKeyTTL)
+	assert.EqualValues(t, 90*time.Minute, GetIdempotencyKeyTTL())
+
+	os.Setenv(idempotencyKeyTTL, "forever")
+	assert.EqualValues(t, 24*time.Hour, GetIdempotencyKeyTTL())
+}
+
+func TestGetRepoBatchSettings(t *testing.T) {
+	assert.EqualValues(t, 10, GetRepoBatchConcurrency())
+	assert.EqualValues(t, 100, GetRepoBatchMaxSize())
+
+	os.Setenv(repoBatchConcurrency, "3")
+	os.Setenv(repoBatchMaxSize, "-1")
+	defer os.Unsetenv(repoBatchConcurrency)
+	defer os.Unsetenv(repoBatchMaxSize)
+	assert.EqualValues(t, 3, GetRepoBatchConcurrency())
+	assert.EqualValues(t, 100, GetRepoBatchMaxSize())
+}
+
+func TestGetDriftSettings(t *testing.T) {
+	assert.EqualValues(t, time.Hour, GetDriftCheckInterval())
+	assert.EqualValues(t, []string{}, GetDriftRemediateFields())
+
+	os.Setenv(driftCheckInterval, "15m")
+	os.Setenv(driftRemediateFields, " description, ,collaborators ")
+	defer os.Unsetenv(driftCheckInterval)
+	defer os.Unsetenv(driftRemediateFields)
+	assert.EqualValues(t, 15*time.Minute, GetDriftCheckInterval())
+	assert.EqualValues(t, []string{"description", "collaborators"}, GetDriftRemediateFields())
+}
+
+func TestGetClientSettings(t *testing.T) {
+	assert.EqualValues(t, "", GetClientRegistryPath())
+	assert.EqualValues(t, "", GetClientUsagePath())
+	assert.EqualValues(t, 100, GetClientReposPerDay())
+	assert.EqualValues(t, 5, GetClientMaxConcurrentJobs())
+
+	os.Setenv(clientReposPerDay, "20")
+	os.Setenv(clientMaxConcurrentJobs, "1")
+	defer os.Unsetenv(clientReposPerDay)
+	defer os.Unsetenv(clientMaxConcurrentJobs)
+	assert.EqualValues(t, 20, GetClientReposPerDay())
+	assert.EqualValues(t, 1, GetClientMaxConcurrentJobs())
+}
+
+func TestGetClientCredentials(t *testing.T) {
+	assert.EqualValues(t, "CLIENT_CREDENTIALS", clientCredentials)
+	assert.False(t, GetClientCredentials())
+
+	os.Setenv(clientCredentials, "true")
+	defer os.Unsetenv(clientCredentials)
+	assert.True(t, GetClientCredentials())
+}
@@ -0,0 +1,47 @@
+package approvals
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/approvals"
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetApprovals(c *gin.Context) {
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.ApprovalsService.Find(clientId, c.Query("status"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func GetApproval(c *gin.Context) {
+	clientId := c.GetHeader("X-Client-Id")
+
+	approval, err := services.ApprovalsService.Get(clientId, c.Param("approval_id"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
+
+func Decide(c *gin.Context) {
+	var request approvals.DecisionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		errors.RespondError(c, errors.NewBadRequestError("invalid json body"))
+		return
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	approval, err := services.ApprovalsService.Decide(clientId, c.Param("approval_id"), request)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
@@ -0,0 +1,78 @@
+package approvals
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/approvals"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetApprovalsInvalidStatus(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "/approvals?status=unknown", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	GetApprovals(c)
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "invalid status unknown", apiErr.Message())
+}
+
+func TestGetApprovalsOwn(t *testing.T) {
+	approvals.ApprovalsDao.Save(approvals.Approval{ClientId: "controller-client", Request: repositories.CreateRepoRequest{Name: "prod-api"}})
+
+	request, _ := http.NewRequest(http.MethodGet, "/approvals?status=pending", nil)
+	request.Header.Set("X-Client-Id", "controller-client")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	GetApprovals(c)
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result []approvals.Approval
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(result))
+	assert.EqualValues(t, "prod-api", result[0].Request.Name)
+}
+
+func TestGetApprovalNotFound(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "/approvals/missing", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	c.Params = gin.Params{{Key: "approval_id", Value: "missing"}}
+
+	GetApproval(c)
+	assert.EqualValues(t, http.StatusNotFound, response.Code)
+}
+
+func TestDecideInvalidBody(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/approvals/abc", strings.NewReader(``))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	c.Params = gin.Params{{Key: "approval_id", Value: "abc"}}
+
+	Decide(c)
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
+
+func TestDecideNotAdmin(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/approvals/abc", strings.NewReader(`{"decision":"approve"}`))
+	request.Header.Set("X-Client-Id", "team-a")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	c.Params = gin.Params{{Key: "approval_id", Value: "abc"}}
+
+	Decide(c)
+	assert.EqualValues(t, http.StatusForbidden, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "only admins can decide on approvals", apiErr.Message())
+}
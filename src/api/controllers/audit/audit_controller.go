@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+)
+
+// GetRepositoryRecords lists the audited repository operations of the caller,
+// optionally filtered by status and a from/to date range. Admins see every
+// client and can filter by client_id.
+func GetRepositoryRecords(c *gin.Context) {
+	filter := audit.RecordFilter{
+		ClientId: c.Query("client_id"),
+		Status:   c.Query("status"),
+	}
+
+	var err errors.ApiError
+	if filter.From, err = parseTime("from", c.Query("from"), false); err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	if filter.To, err = parseTime("to", c.Query("to"), true); err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+
+	records, err := services.AuditService.FindRepositoryRecords(c.GetHeader("X-Client-Id"), filter)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// parseTime accepts RFC 3339 timestamps and plain dates. A plain date used as
+// the upper bound covers that whole day.
+func parseTime(name string, value string, upper bool) (time.Time, errors.ApiError) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if result, err := time.Parse(time.RFC3339, value); err == nil {
+		return result, nil
+	}
+	result, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, errors.NewBadRequestError(fmt.Sprintf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", name))
+	}
+	if upper {
+		result = result.AddDate(0, 0, 1)
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetRepositoryRecordsInvalidDate(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "/audit/repositories?from=yesterday", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	GetRepositoryRecords(c)
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "from must be an RFC 3339 timestamp or a YYYY-MM-DD date", apiErr.Message())
+}
+
+func TestGetRepositoryRecords(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+	audit.Store.Save(audit.RepositoryRecord{ClientId: "client", Status: audit.StatusFailed, CreatedAt: time.Date(2021, 10, 1, 23, 0, 0, 0, time.UTC)})
+	audit.Store.Save(audit.RepositoryRecord{ClientId: "client", Status: audit.StatusFailed, CreatedAt: time.Date(2021, 10, 2, 0, 0, 0, 0, time.UTC)})
+	audit.Store.Save(audit.RepositoryRecord{ClientId: "client", Status: audit.StatusSucceeded, CreatedAt: time.Date(2021, 10, 1, 8, 0, 0, 0, time.UTC)})
+
+	audit.Store.Save(audit.RepositoryRecord{ClientId: "other", Status: audit.StatusFailed, CreatedAt: time.Date(2021, 10, 1, 22, 0, 0, 0, time.UTC)})
+
+	request, _ := http.NewRequest(http.MethodGet, "/audit/repositories?status=failed&from=2021-10-01&to=2021-10-01", nil)
+	request.Header.Set("X-Client-Id", "client")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	GetRepositoryRecords(c)
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var records []audit.RepositoryRecord
+	err := json.Unmarshal(response.Body.Bytes(), &records)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(records))
+	assert.EqualValues(t, 23, records[0].CreatedAt.Hour())
+}
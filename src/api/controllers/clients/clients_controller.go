@@ -0,0 +1,19 @@
+package clients
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetUsage(c *gin.Context) {
+	clientId := c.GetHeader("X-Client-Id")
+
+	usage, err := services.ClientsService.GetUsage(clientId, c.Param("client_id"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
@@ -0,0 +1,44 @@
+package clients
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetUsageOfOtherClient(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "/clients/team-a/usage", nil)
+	request.Header.Set("X-Client-Id", "team-b")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	c.Params = gin.Params{{Key: "client_id", Value: "team-a"}}
+
+	GetUsage(c)
+	assert.EqualValues(t, http.StatusForbidden, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "clients can only read their own usage", apiErr.Message())
+}
+
+func TestGetUsage(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "/clients/team-a/usage", nil)
+	request.Header.Set("X-Client-Id", "team-a")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	c.Params = gin.Params{{Key: "client_id", Value: "team-a"}}
+
+	GetUsage(c)
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var usage clients.UsageReport
+	err := json.Unmarshal(response.Body.Bytes(), &usage)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "team-a", usage.ClientId)
+	assert.EqualValues(t, clients.DefaultQuotas(), usage.Quotas)
+	assert.EqualValues(t, usage.Quotas.ReposPerDay, usage.ReposRemaining)
+}
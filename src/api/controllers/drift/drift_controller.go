@@ -0,0 +1,31 @@
+package drift
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetReports lists the latest drift report of the managed repositories of the
+// caller, optionally only the drifted ones. Admins see every client and can
+// filter by client_id.
+func GetReports(c *gin.Context) {
+	reports, err := services.DriftService.GetReports(c.GetHeader("X-Client-Id"), c.Query("client_id"), c.Query("drifted") == "true")
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// Check runs a drift check right away instead of waiting for the next one.
+// Only admins can run it.
+func Check(c *gin.Context) {
+	reports, err := services.DriftService.RunCheck(c.GetHeader("X-Client-Id"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
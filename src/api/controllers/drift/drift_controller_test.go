@@ -0,0 +1,52 @@
+package drift
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/drift"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetReports(t *testing.T) {
+	drift.DriftDao.Manage(drift.ManagedRepository{ClientId: "client", Owner: "EBKopec", Spec: manifests.RepositorySpec{Name: "drifted"}})
+	drift.DriftDao.Manage(drift.ManagedRepository{ClientId: "client", Owner: "EBKopec", Spec: manifests.RepositorySpec{Name: "clean"}})
+	defer drift.DriftDao.Unmanage("EBKopec", "drifted")
+	defer drift.DriftDao.Unmanage("EBKopec", "clean")
+	drift.DriftDao.SaveReport(drift.Report{ClientId: "client", Owner: "EBKopec", Name: "drifted", Drifted: true})
+	drift.DriftDao.SaveReport(drift.Report{ClientId: "client", Owner: "EBKopec", Name: "clean"})
+
+	drift.DriftDao.Manage(drift.ManagedRepository{ClientId: "other", Owner: "EBKopec", Spec: manifests.RepositorySpec{Name: "foreign"}})
+	defer drift.DriftDao.Unmanage("EBKopec", "foreign")
+	drift.DriftDao.SaveReport(drift.Report{ClientId: "other", Owner: "EBKopec", Name: "foreign", Drifted: true})
+
+	request, _ := http.NewRequest(http.MethodGet, "/drift/reports?drifted=true", nil)
+	request.Header.Set("X-Client-Id", "client")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	GetReports(c)
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var reports []drift.Report
+	err := json.Unmarshal(response.Body.Bytes(), &reports)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(reports))
+	assert.EqualValues(t, "drifted", reports[0].Name)
+}
+
+func TestCheckRequiresAdmin(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/drift/check", nil)
+	request.Header.Set("X-Client-Id", "client")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	Check(c)
+	assert.EqualValues(t, http.StatusForbidden, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "only admins can run drift checks", apiErr.Message())
+}
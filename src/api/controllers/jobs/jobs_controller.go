@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetJob(c *gin.Context) {
+	clientId := c.GetHeader("X-Client-Id")
+
+	job, err := services.JobsService.GetJob(clientId, c.Param("job_id"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+func CancelJob(c *gin.Context) {
+	clientId := c.GetHeader("X-Client-Id")
+
+	job, err := services.JobsService.CancelJob(clientId, c.Param("job_id"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
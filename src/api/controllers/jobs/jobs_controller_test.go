@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/jobs"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetJobNotFound(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "/jobs/missing", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	c.Params = gin.Params{{Key: "job_id", Value: "missing"}}
+
+	GetJob(c)
+	assert.EqualValues(t, http.StatusNotFound, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "job missing not found", apiErr.Message())
+}
+
+func TestGetJobNoError(t *testing.T) {
+	jobs.JobDao.Save(jobs.NewJob("controller-job", "client", []repositories.CreateRepoRequest{{Name: "testing"}}))
+
+	request, _ := http.NewRequest(http.MethodGet, "/jobs/controller-job", nil)
+	request.Header.Set("X-Client-Id", "client")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	c.Params = gin.Params{{Key: "job_id", Value: "controller-job"}}
+
+	GetJob(c)
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result jobs.Job
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "controller-job", result.Id)
+	assert.EqualValues(t, jobs.StatePending, result.State)
+	assert.EqualValues(t, 1, result.Counts.Pending)
+}
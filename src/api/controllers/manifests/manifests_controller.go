@@ -0,0 +1,71 @@
+package manifests
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+func Plan(c *gin.Context) {
+	manifest, err := readManifest(c)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+
+	result, err := services.ManifestsService.Plan(c.GetHeader("X-Client-Id"), *manifest)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func Apply(c *gin.Context) {
+	manifest, err := readManifest(c)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+
+	result, err := services.ManifestsService.Apply(c.GetHeader("X-Client-Id"), *manifest)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(result.StatusCode, result)
+}
+
+// readManifest decodes the body as YAML when the content type says so and as
+// JSON otherwise.
+func readManifest(c *gin.Context) (*manifests.Manifest, errors.ApiError) {
+	var result manifests.Manifest
+	if !isYaml(c.ContentType()) {
+		if err := c.ShouldBindJSON(&result); err != nil {
+			return nil, errors.NewBadRequestError("invalid json body")
+		}
+		return &result, nil
+	}
+
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, errors.NewBadRequestError("invalid request body")
+	}
+	if err := yaml.UnmarshalStrict(body, &result); err != nil {
+		return nil, errors.NewBadRequestError("invalid yaml body")
+	}
+	return &result, nil
+}
+
+func isYaml(contentType string) bool {
+	switch strings.ToLower(contentType) {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package manifests
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	restclient.StartMockups()
+	os.Exit(m.Run())
+}
+
+func TestPlanInvalidYamlBody(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/manifests/plan", strings.NewReader("repositories: [name: testing"))
+	request.Header.Set("Content-Type", "application/yaml")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	Plan(c)
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "invalid yaml body", apiErr.Message())
+}
+
+func TestPlanInvalidJsonBody(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/manifests/plan", strings.NewReader(`{"repositories": 1}`))
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	Plan(c)
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "invalid json body", apiErr.Message())
+}
+
+func TestPlanYaml(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1,"login": "EBKopec"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+
+	request, _ := http.NewRequest(http.MethodPost, "/manifests/plan", strings.NewReader("repositories:\n  - name: testing\n    private: true\n"))
+	request.Header.Set("Content-Type", "application/x-yaml")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	Plan(c)
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result manifests.Plan
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.Create)
+	assert.EqualValues(t, "private", result.Repositories[0].Changes[0].Field)
+}
@@ -0,0 +1,127 @@
+package releases
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+func CreateRelease(c *gin.Context) {
+	var request repositories.CreateReleaseRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		errors.RespondError(c, errors.NewBadRequestError("invalid json body"))
+		return
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.ReleasesService.CreateRelease(clientId, c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func GetReleases(c *gin.Context) {
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.ReleasesService.GetReleases(clientId, c.Param("owner"), c.Param("name"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func GetRelease(c *gin.Context) {
+	releaseId, apiErr := getReleaseId(c)
+	if apiErr != nil {
+		errors.RespondError(c, apiErr)
+		return
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.ReleasesService.GetRelease(clientId, c.Param("owner"), c.Param("name"), releaseId)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func GetReleaseByTag(c *gin.Context) {
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.ReleasesService.GetReleaseByTag(clientId, c.Param("owner"), c.Param("name"), c.Param("tag"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// UploadAsset streams the request body to GitHub as an asset named by the
+// name query parameter, which is why the body must announce its length.
+func UploadAsset(c *gin.Context) {
+	releaseId, apiErr := getReleaseId(c)
+	if apiErr != nil {
+		errors.RespondError(c, apiErr)
+		return
+	}
+	if c.Request.ContentLength < 0 {
+		errors.RespondError(c, errors.NewApiError(http.StatusLengthRequired, "the asset must be sent with a Content-Length"))
+		return
+	}
+	request := repositories.UploadAssetRequest{
+		Name:        c.Query("name"),
+		Label:       c.Query("label"),
+		ContentType: c.ContentType(),
+		Size:        c.Request.ContentLength,
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.ReleasesService.UploadAsset(clientId, c.Param("owner"), c.Param("name"), releaseId, request, c.Request.Body)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func GetTags(c *gin.Context) {
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.ReleasesService.GetTags(clientId, c.Param("owner"), c.Param("name"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func CreateTag(c *gin.Context) {
+	var request repositories.CreateTagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		errors.RespondError(c, errors.NewBadRequestError("invalid json body"))
+		return
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.ReleasesService.CreateTag(clientId, c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func getReleaseId(c *gin.Context) (int64, errors.ApiError) {
+	releaseId, err := strconv.ParseInt(c.Param("release_id"), 10, 64)
+	if err != nil || releaseId <= 0 {
+		return 0, errors.NewBadRequestError("release id must be a positive number")
+	}
+	return releaseId, nil
+}
@@ -0,0 +1,71 @@
+package releases
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	restclient.StartMockups()
+	os.Exit(m.Run())
+}
+
+func releaseContext(request *http.Request, response *httptest.ResponseRecorder, releaseId string) *gin.Context {
+	c := test_utils.GetMockedContext(request, response)
+	c.Params = gin.Params{{Key: "owner", Value: "EBKopec"}, {Key: "name", Value: "api"}, {Key: "release_id", Value: releaseId}}
+	return c
+}
+
+func TestGetReleaseInvalidId(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodGet, "/repositories/EBKopec/api/releases/latest", nil)
+	response := httptest.NewRecorder()
+
+	GetRelease(releaseContext(request, response, "latest"))
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "release id must be a positive number", apiErr.Message())
+}
+
+func TestUploadAssetLengthRequired(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/repositories/EBKopec/api/releases/1/assets?name=app.tar.gz", strings.NewReader("data"))
+	request.ContentLength = -1
+	response := httptest.NewRecorder()
+
+	UploadAsset(releaseContext(request, response, "1"))
+
+	assert.EqualValues(t, http.StatusLengthRequired, response.Code)
+}
+
+func TestUploadAssetNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://uploads.github.com/repos/EBKopec/api/releases/1/assets?name=app.tar.gz",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 9, "name": "app.tar.gz", "content_type": "application/gzip", "size": 4}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+
+	request, _ := http.NewRequest(http.MethodPost, "/repositories/EBKopec/api/releases/1/assets?name=app.tar.gz", strings.NewReader("data"))
+	request.Header.Set("Content-Type", "application/gzip")
+	response := httptest.NewRecorder()
+
+	UploadAsset(releaseContext(request, response, "1"))
+
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+	var result repositories.ReleaseAsset
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &result))
+	assert.EqualValues(t, 9, result.Id)
+	assert.EqualValues(t, "application/gzip", result.ContentType)
+}
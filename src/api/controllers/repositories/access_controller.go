@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func AddDeployKey(c *gin.Context) {
+	var request repositories.DeployKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		errors.RespondError(c, errors.NewBadRequestError("invalid json body"))
+		return
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.AccessService.AddDeployKey(clientId, c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func SetSecret(c *gin.Context) {
+	var request repositories.SetSecretRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		errors.RespondError(c, errors.NewBadRequestError("invalid json body"))
+		return
+	}
+	clientId := c.GetHeader("X-Client-Id")
+	secret := repositories.ActionsSecret{Name: c.Param("secret_name"), Value: request.Value}
+
+	result, err := services.AccessService.SetSecret(clientId, c.Param("owner"), c.Param("name"), secret)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetSecretReservedName(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPut, "/repositories/EBKopec/api/secrets/GITHUB_TOKEN", strings.NewReader(`{"value": "s3cret"}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	repoParams(c)
+	c.Params = append(c.Params, gin.Param{Key: "secret_name", Value: "GITHUB_TOKEN"})
+
+	SetSecret(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "invalid secret", apiErr.Message())
+	assert.NotContains(t, response.Body.String(), "s3cret")
+}
+
+func TestAddDeployKeyInvalidJson(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "/repositories/EBKopec/api/keys", strings.NewReader(`{`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	repoParams(c)
+
+	AddDeployKey(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+}
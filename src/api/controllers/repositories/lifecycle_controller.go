@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func UpdateRepo(c *gin.Context) {
+	var request repositories.UpdateRepoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		errors.RespondError(c, errors.NewBadRequestError("invalid json body"))
+		return
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.LifecycleService.UpdateRepo(clientId, c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func TransferRepo(c *gin.Context) {
+	var request repositories.TransferRepoRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		errors.RespondError(c, errors.NewBadRequestError("invalid json body"))
+		return
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.LifecycleService.TransferRepo(clientId, c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, result)
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUpdateRepoNothingToUpdate(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPatch, "/repositories/EBKopec/api", strings.NewReader(`{}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	repoParams(c)
+
+	UpdateRepo(c)
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	apiErr, err := errors.NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, "nothing to update", apiErr.Message())
+}
+
+func TestTransferRepoAccepted(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/transfer",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 1, "name": "api", "owner": {"login": "EBKopec"}}`,
+		Response:   &http.Response{StatusCode: http.StatusAccepted},
+	})
+
+	request, _ := http.NewRequest(http.MethodPost, "/repositories/EBKopec/api/transfer", strings.NewReader(`{"new_owner": "golang", "new_name": "service"}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	repoParams(c)
+
+	TransferRepo(c)
+
+	assert.EqualValues(t, http.StatusAccepted, response.Code)
+	var result repositories.TransferRepoResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &result))
+	assert.EqualValues(t, "golang", result.NewOwner)
+	assert.EqualValues(t, "service", result.NewName)
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/services"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+func GetTopics(c *gin.Context) {
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.MetadataService.GetTopics(clientId, c.Param("owner"), c.Param("name"))
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func ReplaceTopics(c *gin.Context) {
+	var request repositories.Topics
+	if err := c.ShouldBindJSON(&request); err != nil {
+		errors.RespondError(c, errors.NewBadRequestError("invalid json body"))
+		return
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.MetadataService.ReplaceTopics(clientId, c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func CreateLabels(c *gin.Context) {
+	var request repositories.CreateLabelsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			errors.RespondError(c, errors.NewBadRequestError("invalid json body"))
+			return
+		}
+	}
+	clientId := c.GetHeader("X-Client-Id")
+
+	result, err := services.MetadataService.CreateLabels(clientId, c.Param("owner"), c.Param("name"), request)
+	if err != nil {
+		errors.RespondError(c, err)
+		return
+	}
+	c.JSON(result.StatusCode, result)
+}
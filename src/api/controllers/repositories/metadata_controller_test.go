@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func repoParams(c *gin.Context) {
+	c.Params = gin.Params{{Key: "owner", Value: "EBKopec"}, {Key: "name", Value: "api"}}
+}
+
+func TestReplaceTopicsNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/topics",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"names": ["go"]}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+
+	request, _ := http.NewRequest(http.MethodPut, "/repositories/EBKopec/api/topics", strings.NewReader(`{"names": ["Go"]}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	repoParams(c)
+
+	ReplaceTopics(c)
+
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result repositories.Topics
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &result))
+	assert.EqualValues(t, []string{"go"}, result.Names)
+}
+
+func TestCreateLabelsWithoutBody(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/labels",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 1}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+
+	request, _ := http.NewRequest(http.MethodPost, "/repositories/EBKopec/api/labels", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+	repoParams(c)
+
+	CreateLabels(c)
+
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+	var result repositories.CreateLabelsResponse
+	assert.Nil(t, json.Unmarshal(response.Body.Bytes(), &result))
+	assert.EqualValues(t, len(repositories.StandardLabels), len(result.Results))
+}
@@ -1,26 +1,54 @@
 package repositories
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
 	"github.com/evertonkopec/golang-microservices-main/src/api/services"
 	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/idempotency"
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	contentTypeNdjson = "application/x-ndjson"
 )
 
 func CreateRepo(c *gin.Context){
 	var request repositories.CreateRepoRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		apiErr := errors.NewBadRequestError("invalid json body")
-		c.JSON(apiErr.Status(), apiErr)
+		errors.RespondError(c, apiErr)
 		return
 	}
 
 	clientId := c.GetHeader("X-Client-Id")
 
+	if isDryRun(c) {
+		result, err := services.RepositoryService.DryRunRepos(clientId, []repositories.CreateRepoRequest{request})
+		if err != nil {
+			errors.RespondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result.Results[0])
+		return
+	}
+
 	result, err := services.RepositoryService.CreateRepo(clientId, request)
 	if err != nil {
-		c.JSON(err.Status(), err)
+		errors.RespondError(c, err)
+		return
+	}
+	// retries with the same idempotency key must not get the private keys
+	// of generated deploy keys again
+	idempotency.SetStoredBody(c, result.WithoutPrivateKeys())
+	if result.ApprovalId != "" {
+		c.Header("Location", fmt.Sprintf("/approvals/%s", result.ApprovalId))
+		c.JSON(http.StatusAccepted, result)
 		return
 	}
 	c.JSON(http.StatusCreated, result)
@@ -30,14 +58,96 @@ func CreateRepos(c *gin.Context){
 	var request []repositories.CreateRepoRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
 		apiErr := errors.NewBadRequestError("invalid json body")
-		c.JSON(apiErr.Status(), apiErr)
+		errors.RespondError(c, apiErr)
 		return
 	}
 
-	result, err := services.RepositoryService.CreateRepos(request)
+	clientId := c.GetHeader("X-Client-Id")
+	options := repositories.CreateReposOptions{
+		Atomic:        c.Query("atomic") == "true",
+		Duplicates:    c.Query("duplicates"),
+		CheckExisting: c.Query("check_existing") == "true",
+	}
+
+	if isDryRun(c) {
+		result, err := services.RepositoryService.DryRunRepos(clientId, request)
+		if err != nil {
+			errors.RespondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	if options.Atomic && (c.Query("async") == "true" || streamFormat(c) != "") {
+		errors.RespondError(c, errors.NewBadRequestError("atomic batches can not be processed asynchronously or streamed"))
+		return
+	}
+
+	if c.Query("async") == "true" {
+		job, err := services.JobsService.CreateJob(clientId, request, options)
+		if err != nil {
+			errors.RespondError(c, err)
+			return
+		}
+		c.Header("Location", fmt.Sprintf("/jobs/%s", job.Id))
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	if format := streamFormat(c); format != "" {
+		events, err := services.RepositoryService.StreamRepos(c.Request.Context(), clientId, request, options)
+		if err != nil {
+			errors.RespondError(c, err)
+			return
+		}
+		streamRepoEvents(c, format, events)
+		return
+	}
+
+	result, err := services.RepositoryService.CreateRepos(c.Request.Context(), clientId, request, options)
 	if err != nil {
-		c.JSON(err.Status(), err)
+		errors.RespondError(c, err)
 		return
 	}
 	c.JSON(result.StatusCode, result)
-}
\ No newline at end of file
+}
+
+func isDryRun(c *gin.Context) bool {
+	return c.Query("dry_run") == "true"
+}
+
+// streamFormat returns the streaming content type asked for in the Accept
+// header, or an empty string when the batch must be answered at once.
+func streamFormat(c *gin.Context) string {
+	accept := c.GetHeader("Accept")
+	switch {
+	case strings.Contains(accept, contentTypeNdjson):
+		return contentTypeNdjson
+	case strings.Contains(accept, sse.ContentType):
+		return sse.ContentType
+	}
+	return ""
+}
+
+// streamRepoEvents writes every event as soon as it arrives, either as one
+// JSON document per line or as Server-Sent Events.
+func streamRepoEvents(c *gin.Context, format string, events <-chan repositories.CreateReposEvent) {
+	c.Status(http.StatusOK)
+	c.Header("Cache-Control", "no-cache")
+	if format == contentTypeNdjson {
+		c.Header("Content-Type", contentTypeNdjson)
+	}
+
+	for event := range events {
+		if format == contentTypeNdjson {
+			line, _ := json.Marshal(event)
+			c.Writer.Write(append(line, '\n'))
+		} else if event.Result != nil {
+			c.Render(-1, sse.Event{Id: strconv.Itoa(event.Result.Index), Event: "result", Data: event.Result})
+		} else {
+			c.Render(-1, sse.Event{Event: "summary", Data: event.Summary})
+		}
+		c.Writer.Flush()
+	}
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
 	"github.com/evertonkopec/golang-microservices-main/src/api/services"
@@ -15,19 +16,30 @@ import (
 
 var (
 	funcCreateRepo func(clientId string, request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError)
-	funcCreateRepos func(request []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError)
+	funcCreateRepos func(clientId string, request []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError)
+	funcStreamRepos func(clientId string, request []repositories.CreateRepoRequest) (<-chan repositories.CreateReposEvent, errors.ApiError)
 )
 type repoServiceMock struct {}
 
 func (s*repoServiceMock) CreateRepo(clientId string, request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError){
-	return funcCreateRepo("TODO_client_id", request)
+	return funcCreateRepo(clientId, request)
 }
-func (s*repoServiceMock) CreateRepos(request []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError){
-	return funcCreateRepos(request)
+func (s*repoServiceMock) CreateRepos(ctx context.Context, clientId string, request []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (repositories.CreateReposResponse, errors.ApiError){
+	return funcCreateRepos(clientId, request)
 }
 
 
+func (s*repoServiceMock) StreamRepos(ctx context.Context, clientId string, request []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (<-chan repositories.CreateReposEvent, errors.ApiError){
+	return funcStreamRepos(clientId, request)
+}
+
+func (s*repoServiceMock) DryRunRepos(clientId string, request []repositories.CreateRepoRequest) (repositories.DryRunResponse, errors.ApiError){
+	return repositories.DryRunResponse{}, errors.NewInternalServerError("not implemented")
+}
+
 func TestCreateRepoNoErrorMockingTheEntireService(t *testing.T){
+	originalService := services.RepositoryService
+	defer func() { services.RepositoryService = originalService }()
 	services.RepositoryService = &repoServiceMock{}
 
 	funcCreateRepo = func(clientId string, request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError){
@@ -54,6 +66,8 @@ func TestCreateRepoNoErrorMockingTheEntireService(t *testing.T){
 }
 
 func TestCreateRepoErrorFromGithubMockingTheEntireService(t *testing.T){
+	originalService := services.RepositoryService
+	defer func() { services.RepositoryService = originalService }()
 	services.RepositoryService = &repoServiceMock{}
 
 	funcCreateRepo = func(clientId string, request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError){
@@ -67,6 +81,7 @@ func TestCreateRepoErrorFromGithubMockingTheEntireService(t *testing.T){
 
 	CreateRepo(c)
 	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, errors.ContentTypeProblemJson, response.Header().Get("Content-Type"))
 
 	apiErr, err:= errors.NewApiErrorFromBytes(response.Body.Bytes())
 	assert.Nil(t, err)
@@ -74,4 +89,51 @@ func TestCreateRepoErrorFromGithubMockingTheEntireService(t *testing.T){
 	assert.EqualValues(t, http.StatusBadRequest, apiErr.Status())
 	assert.EqualValues(t, "invalid repository name", apiErr.Message())
 
-}
\ No newline at end of file
+}
+func TestCreateReposPropagatesClientIdMockingTheEntireService(t *testing.T){
+	originalService := services.RepositoryService
+	defer func() { services.RepositoryService = originalService }()
+	services.RepositoryService = &repoServiceMock{}
+
+	var receivedClientId string
+	funcCreateRepos = func(clientId string, request []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError){
+		receivedClientId = clientId
+		return repositories.CreateReposResponse{
+			StatusCode: http.StatusCreated,
+			Results: []repositories.CreateRepositoriesResult{
+				{Index: 0, Response: &repositories.CreateRepoResponse{Id: 321}},
+			},
+		}, nil
+	}
+
+	request, _ := http.NewRequest(http.MethodPost, "/repositories", strings.NewReader(`[{"name":"testing"}]`))
+	request.Header.Set("X-Client-Id", "client-123")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request,response)
+
+	CreateRepos(c)
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+	assert.EqualValues(t, "client-123", receivedClientId)
+}
+
+func TestCreateRepoPendingApprovalMockingTheEntireService(t *testing.T) {
+	originalService := services.RepositoryService
+	defer func() { services.RepositoryService = originalService }()
+	services.RepositoryService = &repoServiceMock{}
+
+	funcCreateRepo = func(clientId string, request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+		return &repositories.CreateRepoResponse{Name: request.Name, ApprovalId: "abc"}, nil
+	}
+
+	request, _ := http.NewRequest(http.MethodPost, "/repositories", strings.NewReader(`{"name":"prod-api"}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	CreateRepo(c)
+	assert.EqualValues(t, http.StatusAccepted, response.Code)
+	assert.EqualValues(t, "/approvals/abc", response.Header().Get("Location"))
+	var result repositories.CreateRepoResponse
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "abc", result.ApprovalId)
+}
@@ -85,3 +85,91 @@ func TestCreateRepoNoError(t *testing.T){
 	assert.EqualValues(t, "", result.Name)
 	assert.EqualValues(t, "", result.Owner)
 }
+
+func TestCreateReposStreamNdjson(t *testing.T){
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "id": 123, "name":"testing", "owner":{"login":"EBKopec" }}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+
+	request, _ := http.NewRequest(http.MethodPost, "/repositories", strings.NewReader(`[{"name":"testing"},{"name":""}]`))
+	request.Header.Set("Accept", "application/x-ndjson")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request,response)
+
+	CreateRepos(c)
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, "application/x-ndjson", response.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(response.Body.String()), "\n")
+	assert.EqualValues(t, 3, len(lines))
+
+	var summary struct {
+		Summary repositories.CreateReposSummary `json:"summary"`
+	}
+	err := json.Unmarshal([]byte(lines[2]), &summary)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, summary.Summary.StatusCode)
+	assert.EqualValues(t, 2, summary.Summary.Total)
+	assert.EqualValues(t, 1, summary.Summary.Succeeded)
+	assert.EqualValues(t, 1, summary.Summary.Failed)
+	assert.Contains(t, response.Body.String(), `"index":1`)
+}
+
+func TestCreateReposStreamServerSentEvents(t *testing.T){
+	request, _ := http.NewRequest(http.MethodPost, "/repositories", strings.NewReader(`[{"name":""}]`))
+	request.Header.Set("Accept", "text/event-stream")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request,response)
+
+	CreateRepos(c)
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	assert.EqualValues(t, "text/event-stream", response.Header().Get("Content-Type"))
+	assert.Contains(t, response.Body.String(), "id:0\nevent:result\ndata:{\"index\":0,")
+	assert.Contains(t, response.Body.String(), "event:summary\ndata:{\"status\":400,\"total\":1,\"succeeded\":0,\"failed\":1}")
+}
+
+func TestCreateReposStreamInvalidBatch(t *testing.T){
+	request, _ := http.NewRequest(http.MethodPost, "/repositories", strings.NewReader(`[]`))
+	request.Header.Set("Accept", "application/x-ndjson")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request,response)
+
+	CreateRepos(c)
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, errors.ContentTypeProblemJson, response.Header().Get("Content-Type"))
+}
+
+func TestCreateRepoDryRun(t *testing.T){
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1, "login": "EBKopec"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+
+	request, _ := http.NewRequest(http.MethodPost, "/repository?dry_run=true", strings.NewReader(`{"name":"testing"}`))
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request,response)
+
+	CreateRepo(c)
+	assert.EqualValues(t, http.StatusOK, response.Code)
+	var result repositories.DryRunResult
+	err := json.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "create", result.Action)
+	assert.EqualValues(t, "EBKopec", result.Owner)
+	assert.EqualValues(t, "testing", result.Name)
+}
@@ -0,0 +1,66 @@
+package approvals
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"time"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+
+	DecisionApprove = "approve"
+	DecisionReject  = "reject"
+)
+
+// Approval is a repository request held back by a policy until an admin
+// approves or rejects it. QuotaDay is the day the request was counted
+// against the quota of the client. Repo and Error hold the outcome of the
+// creation that follows an approval.
+type Approval struct {
+	Id         string                           `json:"id"`
+	ClientId   string                           `json:"client_id"`
+	QuotaDay   string                           `json:"quota_day"`
+	Request    repositories.CreateRepoRequest   `json:"request"`
+	Rule       string                           `json:"rule"`
+	Violations []repositories.FieldError        `json:"violations,omitempty"`
+	Status     string                           `json:"status"`
+	CreatedAt  time.Time                        `json:"created_at"`
+	DecidedBy  string                           `json:"decided_by,omitempty"`
+	DecidedAt  *time.Time                       `json:"decided_at,omitempty"`
+	Reason     string                           `json:"reason,omitempty"`
+	Repo       *repositories.CreateRepoResponse `json:"repo,omitempty"`
+	Error      errors.ApiError                  `json:"error,omitempty"`
+}
+
+// Redacted returns a copy without the secrets of the request, safe to be
+// listed.
+func (a Approval) Redacted() Approval {
+	a.Request.RepoSettings = a.Request.RepoSettings.Redacted()
+	return a
+}
+
+type DecisionRequest struct {
+	Decision string `json:"decision"`
+	Reason   string `json:"reason"`
+}
+
+func (r DecisionRequest) Validate() errors.ApiError {
+	if r.Decision != DecisionApprove && r.Decision != DecisionReject {
+		return errors.NewBadRequestError("decision must be approve or reject")
+	}
+	return nil
+}
+
+// Filter selects approvals; empty fields match every approval.
+type Filter struct {
+	ClientId string
+	Status   string
+}
+
+func (f Filter) Matches(approval Approval) bool {
+	return (f.ClientId == "" || f.ClientId == approval.ClientId) &&
+		(f.Status == "" || f.Status == approval.Status)
+}
@@ -0,0 +1,124 @@
+package approvals
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ApprovalsDao approvalsDaoInterface
+)
+
+func init() {
+	ApprovalsDao = NewApprovalsDao()
+}
+
+type approvalsDaoInterface interface {
+	Save(approval Approval) (*Approval, errors.ApiError)
+	Get(id string) (*Approval, errors.ApiError)
+	Find(filter Filter) []Approval
+	Decide(id string, decision DecisionRequest, decidedBy string) (*Approval, errors.ApiError)
+	Complete(id string, repo *repositories.CreateRepoResponse, err errors.ApiError) (*Approval, errors.ApiError)
+}
+
+type approvalsDao struct {
+	lock      sync.RWMutex
+	approvals map[string]*Approval
+}
+
+func NewApprovalsDao() approvalsDaoInterface {
+	return &approvalsDao{approvals: make(map[string]*Approval)}
+}
+
+// Save stores a new pending approval with a random id.
+func (d *approvalsDao) Save(approval Approval) (*Approval, errors.ApiError) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, errors.NewInternalServerError("error when trying to generate approval id")
+	}
+	approval.Id = hex.EncodeToString(bytes)
+	approval.Status = StatusPending
+	approval.CreatedAt = time.Now().UTC()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.approvals[approval.Id] = &approval
+	result := approval
+	return &result, nil
+}
+
+func (d *approvalsDao) Get(id string) (*Approval, errors.ApiError) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	approval := d.approvals[id]
+	if approval == nil {
+		return nil, errors.NewNotFoundApiError(fmt.Sprintf("approval %s not found", id))
+	}
+	result := *approval
+	return &result, nil
+}
+
+// Find returns the matching approvals, oldest first.
+func (d *approvalsDao) Find(filter Filter) []Approval {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	result := make([]Approval, 0)
+	for _, approval := range d.approvals {
+		if filter.Matches(*approval) {
+			result = append(result, *approval)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// Decide moves a pending approval to approved or rejected. Deciding twice is
+// a conflict, so two admins can not both trigger the creation.
+func (d *approvalsDao) Decide(id string, decision DecisionRequest, decidedBy string) (*Approval, errors.ApiError) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	approval := d.approvals[id]
+	if approval == nil {
+		return nil, errors.NewNotFoundApiError(fmt.Sprintf("approval %s not found", id))
+	}
+	if approval.Status != StatusPending {
+		return nil, errors.NewConflictError(fmt.Sprintf("approval %s is already %s", id, approval.Status))
+	}
+
+	now := time.Now().UTC()
+	approval.Status = StatusRejected
+	if decision.Decision == DecisionApprove {
+		approval.Status = StatusApproved
+	}
+	approval.DecidedBy = decidedBy
+	approval.DecidedAt = &now
+	approval.Reason = decision.Reason
+	result := *approval
+	return &result, nil
+}
+
+// Complete records the outcome of creating the repository of an approval.
+func (d *approvalsDao) Complete(id string, repo *repositories.CreateRepoResponse, err errors.ApiError) (*Approval, errors.ApiError) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	approval := d.approvals[id]
+	if approval == nil {
+		return nil, errors.NewNotFoundApiError(fmt.Sprintf("approval %s not found", id))
+	}
+	approval.Repo = repo
+	approval.Error = err
+	result := *approval
+	return &result, nil
+}
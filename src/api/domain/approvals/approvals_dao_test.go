@@ -0,0 +1,54 @@
+package approvals
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestSaveAndFind(t *testing.T) {
+	dao := NewApprovalsDao()
+	first, err := dao.Save(Approval{ClientId: "team-a", Request: repositories.CreateRepoRequest{Name: "first"}})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, first.Id)
+	assert.EqualValues(t, StatusPending, first.Status)
+	dao.Save(Approval{ClientId: "team-b", Request: repositories.CreateRepoRequest{Name: "second"}})
+
+	assert.EqualValues(t, 2, len(dao.Find(Filter{})))
+	found := dao.Find(Filter{ClientId: "team-a", Status: StatusPending})
+	assert.EqualValues(t, 1, len(found))
+	assert.EqualValues(t, "first", found[0].Request.Name)
+	assert.EqualValues(t, 0, len(dao.Find(Filter{Status: StatusApproved})))
+
+	_, err = dao.Get("missing")
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+}
+
+func TestDecideOnlyOnce(t *testing.T) {
+	dao := NewApprovalsDao()
+	approval, _ := dao.Save(Approval{ClientId: "team-a"})
+
+	decided, err := dao.Decide(approval.Id, DecisionRequest{Decision: DecisionApprove, Reason: "fine"}, "admin")
+	assert.Nil(t, err)
+	assert.EqualValues(t, StatusApproved, decided.Status)
+	assert.EqualValues(t, "admin", decided.DecidedBy)
+	assert.EqualValues(t, "fine", decided.Reason)
+	assert.NotNil(t, decided.DecidedAt)
+
+	_, err = dao.Decide(approval.Id, DecisionRequest{Decision: DecisionReject}, "admin")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusConflict, err.Status())
+	assert.EqualValues(t, "approval "+approval.Id+" is already approved", err.Message())
+
+	completed, err := dao.Complete(approval.Id, nil, errors.NewInternalServerError("boom"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "boom", completed.Error.Message())
+}
+
+func TestDecisionRequestValidate(t *testing.T) {
+	assert.Nil(t, DecisionRequest{Decision: DecisionReject}.Validate())
+	err := DecisionRequest{Decision: "maybe"}.Validate()
+	assert.EqualValues(t, "decision must be approve or reject", err.Message())
+}
@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// fileStore keeps every record in memory for queries and appends it as one
+// JSON document per line to a file, which is replayed on start.
+type fileStore struct {
+	memory *memoryStore
+	lock   sync.Mutex
+	file   *os.File
+}
+
+func NewFileStore(path string) (RecordStore, error) {
+	memory := &memoryStore{records: make([]RepositoryRecord, 0)}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record RepositoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("invalid audit record at %s:%d: %w", path, line, err)
+		}
+		memory.records = append(memory.records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &fileStore{memory: memory, file: file}, nil
+}
+
+func (s *fileStore) Save(record RepositoryRecord) (*RepositoryRecord, errors.ApiError) {
+	if err := assignId(&record); err != nil {
+		return nil, err
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, errors.Wrap(err, http.StatusInternalServerError, "error when trying to encode audit record")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return nil, errors.Wrap(err, http.StatusInternalServerError, "error when trying to write audit record")
+	}
+	return s.memory.Save(record)
+}
+
+func (s *fileStore) Find(filter RecordFilter) ([]RepositoryRecord, errors.ApiError) {
+	return s.memory.Find(filter)
+}
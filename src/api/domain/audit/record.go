@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"time"
+)
+
+const (
+	ActionCreate    = "create"
+	ActionDelete    = "delete"
+	ActionRename    = "rename"
+	ActionTransfer  = "transfer"
+	ActionArchive   = "archive"
+	ActionUnarchive = "unarchive"
+	// ActionUpdate changes the settings of a repository, see Changes.
+	ActionUpdate = "update"
+
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// RepositoryRecord tells who did what to a repository, when, and how it went.
+type RepositoryRecord struct {
+	Id         string                          `json:"id"`
+	ClientId   string                          `json:"client_id"`
+	Action     string                          `json:"action"`
+	Owner      string                          `json:"owner,omitempty"`
+	Name       string                          `json:"name"`
+	Request    *repositories.CreateRepoRequest `json:"request,omitempty"`
+	Response   *github.CreateRepoResponse      `json:"response,omitempty"`
+	NewOwner   string                          `json:"new_owner,omitempty"`
+	NewName    string                          `json:"new_name,omitempty"`
+	Changes    *RepositoryChanges              `json:"changes,omitempty"`
+	Status     string                          `json:"status"`
+	StatusCode int                             `json:"status_code"`
+	Error      string                          `json:"error,omitempty"`
+	CreatedAt  time.Time                       `json:"created_at"`
+}
+
+// RepositoryChanges are the settings an update gave a repository. Fields left
+// nil were not changed, and neither were collaborators and protected branches
+// not listed.
+type RepositoryChanges struct {
+	Description      *string                         `json:"description,omitempty"`
+	Homepage         *string                         `json:"homepage,omitempty"`
+	Private          *bool                           `json:"private,omitempty"`
+	HasIssues        *bool                           `json:"has_issues,omitempty"`
+	HasProjects      *bool                           `json:"has_projects,omitempty"`
+	HasWiki          *bool                           `json:"has_wiki,omitempty"`
+	Topics           *[]string                       `json:"topics,omitempty"`
+	Collaborators    map[string]string               `json:"collaborators,omitempty"`
+	BranchProtection []repositories.BranchProtection `json:"branch_protection,omitempty"`
+}
+
+// NewRepositoryRecord builds the record of an attempt, failed when err is set.
+func NewRepositoryRecord(clientId string, action string, owner string, name string, statusCode int, err errors.ApiError) RepositoryRecord {
+	record := RepositoryRecord{
+		ClientId:   clientId,
+		Action:     action,
+		Owner:      owner,
+		Name:       name,
+		Status:     StatusSucceeded,
+		StatusCode: statusCode,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err != nil {
+		record.Status = StatusFailed
+		record.StatusCode = err.Status()
+		record.Error = err.Error()
+	}
+	return record
+}
+
+type RecordFilter struct {
+	ClientId string
+	Action   string
+	Status   string
+	From     time.Time
+	To       time.Time
+}
+
+// Matches reports whether record satisfies every criteria set in the filter.
+// Zero values match everything; From is inclusive and To exclusive.
+func (f RecordFilter) Matches(record RepositoryRecord) bool {
+	if f.ClientId != "" && f.ClientId != record.ClientId {
+		return false
+	}
+	if f.Action != "" && f.Action != record.Action {
+		return false
+	}
+	if f.Status != "" && f.Status != record.Status {
+		return false
+	}
+	if !f.From.IsZero() && record.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !record.CreatedAt.Before(f.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+	"sync"
+)
+
+var (
+	Store RecordStore
+)
+
+func init() {
+	if path := config.GetAuditStorePath(); path != "" {
+		store, err := NewFileStore(path)
+		if err != nil {
+			panic(err)
+		}
+		Store = store
+		return
+	}
+	Store = NewMemoryStore()
+}
+
+type RecordStore interface {
+	Save(record RepositoryRecord) (*RepositoryRecord, errors.ApiError)
+	Find(filter RecordFilter) ([]RepositoryRecord, errors.ApiError)
+}
+
+type memoryStore struct {
+	lock    sync.RWMutex
+	records []RepositoryRecord
+}
+
+func NewMemoryStore() RecordStore {
+	return &memoryStore{records: make([]RepositoryRecord, 0)}
+}
+
+func (s *memoryStore) Save(record RepositoryRecord) (*RepositoryRecord, errors.ApiError) {
+	if err := assignId(&record); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.records = append(s.records, record)
+	return &record, nil
+}
+
+// Find returns the matching records, oldest first.
+func (s *memoryStore) Find(filter RecordFilter) ([]RepositoryRecord, errors.ApiError) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	result := make([]RepositoryRecord, 0)
+	for _, record := range s.records {
+		if filter.Matches(record) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+func assignId(record *RepositoryRecord) errors.ApiError {
+	if record.Id != "" {
+		return nil
+	}
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return errors.Wrap(err, http.StatusInternalServerError, "error when trying to create audit record id")
+	}
+	record.Id = hex.EncodeToString(bytes)
+	return nil
+}
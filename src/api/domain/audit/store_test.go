@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewRepositoryRecord(t *testing.T) {
+	record := NewRepositoryRecord("client", ActionCreate, "EBKopec", "testing", http.StatusCreated, nil)
+	assert.EqualValues(t, "client", record.ClientId)
+	assert.EqualValues(t, ActionCreate, record.Action)
+	assert.EqualValues(t, StatusSucceeded, record.Status)
+	assert.EqualValues(t, http.StatusCreated, record.StatusCode)
+	assert.EqualValues(t, "", record.Error)
+	assert.False(t, record.CreatedAt.IsZero())
+
+	record = NewRepositoryRecord("client", ActionCreate, "", "testing", http.StatusCreated, errors.NewConflictError("already exists"))
+	assert.EqualValues(t, StatusFailed, record.Status)
+	assert.EqualValues(t, http.StatusConflict, record.StatusCode)
+	assert.EqualValues(t, "already exists", record.Error)
+}
+
+func TestRecordFilterMatches(t *testing.T) {
+	day := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	record := RepositoryRecord{ClientId: "client", Action: ActionCreate, Status: StatusFailed, CreatedAt: day}
+
+	assert.True(t, RecordFilter{}.Matches(record))
+	assert.True(t, RecordFilter{ClientId: "client", Action: ActionCreate, Status: StatusFailed}.Matches(record))
+	assert.False(t, RecordFilter{ClientId: "other"}.Matches(record))
+	assert.False(t, RecordFilter{Action: ActionDelete}.Matches(record))
+	assert.False(t, RecordFilter{Status: StatusSucceeded}.Matches(record))
+	assert.True(t, RecordFilter{From: day, To: day.Add(time.Second)}.Matches(record))
+	assert.False(t, RecordFilter{From: day.Add(time.Second)}.Matches(record))
+	assert.False(t, RecordFilter{To: day}.Matches(record))
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+
+	saved, err := store.Save(RepositoryRecord{ClientId: "first", Status: StatusSucceeded})
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", saved.Id)
+	store.Save(RepositoryRecord{ClientId: "second", Status: StatusFailed})
+	store.Save(RepositoryRecord{ClientId: "first", Status: StatusFailed})
+
+	records, err := store.Find(RecordFilter{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, len(records))
+	assert.EqualValues(t, saved.Id, records[0].Id)
+
+	records, err = store.Find(RecordFilter{ClientId: "first", Status: StatusFailed})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(records))
+	assert.EqualValues(t, "first", records[0].ClientId)
+}
+
+func TestFileStorePersistsRecords(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "audit")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "records.jsonl")
+
+	store, err := NewFileStore(path)
+	assert.Nil(t, err)
+	saved, apiErr := store.Save(RepositoryRecord{
+		ClientId: "client",
+		Action:   ActionCreate,
+		Name:     "testing",
+		Request:  &repositories.CreateRepoRequest{Name: "testing"},
+		Response: &github.CreateRepoResponse{Id: 123, Name: "testing"},
+		Status:   StatusSucceeded,
+	})
+	assert.Nil(t, apiErr)
+
+	reopened, err := NewFileStore(path)
+	assert.Nil(t, err)
+	records, apiErr := reopened.Find(RecordFilter{ClientId: "client"})
+	assert.Nil(t, apiErr)
+	assert.EqualValues(t, 1, len(records))
+	assert.EqualValues(t, saved.Id, records[0].Id)
+	assert.EqualValues(t, "testing", records[0].Request.Name)
+	assert.EqualValues(t, 123, records[0].Response.Id)
+}
+
+func TestFileStoreInvalidFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "audit")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "records.jsonl")
+	ioutil.WriteFile(path, []byte("{\"id\":\"1\"}\nnot json\n"), 0600)
+
+	store, err := NewFileStore(path)
+	assert.Nil(t, store)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "records.jsonl:2")
+}
@@ -0,0 +1,55 @@
+package clients
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"time"
+)
+
+// Quotas limit what a client can do. Zero values fall back to the defaults
+// from the configuration.
+type Quotas struct {
+	ReposPerDay       int `json:"repos_per_day" yaml:"repos_per_day"`
+	MaxBatchSize      int `json:"max_batch_size" yaml:"max_batch_size"`
+	MaxConcurrentJobs int `json:"max_concurrent_jobs" yaml:"max_concurrent_jobs"`
+}
+
+// Client is a caller identified by its X-Client-Id header. Admins decide on
+// the requests waiting for approval.
+type Client struct {
+	Id     string `json:"id" yaml:"id"`
+	Admin  bool   `json:"admin" yaml:"admin"`
+	Quotas Quotas `json:"quotas" yaml:"quotas"`
+}
+
+func DefaultQuotas() Quotas {
+	return Quotas{
+		ReposPerDay:       config.GetClientReposPerDay(),
+		MaxBatchSize:      config.GetRepoBatchMaxSize(),
+		MaxConcurrentJobs: config.GetClientMaxConcurrentJobs(),
+	}
+}
+
+func (q Quotas) withDefaults() Quotas {
+	defaults := DefaultQuotas()
+	if q.ReposPerDay <= 0 {
+		q.ReposPerDay = defaults.ReposPerDay
+	}
+	if q.MaxBatchSize <= 0 {
+		q.MaxBatchSize = defaults.MaxBatchSize
+	}
+	if q.MaxConcurrentJobs <= 0 {
+		q.MaxConcurrentJobs = defaults.MaxConcurrentJobs
+	}
+	return q
+}
+
+// UsageReport is what a client consumed today against its quotas.
+type UsageReport struct {
+	ClientId       string    `json:"client_id"`
+	Quotas         Quotas    `json:"quotas"`
+	Day            string    `json:"day"`
+	ReposCreated   int       `json:"repos_created"`
+	ReposRemaining int       `json:"repos_remaining"`
+	ActiveJobs     int       `json:"active_jobs"`
+	ResetsAt       time.Time `json:"resets_at"`
+}
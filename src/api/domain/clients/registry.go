@@ -0,0 +1,79 @@
+package clients
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"sync"
+)
+
+var (
+	ClientRegistry clientRegistryInterface
+)
+
+func init() {
+	registry, err := LoadRegistry(config.GetClientRegistryPath())
+	if err != nil {
+		panic(err)
+	}
+	ClientRegistry = registry
+}
+
+type clientRegistryInterface interface {
+	Get(id string) Client
+}
+
+type clientRegistry struct {
+	lock    sync.RWMutex
+	clients map[string]Client
+}
+
+type registryFile struct {
+	Clients []Client `yaml:"clients"`
+}
+
+func NewRegistry(clients ...Client) clientRegistryInterface {
+	result := &clientRegistry{clients: make(map[string]Client)}
+	for _, client := range clients {
+		result.clients[client.Id] = client
+	}
+	return result
+}
+
+// LoadRegistry reads the clients from a YAML or JSON file. An empty path
+// returns an empty registry.
+func LoadRegistry(path string) (clientRegistryInterface, error) {
+	if path == "" {
+		return NewRegistry(), nil
+	}
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file registryFile
+	if err := yaml.UnmarshalStrict(bytes, &file); err != nil {
+		return nil, fmt.Errorf("invalid client registry %s: %w", path, err)
+	}
+	for index, client := range file.Clients {
+		if client.Id == "" {
+			return nil, fmt.Errorf("invalid client registry %s: client %d has no id", path, index)
+		}
+	}
+	return NewRegistry(file.Clients...), nil
+}
+
+// Get returns the client with its quotas. Clients not in the registry get the
+// default quotas.
+func (r *clientRegistry) Get(id string) Client {
+	r.lock.RLock()
+	client, exists := r.clients[id]
+	r.lock.RUnlock()
+
+	if !exists {
+		client = Client{Id: id}
+	}
+	client.Quotas = client.Quotas.withDefaults()
+	return client
+}
@@ -0,0 +1,56 @@
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryGet(t *testing.T) {
+	registry := NewRegistry(Client{Id: "team-a", Quotas: Quotas{ReposPerDay: 5}})
+
+	client := registry.Get("team-a")
+	assert.EqualValues(t, "team-a", client.Id)
+	assert.EqualValues(t, 5, client.Quotas.ReposPerDay)
+	assert.EqualValues(t, 100, client.Quotas.MaxBatchSize)
+	assert.EqualValues(t, 5, client.Quotas.MaxConcurrentJobs)
+
+	client = registry.Get("unknown")
+	assert.EqualValues(t, "unknown", client.Id)
+	assert.EqualValues(t, DefaultQuotas(), client.Quotas)
+}
+
+func TestLoadRegistry(t *testing.T) {
+	registry, err := LoadRegistry("")
+	assert.Nil(t, err)
+	assert.EqualValues(t, DefaultQuotas(), registry.Get("any").Quotas)
+
+	dir, _ := ioutil.TempDir("", "clients")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "clients.yaml")
+	ioutil.WriteFile(path, []byte("clients:\n  - id: team-a\n    quotas:\n      repos_per_day: 3\n      max_concurrent_jobs: 1\n"), 0600)
+
+	registry, err = LoadRegistry(path)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 3, registry.Get("team-a").Quotas.ReposPerDay)
+	assert.EqualValues(t, 1, registry.Get("team-a").Quotas.MaxConcurrentJobs)
+}
+
+func TestLoadRegistryInvalidFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "clients")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "clients.yaml")
+
+	ioutil.WriteFile(path, []byte("clients:\n  - quotas:\n      repos_per_day: 3\n"), 0600)
+	registry, err := LoadRegistry(path)
+	assert.Nil(t, registry)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "client 0 has no id")
+
+	ioutil.WriteFile(path, []byte("clients:\n  - id: team-a\n    quota: {}\n"), 0600)
+	registry, err = LoadRegistry(path)
+	assert.Nil(t, registry)
+	assert.NotNil(t, err)
+}
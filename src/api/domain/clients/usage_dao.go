@@ -0,0 +1,190 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	dayLayout = "2006-01-02"
+
+	// jobRetryAfter is the hint given to clients running too many jobs, since
+	// there is no telling when one of them finishes.
+	jobRetryAfter = 30 * time.Second
+)
+
+var (
+	UsageDao usageDaoInterface
+)
+
+func init() {
+	dao, err := NewUsageDao(config.GetClientUsagePath())
+	if err != nil {
+		panic(err)
+	}
+	UsageDao = dao
+}
+
+type usageDaoInterface interface {
+	ConsumeRepos(clientId string, count int, limit int) (string, errors.ApiError)
+	RefundRepos(clientId string, day string, count int)
+	StartJob(clientId string, limit int) errors.ApiError
+	FinishJob(clientId string)
+	Get(clientId string) UsageReport
+}
+
+type dailyUsage struct {
+	Day   string `json:"day"`
+	Repos int    `json:"repos"`
+}
+
+// usageDao counts the repositories created per client and UTC day, persisted
+// to path when set, and the jobs running per client, which do not survive a
+// restart anyway.
+type usageDao struct {
+	lock  sync.Mutex
+	path  string
+	now   func() time.Time
+	repos map[string]*dailyUsage
+	jobs  map[string]int
+}
+
+func NewUsageDao(path string) (*usageDao, error) {
+	result := &usageDao{
+		path:  path,
+		now:   time.Now,
+		repos: make(map[string]*dailyUsage),
+		jobs:  make(map[string]int),
+	}
+	if path == "" {
+		return result, nil
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bytes, &result.repos); err != nil {
+		return nil, fmt.Errorf("invalid usage file %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// ConsumeRepos counts count more repositories for today, failing without
+// counting anything when that goes over limit. It returns the day the
+// repositories were counted on, to refund them against.
+func (d *usageDao) ConsumeRepos(clientId string, count int, limit int) (string, errors.ApiError) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	usage := d.today(clientId)
+	if usage.Repos+count > limit {
+		now := d.now().UTC()
+		return "", errors.NewTooManyRequestsErrorWithRetry(
+			fmt.Sprintf("daily repository quota exceeded: %d of %d repositories already created today", usage.Repos, limit),
+			nextDay(now).Sub(now))
+	}
+	usage.Repos += count
+	d.persist()
+	return usage.Day, nil
+}
+
+// RefundRepos gives back repositories counted on day that were not created.
+// Nothing is given back once day is over: its quota is gone anyway and the
+// one of today is not the one they were counted against.
+func (d *usageDao) RefundRepos(clientId string, day string, count int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	usage := d.today(clientId)
+	if usage.Day != day {
+		return
+	}
+	usage.Repos -= count
+	if usage.Repos < 0 {
+		usage.Repos = 0
+	}
+	d.persist()
+}
+
+func (d *usageDao) StartJob(clientId string, limit int) errors.ApiError {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.jobs[clientId] >= limit {
+		return errors.NewTooManyRequestsErrorWithRetry(fmt.Sprintf("at most %d jobs can run at the same time", limit), jobRetryAfter)
+	}
+	d.jobs[clientId]++
+	return nil
+}
+
+func (d *usageDao) FinishJob(clientId string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.jobs[clientId] <= 1 {
+		delete(d.jobs, clientId)
+		return
+	}
+	d.jobs[clientId]--
+}
+
+// Get returns the usage of today, leaving the quotas to the caller.
+func (d *usageDao) Get(clientId string) UsageReport {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	usage := d.today(clientId)
+	return UsageReport{
+		ClientId:     clientId,
+		Day:          usage.Day,
+		ReposCreated: usage.Repos,
+		ActiveJobs:   d.jobs[clientId],
+		ResetsAt:     nextDay(d.now().UTC()),
+	}
+}
+
+// today returns the usage of the current day, starting over when the stored
+// one is from a previous day.
+func (d *usageDao) today(clientId string) *dailyUsage {
+	day := d.now().UTC().Format(dayLayout)
+	usage := d.repos[clientId]
+	if usage == nil || usage.Day != day {
+		usage = &dailyUsage{Day: day}
+		d.repos[clientId] = usage
+	}
+	return usage
+}
+
+// persist writes the counters to a temporary file renamed over the previous
+// one, so a crash never leaves a truncated file behind. Failures are logged:
+// losing counters must not fail requests.
+func (d *usageDao) persist() {
+	if d.path == "" {
+		return
+	}
+	bytes, err := json.Marshal(d.repos)
+	if err == nil {
+		temporary := d.path + ".tmp"
+		if err = ioutil.WriteFile(temporary, bytes, 0600); err == nil {
+			err = os.Rename(temporary, d.path)
+		}
+	}
+	if err != nil {
+		option_b.Error("error when trying to persist client usage", err, option_b.Field("path", d.path))
+	}
+}
+
+func nextDay(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,112 @@
+package clients
+
+import (
+	stderrors "errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConsumeRepos(t *testing.T) {
+	dao, _ := NewUsageDao("")
+	now := time.Date(2021, 10, 1, 23, 59, 30, 0, time.UTC)
+	dao.now = func() time.Time { return now }
+
+	day, err := dao.ConsumeRepos("client", 2, 3)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "2021-10-01", day)
+	_, err = dao.ConsumeRepos("client", 2, 3)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+	assert.EqualValues(t, "daily repository quota exceeded: 2 of 3 repositories already created today", err.Message())
+	assert.True(t, stderrors.Is(err, errors.ErrRateLimited))
+	assert.EqualValues(t, 30*time.Second, err.(interface{ RetryAfter() time.Duration }).RetryAfter())
+	_, err = dao.ConsumeRepos("other", 3, 3)
+	assert.Nil(t, err)
+
+	dao.RefundRepos("client", day, 1)
+	_, err = dao.ConsumeRepos("client", 2, 3)
+	assert.Nil(t, err)
+
+	now = now.Add(time.Minute)
+	usage := dao.Get("client")
+	assert.EqualValues(t, "2021-10-02", usage.Day)
+	assert.EqualValues(t, 0, usage.ReposCreated)
+	assert.EqualValues(t, time.Date(2021, 10, 3, 0, 0, 0, 0, time.UTC), usage.ResetsAt)
+}
+
+func TestRefundReposNeverGoesNegative(t *testing.T) {
+	dao, _ := NewUsageDao("")
+	dao.RefundRepos("client", dao.Get("client").Day, 5)
+	assert.EqualValues(t, 0, dao.Get("client").ReposCreated)
+}
+
+func TestRefundReposOfPreviousDay(t *testing.T) {
+	dao, _ := NewUsageDao("")
+	now := time.Date(2021, 10, 1, 23, 59, 30, 0, time.UTC)
+	dao.now = func() time.Time { return now }
+
+	day, err := dao.ConsumeRepos("client", 2, 3)
+	assert.Nil(t, err)
+
+	now = now.Add(time.Minute)
+	_, err = dao.ConsumeRepos("client", 1, 3)
+	assert.Nil(t, err)
+	dao.RefundRepos("client", day, 2)
+
+	usage := dao.Get("client")
+	assert.EqualValues(t, "2021-10-02", usage.Day)
+	assert.EqualValues(t, 1, usage.ReposCreated)
+}
+
+func TestJobs(t *testing.T) {
+	dao, _ := NewUsageDao("")
+
+	assert.Nil(t, dao.StartJob("client", 2))
+	assert.Nil(t, dao.StartJob("client", 2))
+	err := dao.StartJob("client", 2)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+	assert.EqualValues(t, "at most 2 jobs can run at the same time", err.Message())
+	assert.EqualValues(t, 2, dao.Get("client").ActiveJobs)
+
+	dao.FinishJob("client")
+	assert.Nil(t, dao.StartJob("client", 2))
+	dao.FinishJob("client")
+	dao.FinishJob("client")
+	dao.FinishJob("client")
+	assert.EqualValues(t, 0, dao.Get("client").ActiveJobs)
+}
+
+func TestUsagePersistedAcrossRestarts(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "usage")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "usage.json")
+
+	dao, err := NewUsageDao(path)
+	assert.Nil(t, err)
+	dao.ConsumeRepos("client", 4, 10)
+	dao.StartJob("client", 1)
+
+	restarted, err := NewUsageDao(path)
+	assert.Nil(t, err)
+	usage := restarted.Get("client")
+	assert.EqualValues(t, 4, usage.ReposCreated)
+	assert.EqualValues(t, 0, usage.ActiveJobs)
+}
+
+func TestNewUsageDaoInvalidFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "usage")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "usage.json")
+	ioutil.WriteFile(path, []byte("not json"), 0600)
+
+	dao, err := NewUsageDao(path)
+	assert.Nil(t, dao)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,57 @@
+package credentials
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"strings"
+)
+
+const (
+	// TypePersonalAccessToken is a personal access token of a GitHub account.
+	TypePersonalAccessToken = "pat"
+	// TypeGithubApp is an installation of a GitHub App, exchanged for short
+	// lived installation tokens.
+	TypeGithubApp = "github_app"
+	// TypeToken is any other token the provider accepts as is, such as an
+	// OAuth token.
+	TypeToken = "token"
+)
+
+// Credential is how the service authenticates against GitHub on behalf of a
+// client.
+type Credential struct {
+	Type           string `json:"type"`
+	Token          string `json:"token,omitempty"`
+	AppId          int64  `json:"app_id,omitempty"`
+	InstallationId int64  `json:"installation_id,omitempty"`
+	PrivateKey     string `json:"private_key,omitempty"`
+}
+
+func (c *Credential) Validate() errors.ApiError {
+	c.Token = strings.TrimSpace(c.Token)
+
+	causes := make([]interface{}, 0)
+	switch c.Type {
+	case TypePersonalAccessToken, TypeToken:
+		if c.Token == "" {
+			causes = append(causes, repositories.FieldError{Field: "token", Message: "token is required"})
+		}
+	case TypeGithubApp:
+		if c.AppId <= 0 {
+			causes = append(causes, repositories.FieldError{Field: "app_id", Message: "app id is required"})
+		}
+		if c.InstallationId <= 0 {
+			causes = append(causes, repositories.FieldError{Field: "installation_id", Message: "installation id is required"})
+		}
+		if strings.TrimSpace(c.PrivateKey) == "" {
+			causes = append(causes, repositories.FieldError{Field: "private_key", Message: "private key is required"})
+		}
+	default:
+		causes = append(causes, repositories.FieldError{Field: "type", Message: "type must be one of pat, github_app, token"})
+	}
+
+	if len(causes) == 0 {
+		return nil
+	}
+	return errors.NewValidationError("invalid credential", causes...)
+}
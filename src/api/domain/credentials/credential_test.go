@@ -0,0 +1,27 @@
+package credentials
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestCredentialValidate(t *testing.T) {
+	assert.Nil(t, (&Credential{Type: TypePersonalAccessToken, Token: "ghp_abc"}).Validate())
+	assert.Nil(t, (&Credential{Type: TypeToken, Token: "gho_abc"}).Validate())
+	assert.Nil(t, (&Credential{Type: TypeGithubApp, AppId: 1, InstallationId: 2, PrivateKey: "-----BEGIN"}).Validate())
+
+	err := (&Credential{Type: TypePersonalAccessToken, Token: " "}).Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, []interface{}{repositories.FieldError{Field: "token", Message: "token is required"}}, err.Causes())
+
+	err = (&Credential{Type: TypeGithubApp}).Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, 3, len(err.Causes()))
+
+	err = (&Credential{Type: "password"}).Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []interface{}{repositories.FieldError{Field: "type", Message: "type must be one of pat, github_app, token"}}, err.Causes())
+}
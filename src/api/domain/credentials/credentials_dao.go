@@ -0,0 +1,80 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/secrets"
+	"net/http"
+)
+
+var (
+	// CredentialsDao is nil when clients do not have their own credentials, in
+	// which case every client shares the configured GitHub token.
+	CredentialsDao credentialsDaoInterface
+)
+
+func init() {
+	if !config.GetClientCredentials() {
+		return
+	}
+	store, err := config.GetSecretStore()
+	if err != nil {
+		panic(err)
+	}
+	if store == nil {
+		panic(fmt.Errorf("client credentials require a secret store"))
+	}
+	CredentialsDao = NewSecretsDao(store)
+}
+
+type credentialsDaoInterface interface {
+	Get(clientId string) (*Credential, errors.ApiError)
+	Save(clientId string, credential Credential) errors.ApiError
+}
+
+// secretsDao keeps the credential of every client as a JSON encoded secret,
+// named after the client id. The store binds each secret to its name, so
+// credentials can not be swapped between clients.
+type secretsDao struct {
+	store secrets.Store
+}
+
+func NewSecretsDao(store secrets.Store) *secretsDao {
+	return &secretsDao{store: store}
+}
+
+func secretName(clientId string) string {
+	return config.SecretClientCredentialPrefix + clientId
+}
+
+func (d *secretsDao) Get(clientId string) (*Credential, errors.ApiError) {
+	value, err := d.store.Get(secretName(clientId))
+	if err == secrets.ErrNotFound {
+		return nil, errors.NewNotFoundApiError(fmt.Sprintf("no credential for client %s", clientId))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, http.StatusInternalServerError, "error when trying to read client credential")
+	}
+	var result Credential
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		return nil, errors.Wrap(err, http.StatusInternalServerError, "error when trying to decode client credential")
+	}
+	return &result, nil
+}
+
+// Save stores the credential of clientId, replacing the previous one.
+func (d *secretsDao) Save(clientId string, credential Credential) errors.ApiError {
+	if err := credential.Validate(); err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(credential)
+	if err != nil {
+		return errors.Wrap(err, http.StatusInternalServerError, "error when trying to encode client credential")
+	}
+	if err := d.store.Set(secretName(clientId), string(bytes)); err != nil {
+		return errors.Wrap(err, http.StatusInternalServerError, "error when trying to write client credential")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package credentials
+
+import (
+	"encoding/json"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/crypto"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/secrets"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func newTestDao(t *testing.T) (*secretsDao, string, []byte) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	key, _ := crypto.NewKey()
+	store, err := secrets.NewFileStore(path, key)
+	assert.Nil(t, err)
+	return NewSecretsDao(store), path, key
+}
+
+func TestSecretsDaoSaveAndGet(t *testing.T) {
+	dao, path, key := newTestDao(t)
+
+	credential, err := dao.Get("team-a")
+	assert.Nil(t, credential)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+
+	assert.Nil(t, dao.Save("team-a", Credential{Type: TypePersonalAccessToken, Token: "ghp_secret"}))
+
+	credential, err = dao.Get("team-a")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghp_secret", credential.Token)
+
+	bytes, _ := ioutil.ReadFile(path)
+	assert.NotContains(t, string(bytes), "ghp_secret")
+
+	store, openErr := secrets.NewFileStore(path, key)
+	assert.Nil(t, openErr)
+	credential, err = NewSecretsDao(store).Get("team-a")
+	assert.Nil(t, err)
+	assert.EqualValues(t, TypePersonalAccessToken, credential.Type)
+}
+
+func TestSecretsDaoSaveInvalidCredential(t *testing.T) {
+	dao, _, _ := newTestDao(t)
+
+	err := dao.Save("team-a", Credential{Type: TypePersonalAccessToken})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid credential", err.Message())
+}
+
+func TestSecretsDaoRefusesSwappedEntries(t *testing.T) {
+	dao, path, key := newTestDao(t)
+	dao.Save("team-a", Credential{Type: TypePersonalAccessToken, Token: "ghp_a"})
+	dao.Save("team-b", Credential{Type: TypePersonalAccessToken, Token: "ghp_b"})
+
+	var content map[string]interface{}
+	bytes, _ := ioutil.ReadFile(path)
+	json.Unmarshal(bytes, &content)
+	entries := content["secrets"].(map[string]interface{})
+	entries[secretName("team-b")] = entries[secretName("team-a")]
+	bytes, _ = json.Marshal(content)
+	ioutil.WriteFile(path, bytes, 0600)
+
+	store, _ := secrets.NewFileStore(path, key)
+	credential, err := NewSecretsDao(store).Get("team-b")
+	assert.Nil(t, credential)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+	assert.EqualValues(t, "error when trying to read client credential", err.Message())
+}
@@ -0,0 +1,49 @@
+package drift
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"strings"
+	"time"
+)
+
+// ManagedRepository is a repository provisioned through the service together
+// with the configuration it is expected to keep.
+type ManagedRepository struct {
+	ClientId string                   `json:"client_id"`
+	Owner    string                   `json:"owner"`
+	Spec     manifests.RepositorySpec `json:"spec"`
+}
+
+func (r ManagedRepository) Key() string {
+	return Key(r.Owner, r.Spec.Name)
+}
+
+// Key identifies a repository the way GitHub does, ignoring case.
+func Key(owner string, name string) string {
+	return strings.ToLower(owner + "/" + name)
+}
+
+// Report is the outcome of the last comparison of a managed repository with
+// its expected configuration. From is what GitHub reports, To what is expected.
+type Report struct {
+	ClientId   string             `json:"client_id"`
+	Owner      string             `json:"owner"`
+	Name       string             `json:"name"`
+	CheckedAt  time.Time          `json:"checked_at"`
+	Drifted    bool               `json:"drifted"`
+	Changes    []manifests.Change `json:"changes,omitempty"`
+	Remediated []string           `json:"remediated,omitempty"`
+	Error      errors.ApiError    `json:"error,omitempty"`
+}
+
+// ShouldRemediate reports whether field is one of fields or nested below one
+// of them, so "collaborators" covers every collaborator.
+func ShouldRemediate(field string, fields []string) bool {
+	for _, current := range fields {
+		if field == current || strings.HasPrefix(field, current+".") {
+			return true
+		}
+	}
+	return false
+}
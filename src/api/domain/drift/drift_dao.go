@@ -0,0 +1,178 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+var (
+	DriftDao driftDaoInterface
+)
+
+func init() {
+	dao, err := NewDriftDao(config.GetDriftStorePath())
+	if err != nil {
+		panic(err)
+	}
+	DriftDao = dao
+}
+
+type driftDaoInterface interface {
+	Manage(repository ManagedRepository)
+	Unmanage(owner string, name string)
+	GetManaged() []ManagedRepository
+	Update(owner string, name string, update func(repository *ManagedRepository)) bool
+	SaveReport(report Report)
+	GetReports() []Report
+	Restored() bool
+}
+
+// driftDao keeps the managed repositories, persisted to path when set, and
+// the latest report of each, which the next check recomputes anyway.
+type driftDao struct {
+	lock         sync.RWMutex
+	path         string
+	restored     bool
+	repositories map[string]ManagedRepository
+	reports      map[string]Report
+}
+
+func NewDriftDao(path string) (*driftDao, error) {
+	result := &driftDao{
+		path:         path,
+		repositories: make(map[string]ManagedRepository),
+		reports:      make(map[string]Report),
+	}
+	if path == "" {
+		return result, nil
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bytes, &result.repositories); err != nil {
+		return nil, fmt.Errorf("invalid managed repositories file %s: %w", path, err)
+	}
+	result.restored = true
+	return result, nil
+}
+
+// Restored reports whether the managed repositories were read from the file
+// written before a restart.
+func (d *driftDao) Restored() bool {
+	return d.restored
+}
+
+// Manage starts watching repository, replacing its expected configuration
+// when it is already watched.
+func (d *driftDao) Manage(repository ManagedRepository) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.repositories[repository.Key()] = repository
+	d.persist()
+}
+
+func (d *driftDao) Unmanage(owner string, name string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	delete(d.repositories, Key(owner, name))
+	delete(d.reports, Key(owner, name))
+	d.persist()
+}
+
+// Update applies update to the watched repository while holding the lock and
+// reports false when the repository is not watched. A repository whose owner
+// or name is changed by update is watched under its new key, its last report
+// is dropped.
+func (d *driftDao) Update(owner string, name string, update func(repository *ManagedRepository)) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	key := Key(owner, name)
+	repository, exists := d.repositories[key]
+	if !exists {
+		return false
+	}
+	update(&repository)
+	if repository.Key() != key {
+		delete(d.repositories, key)
+		delete(d.reports, key)
+	}
+	d.repositories[repository.Key()] = repository
+	d.persist()
+	return true
+}
+
+// GetManaged returns every watched repository sorted by owner and name.
+func (d *driftDao) GetManaged() []ManagedRepository {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	result := make([]ManagedRepository, 0, len(d.repositories))
+	for _, repository := range d.repositories {
+		result = append(result, repository)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key() < result[j].Key()
+	})
+	return result
+}
+
+// SaveReport keeps report as the latest one of its repository, unless the
+// repository stopped being managed meanwhile.
+func (d *driftDao) SaveReport(report Report) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	key := Key(report.Owner, report.Name)
+	if _, managed := d.repositories[key]; managed {
+		d.reports[key] = report
+	}
+}
+
+// GetReports returns the latest report of every repository sorted by owner
+// and name.
+func (d *driftDao) GetReports() []Report {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	result := make([]Report, 0, len(d.reports))
+	for _, report := range d.reports {
+		result = append(result, report)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return Key(result[i].Owner, result[i].Name) < Key(result[j].Owner, result[j].Name)
+	})
+	return result
+}
+
+// persist writes the managed repositories to a temporary file renamed over
+// the previous one, so a crash never leaves a truncated file behind. Failures
+// are logged: the change already happened on GitHub.
+func (d *driftDao) persist() {
+	if d.path == "" {
+		return
+	}
+	bytes, err := json.Marshal(d.repositories)
+	if err == nil {
+		temporary := d.path + ".tmp"
+		if err = ioutil.WriteFile(temporary, bytes, 0600); err == nil {
+			err = os.Rename(temporary, d.path)
+		}
+	}
+	if err != nil {
+		option_b.Error("error when trying to persist managed repositories", err, option_b.Field("path", d.path))
+	}
+}
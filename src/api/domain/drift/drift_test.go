@@ -0,0 +1,105 @@
+package drift
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShouldRemediate(t *testing.T) {
+	fields := []string{"description", "collaborators"}
+	assert.True(t, ShouldRemediate("description", fields))
+	assert.True(t, ShouldRemediate("collaborators.octocat", fields))
+	assert.False(t, ShouldRemediate("collaborators_count", fields))
+	assert.False(t, ShouldRemediate("topics", fields))
+	assert.False(t, ShouldRemediate("topics", nil))
+}
+
+func TestDriftDao(t *testing.T) {
+	dao := &driftDao{repositories: make(map[string]ManagedRepository), reports: make(map[string]Report)}
+	dao.Manage(ManagedRepository{ClientId: "client", Owner: "EBKopec", Spec: manifests.RepositorySpec{Name: "second"}})
+	dao.Manage(ManagedRepository{ClientId: "client", Owner: "EBKopec", Spec: manifests.RepositorySpec{Name: "first"}})
+	dao.Manage(ManagedRepository{ClientId: "other", Owner: "ebkopec", Spec: manifests.RepositorySpec{Name: "First"}})
+
+	managed := dao.GetManaged()
+	assert.EqualValues(t, 2, len(managed))
+	assert.EqualValues(t, "First", managed[0].Spec.Name)
+	assert.EqualValues(t, "other", managed[0].ClientId)
+	assert.EqualValues(t, "second", managed[1].Spec.Name)
+
+	dao.SaveReport(Report{Owner: "EBKopec", Name: "second", Drifted: true})
+	dao.SaveReport(Report{Owner: "EBKopec", Name: "unknown"})
+	reports := dao.GetReports()
+	assert.EqualValues(t, 1, len(reports))
+	assert.True(t, reports[0].Drifted)
+
+	dao.Unmanage("EBKopec", "SECOND")
+	assert.EqualValues(t, 1, len(dao.GetManaged()))
+	assert.EqualValues(t, 0, len(dao.GetReports()))
+}
+
+func TestDriftDaoUpdate(t *testing.T) {
+	dao := &driftDao{repositories: make(map[string]ManagedRepository), reports: make(map[string]Report)}
+	dao.Manage(ManagedRepository{ClientId: "client", Owner: "EBKopec", Spec: manifests.RepositorySpec{Name: "api"}})
+
+	assert.True(t, dao.Update("ebkopec", "API", func(repository *ManagedRepository) {
+		repository.Spec.Topics = []string{"go"}
+	}))
+	assert.EqualValues(t, []string{"go"}, dao.GetManaged()[0].Spec.Topics)
+	assert.False(t, dao.Update("EBKopec", "unknown", func(repository *ManagedRepository) {
+		t.Fatal("update called for an unknown repository")
+	}))
+
+	dao.SaveReport(Report{Owner: "EBKopec", Name: "api", Drifted: true})
+	assert.True(t, dao.Update("EBKopec", "api", func(repository *ManagedRepository) {
+		repository.Owner = "golang"
+		repository.Spec.Name = "service"
+	}))
+	managed := dao.GetManaged()
+	assert.EqualValues(t, 1, len(managed))
+	assert.EqualValues(t, "golang", managed[0].Owner)
+	assert.EqualValues(t, "service", managed[0].Spec.Name)
+	assert.EqualValues(t, 0, len(dao.GetReports()))
+}
+
+func TestManagedRepositoriesPersistedAcrossRestarts(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "drift")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "managed.json")
+
+	dao, err := NewDriftDao(path)
+	assert.Nil(t, err)
+	assert.False(t, dao.Restored())
+	collaborators := map[string]string{"octocat": "push"}
+	dao.Manage(ManagedRepository{ClientId: "client", Owner: "EBKopec", Spec: manifests.RepositorySpec{Name: "first", Collaborators: collaborators}})
+	dao.Manage(ManagedRepository{ClientId: "client", Owner: "EBKopec", Spec: manifests.RepositorySpec{Name: "second"}})
+	dao.Unmanage("EBKopec", "second")
+	dao.Update("EBKopec", "first", func(repository *ManagedRepository) {
+		repository.Spec.Topics = []string{"go"}
+	})
+	dao.SaveReport(Report{Owner: "EBKopec", Name: "first", Drifted: true})
+
+	restarted, err := NewDriftDao(path)
+	assert.Nil(t, err)
+	assert.True(t, restarted.Restored())
+	assert.EqualValues(t, []ManagedRepository{{
+		ClientId: "client",
+		Owner:    "EBKopec",
+		Spec:     manifests.RepositorySpec{Name: "first", Topics: []string{"go"}, Collaborators: collaborators},
+	}}, restarted.GetManaged())
+	assert.EqualValues(t, 0, len(restarted.GetReports()))
+}
+
+func TestNewDriftDaoInvalidFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "drift")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "managed.json")
+	ioutil.WriteFile(path, []byte("not json"), 0600)
+
+	dao, err := NewDriftDao(path)
+	assert.Nil(t, dao)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,32 @@
+package github
+
+import (
+	"time"
+)
+
+type CreateDeployKeyRequest struct {
+	Title    string `json:"title"`
+	Key      string `json:"key"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+type DeployKey struct {
+	Id        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Key       string    `json:"key"`
+	ReadOnly  bool      `json:"read_only"`
+	Verified  bool      `json:"verified"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActionsPublicKey is the base64 encoded curve25519 key Actions secrets of a
+// repository must be encrypted with.
+type ActionsPublicKey struct {
+	KeyId string `json:"key_id"`
+	Key   string `json:"key"`
+}
+
+type CreateOrUpdateSecretRequest struct {
+	EncryptedValue string `json:"encrypted_value"`
+	KeyId          string `json:"key_id"`
+}
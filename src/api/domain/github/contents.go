@@ -0,0 +1,25 @@
+package github
+
+// CreateFileRequest creates a file, or replaces it when Sha is the blob sha
+// of its current content.
+type CreateFileRequest struct {
+	Message string `json:"message"`
+	Content string `json:"content"`
+	Sha     string `json:"sha,omitempty"`
+	Branch  string `json:"branch,omitempty"`
+}
+
+type FileContent struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+	Sha  string `json:"sha"`
+}
+
+type Commit struct {
+	Sha string `json:"sha"`
+}
+
+type CreateFileResponse struct {
+	Content FileContent `json:"content"`
+	Commit  Commit      `json:"commit"`
+}
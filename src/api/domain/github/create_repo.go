@@ -8,6 +8,10 @@ type CreateRepoRequest struct {
 	HasIssues   bool   `json:"has_issues"`
 	HasProjects bool   `json:"has_projects"`
 	HasWiki     bool   `json:"has_wiki"`
+	AutoInit    bool   `json:"auto_init,omitempty"`
+
+	LicenseTemplate   string `json:"license_template,omitempty"`
+	GitignoreTemplate string `json:"gitignore_template,omitempty"`
 }
 
 type CreateRepoResponse struct {
@@ -0,0 +1,54 @@
+package github
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	GraphqlErrorNotFound    = "NOT_FOUND"
+	GraphqlErrorForbidden   = "FORBIDDEN"
+	GraphqlErrorRateLimited = "RATE_LIMITED"
+)
+
+type GraphqlRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphqlResponse is the envelope of every GraphQL answer: GitHub replies 200
+// even when some or all of the fields could not be resolved and lists why in
+// Errors.
+type GraphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphqlError  `json:"errors"`
+}
+
+type GraphqlError struct {
+	Type    string        `json:"type"`
+	Path    []interface{} `json:"path"`
+	Message string        `json:"message"`
+}
+
+// Field returns the top level field, or alias, the error is about.
+func (e GraphqlError) Field() string {
+	if len(e.Path) == 0 {
+		return ""
+	}
+	field, _ := e.Path[0].(string)
+	return field
+}
+
+// GraphqlRateLimit is what the rateLimit field reports: the points the query
+// cost and the ones left until ResetAt.
+type GraphqlRateLimit struct {
+	Cost      int       `json:"cost"`
+	Remaining int       `json:"remaining"`
+	NodeCount int       `json:"nodeCount"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+type RepositoryRef struct {
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+}
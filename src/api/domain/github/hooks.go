@@ -0,0 +1,26 @@
+package github
+
+const (
+	HookNameWeb = "web"
+)
+
+type CreateHookRequest struct {
+	Name   string     `json:"name"`
+	Active bool       `json:"active"`
+	Events []string   `json:"events"`
+	Config HookConfig `json:"config"`
+}
+
+type HookConfig struct {
+	Url         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Secret      string `json:"secret,omitempty"`
+}
+
+type Hook struct {
+	Id     int64      `json:"id"`
+	Name   string     `json:"name"`
+	Active bool       `json:"active"`
+	Events []string   `json:"events"`
+	Config HookConfig `json:"config"`
+}
@@ -0,0 +1,8 @@
+package github
+
+type Label struct {
+	Id          int64  `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
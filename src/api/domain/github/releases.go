@@ -0,0 +1,79 @@
+package github
+
+import (
+	"time"
+)
+
+const (
+	GitObjectCommit = "commit"
+)
+
+type CreateReleaseRequest struct {
+	TagName              string `json:"tag_name"`
+	TargetCommitish      string `json:"target_commitish,omitempty"`
+	Name                 string `json:"name,omitempty"`
+	Body                 string `json:"body,omitempty"`
+	Draft                bool   `json:"draft"`
+	Prerelease           bool   `json:"prerelease"`
+	GenerateReleaseNotes bool   `json:"generate_release_notes"`
+}
+
+type Release struct {
+	Id              int64          `json:"id"`
+	TagName         string         `json:"tag_name"`
+	TargetCommitish string         `json:"target_commitish"`
+	Name            string         `json:"name"`
+	Body            string         `json:"body"`
+	Draft           bool           `json:"draft"`
+	Prerelease      bool           `json:"prerelease"`
+	HtmlUrl         string         `json:"html_url"`
+	CreatedAt       time.Time      `json:"created_at"`
+	PublishedAt     *time.Time     `json:"published_at"`
+	Assets          []ReleaseAsset `json:"assets"`
+}
+
+type ReleaseAsset struct {
+	Id                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Label              string `json:"label"`
+	ContentType        string `json:"content_type"`
+	Size               int64  `json:"size"`
+	State              string `json:"state"`
+	BrowserDownloadUrl string `json:"browser_download_url"`
+}
+
+type Tag struct {
+	Name   string `json:"name"`
+	Commit Commit `json:"commit"`
+}
+
+// CreateTagObjectRequest creates an annotated tag object, which only becomes
+// visible once a reference points to it.
+type CreateTagObjectRequest struct {
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+	Object  string `json:"object"`
+	Type    string `json:"type"`
+}
+
+type GitObject struct {
+	Sha  string `json:"sha"`
+	Type string `json:"type"`
+}
+
+type TagObject struct {
+	Sha     string    `json:"sha"`
+	Tag     string    `json:"tag"`
+	Message string    `json:"message"`
+	Object  GitObject `json:"object"`
+}
+
+type CreateRefRequest struct {
+	Ref string `json:"ref"`
+	Sha string `json:"sha"`
+}
+
+type Reference struct {
+	Ref    string    `json:"ref"`
+	Object GitObject `json:"object"`
+}
@@ -0,0 +1,23 @@
+package github
+
+type Repository struct {
+	Id            int64           `json:"id"`
+	Name          string          `json:"name"`
+	FullName      string          `json:"full_name"`
+	Description   string          `json:"description"`
+	Homepage      string          `json:"homepage"`
+	Private       bool            `json:"private"`
+	Archived      bool            `json:"archived"`
+	HasIssues     bool            `json:"has_issues"`
+	HasProjects   bool            `json:"has_projects"`
+	HasWiki       bool            `json:"has_wiki"`
+	DefaultBranch string          `json:"default_branch"`
+	Topics        []string        `json:"topics"`
+	Owner         RepoOwner       `json:"owner"`
+	Permissions   RepoPermissions `json:"permissions"`
+}
+
+type User struct {
+	Id    int64  `json:"id"`
+	Login string `json:"login"`
+}
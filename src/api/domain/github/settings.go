@@ -0,0 +1,88 @@
+package github
+
+// UpdateRepoRequest only sends the settings that are set.
+type UpdateRepoRequest struct {
+	Description *string `json:"description,omitempty"`
+	Homepage    *string `json:"homepage,omitempty"`
+	Private     *bool   `json:"private,omitempty"`
+	HasIssues   *bool   `json:"has_issues,omitempty"`
+	HasProjects *bool   `json:"has_projects,omitempty"`
+	HasWiki     *bool   `json:"has_wiki,omitempty"`
+	Name        *string `json:"name,omitempty"`
+	Archived    *bool   `json:"archived,omitempty"`
+}
+
+type TransferRepoRequest struct {
+	NewOwner string  `json:"new_owner"`
+	NewName  string  `json:"new_name,omitempty"`
+	TeamIds  []int64 `json:"team_ids,omitempty"`
+}
+
+type Topics struct {
+	Names []string `json:"names"`
+}
+
+type Collaborator struct {
+	Id          int64                   `json:"id"`
+	Login       string                  `json:"login"`
+	Permissions CollaboratorPermissions `json:"permissions"`
+}
+
+type CollaboratorPermissions struct {
+	Admin    bool `json:"admin"`
+	Maintain bool `json:"maintain"`
+	Push     bool `json:"push"`
+	Triage   bool `json:"triage"`
+	Pull     bool `json:"pull"`
+}
+
+// Permission returns the highest permission granted, named the way
+// AddCollaboratorRequest expects it.
+func (p CollaboratorPermissions) Permission() string {
+	switch {
+	case p.Admin:
+		return "admin"
+	case p.Maintain:
+		return "maintain"
+	case p.Push:
+		return "push"
+	case p.Triage:
+		return "triage"
+	case p.Pull:
+		return "pull"
+	}
+	return ""
+}
+
+type AddCollaboratorRequest struct {
+	Permission string `json:"permission"`
+}
+
+// BranchProtection is the protection of a branch as GitHub reports it.
+type BranchProtection struct {
+	RequiredStatusChecks       *RequiredStatusChecks `json:"required_status_checks"`
+	EnforceAdmins              *EnabledSetting       `json:"enforce_admins"`
+	RequiredPullRequestReviews *RequiredReviews      `json:"required_pull_request_reviews"`
+}
+
+type RequiredStatusChecks struct {
+	Strict   bool     `json:"strict"`
+	Contexts []string `json:"contexts"`
+}
+
+type EnabledSetting struct {
+	Enabled bool `json:"enabled"`
+}
+
+type RequiredReviews struct {
+	RequiredApprovingReviewCount int `json:"required_approving_review_count"`
+}
+
+// UpdateBranchProtectionRequest replaces the protection of a branch. GitHub
+// requires every member to be present, null disabling the setting.
+type UpdateBranchProtectionRequest struct {
+	RequiredStatusChecks       *RequiredStatusChecks `json:"required_status_checks"`
+	EnforceAdmins              bool                  `json:"enforce_admins"`
+	RequiredPullRequestReviews *RequiredReviews      `json:"required_pull_request_reviews"`
+	Restrictions               interface{}           `json:"restrictions"`
+}
@@ -0,0 +1,166 @@
+package jobs
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"time"
+)
+
+const (
+	StatePending   = "pending"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	// StatePendingApproval items wait for an admin, see the approval_id of
+	// their repo.
+	StatePendingApproval = "pending_approval"
+	StateFailed          = "failed"
+	// StateSkipped items were left out as duplicates of an earlier item.
+	StateSkipped   = "skipped"
+	StateCancelled = "cancelled"
+	StateCompleted = "completed"
+)
+
+type Job struct {
+	Id         string                          `json:"id"`
+	ClientId   string                          `json:"client_id"`
+	State      string                          `json:"state"`
+	Options    repositories.CreateReposOptions `json:"options"`
+	Counts     Counts                          `json:"counts"`
+	Items      []Item                          `json:"items"`
+	CreatedAt  time.Time                       `json:"created_at"`
+	StartedAt  *time.Time                      `json:"started_at,omitempty"`
+	FinishedAt *time.Time                      `json:"finished_at,omitempty"`
+}
+
+type Counts struct {
+	Pending         int `json:"pending"`
+	Running         int `json:"running"`
+	Succeeded       int `json:"succeeded"`
+	PendingApproval int `json:"pending_approval"`
+	Failed          int `json:"failed"`
+	Skipped         int `json:"skipped"`
+	Cancelled       int `json:"cancelled"`
+}
+
+type Item struct {
+	Index       int                              `json:"index"`
+	Name        string                           `json:"name"`
+	State       string                           `json:"state"`
+	Repo        *repositories.CreateRepoResponse `json:"repo,omitempty"`
+	Error       errors.ApiError                  `json:"error,omitempty"`
+	Skipped     string                           `json:"skipped,omitempty"`
+	DuplicateOf *int                             `json:"duplicate_of,omitempty"`
+	StartedAt   *time.Time                       `json:"started_at,omitempty"`
+	FinishedAt  *time.Time                       `json:"finished_at,omitempty"`
+}
+
+func NewJob(id string, clientId string, requests []repositories.CreateRepoRequest) *Job {
+	job := Job{
+		Id:        id,
+		ClientId:  clientId,
+		State:     StatePending,
+		CreatedAt: time.Now().UTC(),
+		Items:     make([]Item, len(requests)),
+	}
+	for index, request := range requests {
+		job.Items[index] = Item{
+			Index: index,
+			Name:  request.Name,
+			State: StatePending,
+		}
+	}
+	job.updateCounts()
+	return &job
+}
+
+// IsFinished reports whether every item of the job reached a final state.
+func (j *Job) IsFinished() bool {
+	return j.State == StateCompleted || j.State == StateCancelled
+}
+
+func (j *Job) Start() {
+	now := time.Now().UTC()
+	j.State = StateRunning
+	j.StartedAt = &now
+}
+
+func (j *Job) StartItem(index int) {
+	now := time.Now().UTC()
+	j.Items[index].State = StateRunning
+	j.Items[index].StartedAt = &now
+	j.updateCounts()
+}
+
+// FinishItem records the outcome of one repository. Items that were never
+// started because the job was cancelled end up as cancelled. Jobs can be read
+// any number of times, so the private keys of generated deploy keys are not
+// kept.
+func (j *Job) FinishItem(result repositories.CreateRepositoriesResult, cancelled bool) {
+	now := time.Now().UTC()
+	item := &j.Items[result.Index]
+	if result.Response != nil {
+		withoutKeys := result.Response.WithoutPrivateKeys()
+		item.Repo = &withoutKeys
+	}
+	item.Error = result.Error
+	item.Skipped = result.Skipped
+	item.DuplicateOf = result.DuplicateOf
+	item.FinishedAt = &now
+
+	switch {
+	case result.Response != nil && result.Response.ApprovalId != "":
+		item.State = StatePendingApproval
+	case result.Response != nil:
+		item.State = StateSucceeded
+	case cancelled:
+		item.State = StateCancelled
+	case result.Error == nil && result.Skipped != "":
+		item.State = StateSkipped
+	default:
+		item.State = StateFailed
+	}
+	j.updateCounts()
+}
+
+// Finish closes the job once every item has an outcome. A job with items that
+// were never started is reported as cancelled.
+func (j *Job) Finish() {
+	now := time.Now().UTC()
+	j.State = StateCompleted
+	if j.Counts.Cancelled > 0 {
+		j.State = StateCancelled
+	}
+	j.FinishedAt = &now
+}
+
+// Copy returns a deep enough copy of the job to be handed out while the job
+// keeps being processed.
+func (j *Job) Copy() *Job {
+	result := *j
+	result.Items = make([]Item, len(j.Items))
+	copy(result.Items, j.Items)
+	return &result
+}
+
+func (j *Job) updateCounts() {
+	var counts Counts
+	for _, item := range j.Items {
+		switch item.State {
+		case StatePending:
+			counts.Pending++
+		case StateRunning:
+			counts.Running++
+		case StateSucceeded:
+			counts.Succeeded++
+		case StatePendingApproval:
+			counts.PendingApproval++
+		case StateFailed:
+			counts.Failed++
+		case StateSkipped:
+			counts.Skipped++
+		case StateCancelled:
+			counts.Cancelled++
+		}
+	}
+	j.Counts = counts
+}
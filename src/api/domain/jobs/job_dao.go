@@ -0,0 +1,79 @@
+package jobs
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"sync"
+	"time"
+)
+
+var (
+	JobDao jobDaoInterface
+)
+
+func init() {
+	JobDao = &jobDao{
+		jobs:      make(map[string]*Job),
+		retention: config.GetJobRetention(),
+		now:       time.Now,
+	}
+}
+
+type jobDaoInterface interface {
+	Save(job *Job) errors.ApiError
+	Get(id string) (*Job, errors.ApiError)
+	Update(id string, update func(job *Job)) (*Job, errors.ApiError)
+}
+
+// jobDao keeps jobs in memory. Finished jobs are dropped once they have been
+// finished for longer than retention.
+type jobDao struct {
+	lock      sync.RWMutex
+	jobs      map[string]*Job
+	retention time.Duration
+	now       func() time.Time
+}
+
+func (d *jobDao) Save(job *Job) errors.ApiError {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.purgeExpired()
+	d.jobs[job.Id] = job.Copy()
+	return nil
+}
+
+func (d *jobDao) purgeExpired() {
+	expired := d.now().Add(-d.retention)
+	for id, job := range d.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(expired) {
+			delete(d.jobs, id)
+		}
+	}
+}
+
+func (d *jobDao) Get(id string) (*Job, errors.ApiError) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+
+	job := d.jobs[id]
+	if job == nil || (job.FinishedAt != nil && job.FinishedAt.Before(d.now().Add(-d.retention))) {
+		return nil, errors.NewNotFoundApiError(fmt.Sprintf("job %s not found", id))
+	}
+	return job.Copy(), nil
+}
+
+// Update applies update to the stored job while holding the lock and returns
+// a copy of the result.
+func (d *jobDao) Update(id string, update func(job *Job)) (*Job, errors.ApiError) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	job := d.jobs[id]
+	if job == nil {
+		return nil, errors.NewNotFoundApiError(fmt.Sprintf("job %s not found", id))
+	}
+	update(job)
+	return job.Copy(), nil
+}
@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewJob(t *testing.T) {
+	job := NewJob("abc", "client", []repositories.CreateRepoRequest{{Name: "a"}, {Name: "b"}})
+
+	assert.EqualValues(t, "abc", job.Id)
+	assert.EqualValues(t, "client", job.ClientId)
+	assert.EqualValues(t, StatePending, job.State)
+	assert.EqualValues(t, 2, job.Counts.Pending)
+	assert.EqualValues(t, 2, len(job.Items))
+	assert.EqualValues(t, 1, job.Items[1].Index)
+	assert.EqualValues(t, "b", job.Items[1].Name)
+	assert.Nil(t, job.StartedAt)
+	assert.False(t, job.IsFinished())
+}
+
+func TestJobLifecycle(t *testing.T) {
+	job := NewJob("abc", "client", []repositories.CreateRepoRequest{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+	job.Start()
+	assert.EqualValues(t, StateRunning, job.State)
+	assert.NotNil(t, job.StartedAt)
+
+	job.StartItem(0)
+	job.StartItem(1)
+	assert.EqualValues(t, Counts{Pending: 1, Running: 2}, job.Counts)
+
+	job.FinishItem(repositories.CreateRepositoriesResult{Index: 0, Response: &repositories.CreateRepoResponse{Id: 1}}, false)
+	job.FinishItem(repositories.CreateRepositoriesResult{Index: 1, Error: errors.NewBadRequestError("invalid repository name")}, false)
+	job.FinishItem(repositories.CreateRepositoriesResult{Index: 2, Error: errors.NewBadRequestError("cancelled")}, true)
+	assert.EqualValues(t, Counts{Succeeded: 1, Failed: 1, Cancelled: 1}, job.Counts)
+	assert.EqualValues(t, StateSucceeded, job.Items[0].State)
+	assert.EqualValues(t, StateFailed, job.Items[1].State)
+	assert.EqualValues(t, StateCancelled, job.Items[2].State)
+	assert.NotNil(t, job.Items[2].FinishedAt)
+
+	job.Finish()
+	assert.EqualValues(t, StateCancelled, job.State)
+	assert.True(t, job.IsFinished())
+	assert.NotNil(t, job.FinishedAt)
+}
+
+func TestJobDoesNotKeepPrivateKeys(t *testing.T) {
+	job := NewJob("job", "client", []repositories.CreateRepoRequest{{Name: "api"}})
+	job.Start()
+	job.StartItem(0)
+
+	repo := &repositories.CreateRepoResponse{Name: "api", DeployKeys: []repositories.DeployKey{{Title: "ci", PrivateKey: "secret"}}}
+	job.FinishItem(repositories.CreateRepositoriesResult{Index: 0, Response: repo}, false)
+	assert.EqualValues(t, "", job.Items[0].Repo.DeployKeys[0].PrivateKey)
+	assert.EqualValues(t, "secret", repo.DeployKeys[0].PrivateKey)
+}
+
+func TestJobDao(t *testing.T) {
+	_, err := JobDao.Get("missing")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "job missing not found", err.Message())
+
+	job := NewJob("dao", "client", []repositories.CreateRepoRequest{{Name: "a"}})
+	assert.Nil(t, JobDao.Save(job))
+
+	updated, err := JobDao.Update("dao", func(job *Job) { job.StartItem(0) })
+	assert.Nil(t, err)
+	assert.EqualValues(t, StateRunning, updated.Items[0].State)
+
+	// handed out copies are not affected by later updates
+	JobDao.Update("dao", func(job *Job) { job.Items[0].Name = "changed" })
+	assert.EqualValues(t, "a", updated.Items[0].Name)
+
+	stored, err := JobDao.Get("dao")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "changed", stored.Items[0].Name)
+}
+
+func TestJobDaoForgetsFinishedJobs(t *testing.T) {
+	now := time.Now()
+	dao := &jobDao{jobs: make(map[string]*Job), retention: time.Hour, now: func() time.Time { return now }}
+
+	finished := NewJob("finished", "client", []repositories.CreateRepoRequest{{Name: "a"}})
+	finished.Finish()
+	running := NewJob("running", "client", []repositories.CreateRepoRequest{{Name: "b"}})
+	running.Start()
+	dao.Save(finished)
+	dao.Save(running)
+
+	_, err := dao.Get("finished")
+	assert.Nil(t, err)
+
+	now = now.Add(2 * time.Hour)
+	_, err = dao.Get("finished")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "job finished not found", err.Message())
+	_, err = dao.Get("running")
+	assert.Nil(t, err)
+
+	dao.Save(NewJob("other", "client", nil))
+	assert.EqualValues(t, 2, len(dao.jobs))
+}
+
+func TestJobItemPendingApproval(t *testing.T) {
+	job := NewJob("abc", "client", []repositories.CreateRepoRequest{{Name: "prod-api"}})
+	job.Start()
+	job.StartItem(0)
+
+	job.FinishItem(repositories.CreateRepositoriesResult{Index: 0, Response: &repositories.CreateRepoResponse{Name: "prod-api", ApprovalId: "approval"}}, false)
+	assert.EqualValues(t, StatePendingApproval, job.Items[0].State)
+	assert.EqualValues(t, Counts{PendingApproval: 1}, job.Counts)
+
+	job.Finish()
+	assert.EqualValues(t, StateCompleted, job.State)
+}
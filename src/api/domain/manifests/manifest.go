@@ -0,0 +1,140 @@
+package manifests
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"strings"
+)
+
+var (
+	validPermissions = []string{"pull", "triage", "push", "maintain", "admin"}
+)
+
+// Manifest describes the desired state of a set of repositories owned by the
+// authenticated user.
+type Manifest struct {
+	Repositories []RepositorySpec `json:"repositories" yaml:"repositories"`
+}
+
+// RepositorySpec is the desired state of one repository. Settings left unset
+// are not managed: they are neither compared nor changed. Collaborators not
+// listed are left alone.
+type RepositorySpec struct {
+	Name             string                 `json:"name" yaml:"name"`
+	Description      *string                `json:"description,omitempty" yaml:"description"`
+	Homepage         *string                `json:"homepage,omitempty" yaml:"homepage"`
+	Private          *bool                  `json:"private,omitempty" yaml:"private"`
+	HasIssues        *bool                  `json:"has_issues,omitempty" yaml:"has_issues"`
+	HasProjects      *bool                  `json:"has_projects,omitempty" yaml:"has_projects"`
+	HasWiki          *bool                  `json:"has_wiki,omitempty" yaml:"has_wiki"`
+	Topics           []string               `json:"topics,omitempty" yaml:"topics"`
+	Collaborators    map[string]string      `json:"collaborators,omitempty" yaml:"collaborators"`
+	BranchProtection []BranchProtectionSpec `json:"branch_protection,omitempty" yaml:"branch_protection"`
+}
+
+type BranchProtectionSpec struct {
+	Branch                   string   `json:"branch" yaml:"branch"`
+	RequiredApprovingReviews int      `json:"required_approving_reviews" yaml:"required_approving_reviews"`
+	EnforceAdmins            bool     `json:"enforce_admins" yaml:"enforce_admins"`
+	RequiredStatusChecks     []string `json:"required_status_checks,omitempty" yaml:"required_status_checks"`
+}
+
+// CreateRepoRequest returns the request validated for a new repository.
+func (s RepositorySpec) CreateRepoRequest() repositories.CreateRepoRequest {
+	result := repositories.CreateRepoRequest{Name: s.Name}
+	if s.Description != nil {
+		result.Description = *s.Description
+	}
+	if s.Homepage != nil {
+		result.Homepage = *s.Homepage
+	}
+	return result
+}
+
+// SetBranchProtection replaces the protection of the branch, adding it when
+// the branch is not protected yet.
+func (s *RepositorySpec) SetBranchProtection(protection BranchProtectionSpec) {
+	for index := range s.BranchProtection {
+		if s.BranchProtection[index].Branch == protection.Branch {
+			s.BranchProtection[index] = protection
+			return
+		}
+	}
+	s.BranchProtection = append(s.BranchProtection, protection)
+}
+
+// Validate checks every repository of the manifest and reports all the
+// violations at once, each field prefixed with the path of its repository.
+func (m *Manifest) Validate() errors.ApiError {
+	if len(m.Repositories) == 0 {
+		return errors.NewBadRequestError("manifest has no repositories")
+	}
+	if maxSize := config.GetRepoBatchMaxSize(); len(m.Repositories) > maxSize {
+		return errors.NewBadRequestError(fmt.Sprintf("a manifest can describe at most %d repositories", maxSize))
+	}
+
+	requests := make([]repositories.CreateRepoRequest, len(m.Repositories))
+	causes := make([]interface{}, 0)
+	for index := range m.Repositories {
+		spec := &m.Repositories[index]
+		spec.Name = strings.TrimSpace(spec.Name)
+		requests[index] = spec.CreateRepoRequest()
+
+		path := fmt.Sprintf("repositories[%d]", index)
+		for _, current := range spec.validate(requests[index]) {
+			current.Field = fmt.Sprintf("%s.%s", path, current.Field)
+			causes = append(causes, current)
+		}
+	}
+	for index, first := range repositories.FindDuplicates(requests) {
+		causes = append(causes, repositories.FieldError{
+			Field:   fmt.Sprintf("repositories[%d].name", index),
+			Message: fmt.Sprintf("repository already described by repositories[%d]", first),
+		})
+	}
+
+	if len(causes) == 0 {
+		return nil
+	}
+	return errors.NewValidationError("invalid manifest", causes...)
+}
+
+func (s *RepositorySpec) validate(request repositories.CreateRepoRequest) []repositories.FieldError {
+	result := make([]repositories.FieldError, 0)
+	if err := request.Validate(); err != nil {
+		for _, cause := range err.Causes() {
+			if current, ok := cause.(repositories.FieldError); ok {
+				result = append(result, current)
+			}
+		}
+	}
+
+	for login, permission := range s.Collaborators {
+		if strings.TrimSpace(login) == "" {
+			result = append(result, repositories.FieldError{Field: "collaborators", Message: "collaborator login is required"})
+		}
+		if !isValidPermission(permission) {
+			result = append(result, repositories.FieldError{
+				Field:   fmt.Sprintf("collaborators.%s", login),
+				Message: fmt.Sprintf("permission must be one of %s", strings.Join(validPermissions, ", ")),
+			})
+		}
+	}
+
+	protections := make([]repositories.BranchProtection, len(s.BranchProtection))
+	for index, protection := range s.BranchProtection {
+		protections[index] = repositories.BranchProtection(protection)
+	}
+	return append(result, repositories.ValidateBranchProtection(protections, "branch_protection")...)
+}
+
+func isValidPermission(permission string) bool {
+	for _, current := range validPermissions {
+		if permission == current {
+			return true
+		}
+	}
+	return false
+}
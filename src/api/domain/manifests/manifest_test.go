@@ -0,0 +1,74 @@
+package manifests
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestManifestFromYaml(t *testing.T) {
+	body := `
+repositories:
+  - name: testing
+    description: a test
+    private: true
+    topics: [go, api]
+    collaborators:
+      octocat: push
+    branch_protection:
+      - branch: main
+        required_approving_reviews: 1
+`
+	var manifest Manifest
+	err := yaml.UnmarshalStrict([]byte(body), &manifest)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(manifest.Repositories))
+	spec := manifest.Repositories[0]
+	assert.EqualValues(t, "a test", *spec.Description)
+	assert.Nil(t, spec.Homepage)
+	assert.True(t, *spec.Private)
+	assert.EqualValues(t, []string{"go", "api"}, spec.Topics)
+	assert.EqualValues(t, "push", spec.Collaborators["octocat"])
+	assert.EqualValues(t, 1, spec.BranchProtection[0].RequiredApprovingReviews)
+	assert.Nil(t, manifest.Validate())
+}
+
+func TestManifestValidateEmpty(t *testing.T) {
+	err := (&Manifest{}).Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "manifest has no repositories", err.Message())
+}
+
+func TestManifestValidateTooLarge(t *testing.T) {
+	os.Setenv("REPO_BATCH_MAX_SIZE", "1")
+	defer os.Unsetenv("REPO_BATCH_MAX_SIZE")
+
+	err := (&Manifest{Repositories: []RepositorySpec{{Name: "first"}, {Name: "second"}}}).Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "a manifest can describe at most 1 repositories", err.Message())
+}
+
+func TestManifestValidateReportsEveryViolation(t *testing.T) {
+	manifest := Manifest{Repositories: []RepositorySpec{
+		{Name: " testing "},
+		{Name: "..", Collaborators: map[string]string{"octocat": "write"}},
+		{Name: "Testing", BranchProtection: []BranchProtectionSpec{{Branch: "main", RequiredApprovingReviews: 7}, {Branch: "main"}, {}}},
+	}}
+
+	err := manifest.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid manifest", err.Message())
+	assert.EqualValues(t, "testing", manifest.Repositories[0].Name)
+	assert.EqualValues(t, []interface{}{
+		repositories.FieldError{Field: "repositories[1].name", Message: "name '..' is reserved"},
+		repositories.FieldError{Field: "repositories[1].collaborators.octocat", Message: "permission must be one of pull, triage, push, maintain, admin"},
+		repositories.FieldError{Field: "repositories[2].branch_protection[0].required_approving_reviews", Message: "required approving reviews must be between 0 and 6"},
+		repositories.FieldError{Field: "repositories[2].branch_protection[1].branch", Message: "branch 'main' is protected more than once"},
+		repositories.FieldError{Field: "repositories[2].branch_protection[2].branch", Message: "branch is required"},
+		repositories.FieldError{Field: "repositories[2].name", Message: "repository already described by repositories[0]"},
+	}, err.Causes())
+}
@@ -0,0 +1,200 @@
+package manifests
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionNoOp   = "no-op"
+
+	FieldTopics                 = "topics"
+	FieldCollaboratorPrefix     = "collaborators."
+	FieldBranchProtectionPrefix = "branch_protection."
+)
+
+// RepositoryState is what the provider reports for an existing repository.
+// Protections only holds the branches the manifest asks about.
+type RepositoryState struct {
+	Repository    github.Repository
+	Collaborators map[string]string
+	Protections   map[string]*BranchProtectionSpec
+}
+
+// Change is a single difference between the desired and the current state.
+// From is omitted for repositories about to be created.
+type Change struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to"`
+}
+
+type RepositoryPlan struct {
+	Owner   string          `json:"owner"`
+	Name    string          `json:"name"`
+	Action  string          `json:"action,omitempty"`
+	Changes []Change        `json:"changes,omitempty"`
+	Error   errors.ApiError `json:"error,omitempty"`
+}
+
+type Plan struct {
+	Create       int              `json:"create"`
+	Update       int              `json:"update"`
+	NoOp         int              `json:"no_op"`
+	Failed       int              `json:"failed"`
+	Repositories []RepositoryPlan `json:"repositories"`
+}
+
+// RepositoryResult is the outcome of applying the plan of one repository.
+// ApprovalId is set when its creation waits for an admin, in which case
+// nothing else is applied yet.
+type RepositoryResult struct {
+	RepositoryPlan
+	Applied    bool   `json:"applied"`
+	ApprovalId string `json:"approval_id,omitempty"`
+}
+
+type ApplyResponse struct {
+	StatusCode   int                `json:"status"`
+	Repositories []RepositoryResult `json:"repositories"`
+}
+
+// Add appends a repository plan and keeps the counters in sync.
+func (p *Plan) Add(current RepositoryPlan) {
+	switch {
+	case current.Error != nil:
+		p.Failed++
+	case current.Action == ActionCreate:
+		p.Create++
+	case current.Action == ActionUpdate:
+		p.Update++
+	default:
+		p.NoOp++
+	}
+	p.Repositories = append(p.Repositories, current)
+}
+
+// Diff compares spec with the current state of the repository, nil when it
+// does not exist yet, and returns the plan to converge.
+func Diff(owner string, spec RepositorySpec, current *RepositoryState) RepositoryPlan {
+	result := RepositoryPlan{Owner: owner, Name: spec.Name, Action: ActionNoOp}
+	if current == nil {
+		current = &RepositoryState{}
+		result.Action = ActionCreate
+	}
+	repo := current.Repository
+	exists := result.Action != ActionCreate
+
+	add := func(field string, from interface{}, to interface{}) {
+		if exists && reflect.DeepEqual(from, to) {
+			return
+		}
+		if !exists {
+			from = nil
+		}
+		result.Changes = append(result.Changes, Change{Field: field, From: from, To: to})
+	}
+
+	if spec.Description != nil {
+		add("description", repo.Description, *spec.Description)
+	}
+	if spec.Homepage != nil {
+		add("homepage", repo.Homepage, *spec.Homepage)
+	}
+	if spec.Private != nil {
+		add("private", repo.Private, *spec.Private)
+	}
+	if spec.HasIssues != nil {
+		add("has_issues", repo.HasIssues, *spec.HasIssues)
+	}
+	if spec.HasProjects != nil {
+		add("has_projects", repo.HasProjects, *spec.HasProjects)
+	}
+	if spec.HasWiki != nil {
+		add("has_wiki", repo.HasWiki, *spec.HasWiki)
+	}
+	if spec.Topics != nil {
+		add(FieldTopics, NormalizeTopics(repo.Topics), NormalizeTopics(spec.Topics))
+	}
+
+	logins := make([]string, 0, len(spec.Collaborators))
+	for login := range spec.Collaborators {
+		logins = append(logins, login)
+	}
+	sort.Strings(logins)
+	for _, login := range logins {
+		add(FieldCollaboratorPrefix+login, current.Collaborators[strings.ToLower(login)], spec.Collaborators[login])
+	}
+
+	for _, protection := range spec.BranchProtection {
+		desired := protection
+		desired.RequiredStatusChecks = normalizeChecks(desired.RequiredStatusChecks)
+		var from interface{}
+		if existing := current.Protections[protection.Branch]; existing != nil {
+			from = existing
+		}
+		add(FieldBranchProtectionPrefix+protection.Branch, from, &desired)
+	}
+
+	if exists && len(result.Changes) > 0 {
+		result.Action = ActionUpdate
+	}
+	return result
+}
+
+// NormalizeTopics returns the topics the way GitHub stores them: lower case,
+// sorted and without duplicates.
+func NormalizeTopics(topics []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		topic = strings.ToLower(strings.TrimSpace(topic))
+		if topic == "" || seen[topic] {
+			continue
+		}
+		seen[topic] = true
+		result = append(result, topic)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func normalizeChecks(checks []string) []string {
+	result := append(make([]string, 0, len(checks)), checks...)
+	sort.Strings(result)
+	return result
+}
+
+// NewBranchProtectionSpec converts what GitHub reports into the manifest form
+// so both can be compared.
+func NewBranchProtectionSpec(branch string, protection github.BranchProtection) *BranchProtectionSpec {
+	result := &BranchProtectionSpec{Branch: branch, RequiredStatusChecks: []string{}}
+	if protection.EnforceAdmins != nil {
+		result.EnforceAdmins = protection.EnforceAdmins.Enabled
+	}
+	if protection.RequiredPullRequestReviews != nil {
+		result.RequiredApprovingReviews = protection.RequiredPullRequestReviews.RequiredApprovingReviewCount
+	}
+	if protection.RequiredStatusChecks != nil {
+		result.RequiredStatusChecks = normalizeChecks(protection.RequiredStatusChecks.Contexts)
+	}
+	return result
+}
+
+// UpdateBranchProtectionRequest returns the request that protects the branch
+// as described.
+func (s BranchProtectionSpec) UpdateBranchProtectionRequest() github.UpdateBranchProtectionRequest {
+	result := github.UpdateBranchProtectionRequest{EnforceAdmins: s.EnforceAdmins}
+	if len(s.RequiredStatusChecks) > 0 {
+		result.RequiredStatusChecks = &github.RequiredStatusChecks{Strict: true, Contexts: s.RequiredStatusChecks}
+	}
+	if s.RequiredApprovingReviews > 0 {
+		result.RequiredPullRequestReviews = &github.RequiredReviews{RequiredApprovingReviewCount: s.RequiredApprovingReviews}
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package manifests
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiffCreate(t *testing.T) {
+	private := true
+	spec := RepositorySpec{
+		Name:             "testing",
+		Private:          &private,
+		Topics:           []string{"Go", "api", "go"},
+		BranchProtection: []BranchProtectionSpec{{Branch: "main", EnforceAdmins: true}},
+	}
+
+	result := Diff("EBKopec", spec, nil)
+	assert.EqualValues(t, ActionCreate, result.Action)
+	assert.EqualValues(t, "EBKopec", result.Owner)
+	assert.EqualValues(t, []Change{
+		{Field: "private", To: true},
+		{Field: "topics", To: []string{"api", "go"}},
+		{Field: "branch_protection.main", To: &BranchProtectionSpec{Branch: "main", EnforceAdmins: true, RequiredStatusChecks: []string{}}},
+	}, result.Changes)
+}
+
+func TestDiffNoOp(t *testing.T) {
+	description := "a test"
+	spec := RepositorySpec{
+		Name:             "testing",
+		Description:      &description,
+		Topics:           []string{"go"},
+		Collaborators:    map[string]string{"OctoCat": "push"},
+		BranchProtection: []BranchProtectionSpec{{Branch: "main", RequiredApprovingReviews: 1, RequiredStatusChecks: []string{"test", "build"}}},
+	}
+	state := RepositoryState{
+		Repository:    github.Repository{Name: "testing", Description: "a test", Topics: []string{"go"}, HasWiki: true},
+		Collaborators: map[string]string{"octocat": "push"},
+		Protections: map[string]*BranchProtectionSpec{
+			"main": NewBranchProtectionSpec("main", github.BranchProtection{
+				RequiredStatusChecks:       &github.RequiredStatusChecks{Contexts: []string{"build", "test"}},
+				RequiredPullRequestReviews: &github.RequiredReviews{RequiredApprovingReviewCount: 1},
+			}),
+		},
+	}
+
+	result := Diff("EBKopec", spec, &state)
+	assert.EqualValues(t, ActionNoOp, result.Action)
+	assert.EqualValues(t, 0, len(result.Changes))
+}
+
+func TestDiffUpdate(t *testing.T) {
+	hasWiki := false
+	spec := RepositorySpec{
+		Name:             "testing",
+		HasWiki:          &hasWiki,
+		Topics:           []string{},
+		Collaborators:    map[string]string{"octocat": "admin", "hubot": "pull"},
+		BranchProtection: []BranchProtectionSpec{{Branch: "main"}},
+	}
+	state := RepositoryState{
+		Repository:    github.Repository{Name: "testing", Topics: []string{"go"}, HasWiki: true},
+		Collaborators: map[string]string{"octocat": "push"},
+		Protections:   map[string]*BranchProtectionSpec{},
+	}
+
+	result := Diff("EBKopec", spec, &state)
+	assert.EqualValues(t, ActionUpdate, result.Action)
+	assert.EqualValues(t, []Change{
+		{Field: "has_wiki", From: true, To: false},
+		{Field: "topics", From: []string{"go"}, To: []string{}},
+		{Field: "collaborators.hubot", From: "", To: "pull"},
+		{Field: "collaborators.octocat", From: "push", To: "admin"},
+		{Field: "branch_protection.main", To: &BranchProtectionSpec{Branch: "main", RequiredStatusChecks: []string{}}},
+	}, result.Changes)
+}
+
+func TestPlanAdd(t *testing.T) {
+	var plan Plan
+	plan.Add(RepositoryPlan{Action: ActionCreate})
+	plan.Add(RepositoryPlan{Action: ActionUpdate})
+	plan.Add(RepositoryPlan{Action: ActionNoOp})
+	plan.Add(RepositoryPlan{Action: ActionUpdate})
+	assert.EqualValues(t, 1, plan.Create)
+	assert.EqualValues(t, 2, plan.Update)
+	assert.EqualValues(t, 1, plan.NoOp)
+	assert.EqualValues(t, 4, len(plan.Repositories))
+}
+
+func TestUpdateBranchProtectionRequest(t *testing.T) {
+	request := BranchProtectionSpec{Branch: "main"}.UpdateBranchProtectionRequest()
+	assert.Nil(t, request.RequiredStatusChecks)
+	assert.Nil(t, request.RequiredPullRequestReviews)
+
+	request = BranchProtectionSpec{Branch: "main", RequiredApprovingReviews: 2, RequiredStatusChecks: []string{"build"}, EnforceAdmins: true}.UpdateBranchProtectionRequest()
+	assert.True(t, request.EnforceAdmins)
+	assert.EqualValues(t, []string{"build"}, request.RequiredStatusChecks.Contexts)
+	assert.EqualValues(t, 2, request.RequiredPullRequestReviews.RequiredApprovingReviewCount)
+}
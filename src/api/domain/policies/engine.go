@@ -0,0 +1,109 @@
+package policies
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+)
+
+var (
+	PolicyEngine policyEngineInterface
+)
+
+func init() {
+	engine, err := LoadEngine(config.GetPolicyRulesPath())
+	if err != nil {
+		panic(err)
+	}
+	PolicyEngine = engine
+}
+
+type policyEngineInterface interface {
+	Evaluate(clientId string, request repositories.CreateRepoRequest) Decision
+	EvaluateName(clientId string, name string) Decision
+	EvaluateVisibility(clientId string, name string, private bool) Decision
+}
+
+type engine struct {
+	rules []Rule
+}
+
+type rulesFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// NewEngine checks the rules, which are evaluated in the given order.
+func NewEngine(rules ...Rule) (policyEngineInterface, error) {
+	result := &engine{rules: make([]Rule, len(rules))}
+	for index, rule := range rules {
+		if err := rule.compile(); err != nil {
+			return nil, err
+		}
+		result.rules[index] = rule
+	}
+	return result, nil
+}
+
+// LoadEngine reads the rules from a YAML or JSON file. An empty path returns
+// an engine that allows every request.
+func LoadEngine(path string) (policyEngineInterface, error) {
+	if path == "" {
+		return NewEngine()
+	}
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file rulesFile
+	if err := yaml.UnmarshalStrict(bytes, &file); err != nil {
+		return nil, fmt.Errorf("invalid policy rules %s: %w", path, err)
+	}
+	result, err := NewEngine(file.Rules...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy rules %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// Evaluate returns the decision of the first rule the request triggers.
+func (e *engine) Evaluate(clientId string, request repositories.CreateRepoRequest) Decision {
+	return e.evaluate(clientId, request, func(rule *Rule) []repositories.FieldError {
+		return rule.Check(request)
+	})
+}
+
+// EvaluateName returns the decision of the first rule an existing repository
+// given name triggers. Only the requirements on names are checked, since
+// renames and transfers keep every other setting.
+func (e *engine) EvaluateName(clientId string, name string) Decision {
+	return e.evaluate(clientId, repositories.CreateRepoRequest{Name: name}, func(rule *Rule) []repositories.FieldError {
+		return rule.CheckName(name)
+	})
+}
+
+// EvaluateVisibility returns the decision of the first rule an existing
+// repository made private, or public, triggers. Only the requirements on
+// visibility are checked, since nothing else changes.
+func (e *engine) EvaluateVisibility(clientId string, name string, private bool) Decision {
+	return e.evaluate(clientId, repositories.CreateRepoRequest{Name: name}, func(rule *Rule) []repositories.FieldError {
+		return rule.CheckVisibility(private)
+	})
+}
+
+func (e *engine) evaluate(clientId string, request repositories.CreateRepoRequest, check func(rule *Rule) []repositories.FieldError) Decision {
+	for index := range e.rules {
+		rule := &e.rules[index]
+		if !rule.AppliesTo(clientId, request) {
+			continue
+		}
+		violations := check(rule)
+		if rule.hasRequirements() && len(violations) == 0 {
+			continue
+		}
+		return Decision{Outcome: rule.Outcome, Rule: rule.Name, Violations: violations}
+	}
+	return Decision{Outcome: OutcomeAllow}
+}
@@ -0,0 +1,142 @@
+package policies
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestEvaluateWithoutRules(t *testing.T) {
+	engine, err := NewEngine()
+	assert.Nil(t, err)
+
+	decision := engine.Evaluate("team-a", repositories.CreateRepoRequest{Name: "anything"})
+	assert.EqualValues(t, OutcomeAllow, decision.Outcome)
+	assert.EqualValues(t, "", decision.Rule)
+}
+
+func TestEvaluateFirstTriggeredRuleWins(t *testing.T) {
+	engine, err := NewEngine(
+		Rule{Name: "admins", Clients: []string{"platform"}, Outcome: OutcomeAllow},
+		Rule{Name: "team-a-prefix", Clients: []string{"team-a"}, NamePrefix: "team-a-", Outcome: OutcomeDeny},
+		Rule{Name: "no-secrets", ForbiddenWords: []string{"Secret"}, RequireDescription: true, Outcome: OutcomeDeny},
+		Rule{Name: "production", Match: "^prod-", Outcome: OutcomeRequireApproval},
+	)
+	assert.Nil(t, err)
+
+	decision := engine.Evaluate("platform", repositories.CreateRepoRequest{Name: "secret"})
+	assert.EqualValues(t, OutcomeAllow, decision.Outcome)
+	assert.EqualValues(t, "admins", decision.Rule)
+
+	decision = engine.Evaluate("team-a", repositories.CreateRepoRequest{Name: "service", Description: "a service"})
+	assert.EqualValues(t, OutcomeDeny, decision.Outcome)
+	assert.EqualValues(t, "team-a-prefix", decision.Rule)
+	assert.EqualValues(t, []repositories.FieldError{{Field: "name", Message: "name must start with 'team-a-'"}}, decision.Violations)
+
+	decision = engine.Evaluate("team-b", repositories.CreateRepoRequest{Name: "my-secrets"})
+	assert.EqualValues(t, OutcomeDeny, decision.Outcome)
+	assert.EqualValues(t, "no-secrets", decision.Rule)
+	assert.EqualValues(t, 2, len(decision.Violations))
+	assert.EqualValues(t, "name must not contain 'secret'", decision.Violations[0].Message)
+	assert.EqualValues(t, "description is required", decision.Violations[1].Message)
+
+	decision = engine.Evaluate("team-b", repositories.CreateRepoRequest{Name: "prod-api", Description: "api"})
+	assert.EqualValues(t, OutcomeRequireApproval, decision.Outcome)
+	assert.EqualValues(t, "production", decision.Rule)
+	assert.EqualValues(t, 0, len(decision.Violations))
+
+	decision = engine.Evaluate("team-b", repositories.CreateRepoRequest{Name: "api", Description: "api"})
+	assert.EqualValues(t, OutcomeAllow, decision.Outcome)
+}
+
+func TestCheckVisibilityAndPattern(t *testing.T) {
+	rule := Rule{Name: "private", NamePattern: "^[a-z-]+$", Visibility: VisibilityPrivate, Outcome: OutcomeDeny}
+	assert.Nil(t, rule.compile())
+
+	violations := rule.Check(repositories.CreateRepoRequest{Name: "Api"})
+	assert.EqualValues(t, []repositories.FieldError{
+		{Field: "name", Message: "name must match '^[a-z-]+$'"},
+		{Field: "private", Message: "repository must be private"},
+	}, violations)
+
+	private := true
+	assert.EqualValues(t, 0, len(rule.Check(repositories.CreateRepoRequest{Name: "api", RepoSettings: repositories.RepoSettings{Private: &private}})))
+}
+
+func TestNewEngineInvalidRules(t *testing.T) {
+	_, err := NewEngine(Rule{Outcome: OutcomeDeny})
+	assert.EqualValues(t, "rule has no name", err.Error())
+
+	_, err = NewEngine(Rule{Name: "rule", Outcome: "maybe"})
+	assert.EqualValues(t, "rule rule: outcome must be one of allow, deny or require_approval", err.Error())
+
+	_, err = NewEngine(Rule{Name: "rule", Visibility: "internal", Outcome: OutcomeDeny})
+	assert.EqualValues(t, "rule rule: visibility must be private or public", err.Error())
+
+	_, err = NewEngine(Rule{Name: "rule", Match: "(", Outcome: OutcomeDeny})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "rule rule: invalid match")
+}
+
+func TestLoadEngine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	ioutil.WriteFile(path, []byte("rules:\n  - name: descriptions\n    require_description: true\n    outcome: require_approval\n"), 0600)
+
+	engine, err := LoadEngine(path)
+	assert.Nil(t, err)
+	decision := engine.Evaluate("team-a", repositories.CreateRepoRequest{Name: "api"})
+	assert.EqualValues(t, OutcomeRequireApproval, decision.Outcome)
+
+	ioutil.WriteFile(path, []byte("rules:\n  - name: descriptions\n    outcome: later\n"), 0600)
+	engine, err = LoadEngine(path)
+	assert.Nil(t, engine)
+	assert.Contains(t, err.Error(), "invalid policy rules")
+
+	ioutil.WriteFile(path, []byte("rules:\n  - name: descriptions\n    unknown: true\n"), 0600)
+	_, err = LoadEngine(path)
+	assert.NotNil(t, err)
+}
+
+func TestEvaluateName(t *testing.T) {
+	engine, err := NewEngine(
+		Rule{Name: "no-secrets", ForbiddenWords: []string{"secret"}, RequireDescription: true, Outcome: OutcomeDeny},
+		Rule{Name: "private", Visibility: VisibilityPrivate, Outcome: OutcomeDeny},
+		Rule{Name: "production", Match: "^prod-", Outcome: OutcomeRequireApproval},
+	)
+	assert.Nil(t, err)
+
+	decision := engine.EvaluateName("team-a", "api")
+	assert.EqualValues(t, OutcomeAllow, decision.Outcome)
+
+	decision = engine.EvaluateName("team-a", "my-secrets")
+	assert.EqualValues(t, OutcomeDeny, decision.Outcome)
+	assert.EqualValues(t, "no-secrets", decision.Rule)
+	assert.EqualValues(t, []repositories.FieldError{{Field: "name", Message: "name must not contain 'secret'"}}, decision.Violations)
+
+	decision = engine.EvaluateName("team-a", "prod-api")
+	assert.EqualValues(t, OutcomeRequireApproval, decision.Outcome)
+	assert.EqualValues(t, "production", decision.Rule)
+}
+
+func TestEvaluateVisibility(t *testing.T) {
+	engine, err := NewEngine(
+		Rule{Name: "no-secrets", ForbiddenWords: []string{"secret"}, RequireDescription: true, Outcome: OutcomeDeny},
+		Rule{Name: "private", Clients: []string{"team-a"}, Visibility: VisibilityPrivate, Outcome: OutcomeDeny},
+		Rule{Name: "production", Match: "^prod-", Outcome: OutcomeRequireApproval},
+	)
+	assert.Nil(t, err)
+
+	decision := engine.EvaluateVisibility("team-a", "my-secrets", true)
+	assert.EqualValues(t, OutcomeAllow, decision.Outcome)
+
+	decision = engine.EvaluateVisibility("team-a", "api", false)
+	assert.EqualValues(t, OutcomeDeny, decision.Outcome)
+	assert.EqualValues(t, "private", decision.Rule)
+	assert.EqualValues(t, []repositories.FieldError{{Field: "private", Message: "repository must be private"}}, decision.Violations)
+
+	decision = engine.EvaluateVisibility("team-b", "prod-api", false)
+	assert.EqualValues(t, OutcomeRequireApproval, decision.Outcome)
+	assert.EqualValues(t, "production", decision.Rule)
+}
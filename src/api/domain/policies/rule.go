@@ -0,0 +1,146 @@
+package policies
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"regexp"
+	"strings"
+)
+
+const (
+	OutcomeAllow           = "allow"
+	OutcomeDeny            = "deny"
+	OutcomeRequireApproval = "require_approval"
+
+	VisibilityPrivate = "private"
+	VisibilityPublic  = "public"
+)
+
+// Rule applies to the requests of its clients, every client when there are
+// none, whose name matches Match. A rule without requirements triggers on
+// every request it applies to; otherwise it triggers when the request breaks
+// at least one of them.
+type Rule struct {
+	Name    string   `json:"name" yaml:"name"`
+	Clients []string `json:"clients,omitempty" yaml:"clients"`
+	Match   string   `json:"match,omitempty" yaml:"match"`
+
+	NamePrefix         string   `json:"name_prefix,omitempty" yaml:"name_prefix"`
+	NamePattern        string   `json:"name_pattern,omitempty" yaml:"name_pattern"`
+	ForbiddenWords     []string `json:"forbidden_words,omitempty" yaml:"forbidden_words"`
+	RequireDescription bool     `json:"require_description,omitempty" yaml:"require_description"`
+	Visibility         string   `json:"visibility,omitempty" yaml:"visibility"`
+
+	Outcome string `json:"outcome" yaml:"outcome"`
+
+	match       *regexp.Regexp
+	namePattern *regexp.Regexp
+}
+
+// Decision is the outcome of the first rule triggered by a request, allow
+// when none is.
+type Decision struct {
+	Outcome    string                    `json:"outcome"`
+	Rule       string                    `json:"rule,omitempty"`
+	Violations []repositories.FieldError `json:"violations,omitempty"`
+}
+
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule has no name")
+	}
+	switch r.Outcome {
+	case OutcomeAllow, OutcomeDeny, OutcomeRequireApproval:
+	default:
+		return fmt.Errorf("rule %s: outcome must be one of %s, %s or %s", r.Name, OutcomeAllow, OutcomeDeny, OutcomeRequireApproval)
+	}
+	switch r.Visibility {
+	case "", VisibilityPrivate, VisibilityPublic:
+	default:
+		return fmt.Errorf("rule %s: visibility must be %s or %s", r.Name, VisibilityPrivate, VisibilityPublic)
+	}
+
+	var err error
+	if r.Match != "" {
+		if r.match, err = regexp.Compile(r.Match); err != nil {
+			return fmt.Errorf("rule %s: invalid match: %w", r.Name, err)
+		}
+	}
+	if r.NamePattern != "" {
+		if r.namePattern, err = regexp.Compile(r.NamePattern); err != nil {
+			return fmt.Errorf("rule %s: invalid name_pattern: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// AppliesTo reports whether the rule is concerned with the request at all.
+func (r *Rule) AppliesTo(clientId string, request repositories.CreateRepoRequest) bool {
+	if len(r.Clients) > 0 {
+		found := false
+		for _, current := range r.Clients {
+			if current == clientId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return r.match == nil || r.match.MatchString(request.Name)
+}
+
+// Check returns every requirement of the rule the request breaks.
+func (r *Rule) Check(request repositories.CreateRepoRequest) []repositories.FieldError {
+	result := r.CheckName(request.Name)
+	description := strings.ToLower(request.Description)
+	for _, word := range r.ForbiddenWords {
+		word = strings.ToLower(word)
+		if word != "" && strings.Contains(description, word) {
+			result = append(result, repositories.FieldError{Field: "description", Message: fmt.Sprintf("description must not contain '%s'", word)})
+		}
+	}
+
+	if r.RequireDescription && strings.TrimSpace(request.Description) == "" {
+		result = append(result, repositories.FieldError{Field: "description", Message: "description is required"})
+	}
+	return append(result, r.CheckVisibility(request.IsPrivate())...)
+}
+
+// CheckVisibility returns the requirement of the rule on visibility a
+// repository breaks, if any.
+func (r *Rule) CheckVisibility(private bool) []repositories.FieldError {
+	result := make([]repositories.FieldError, 0)
+	if r.Visibility == VisibilityPrivate && !private {
+		result = append(result, repositories.FieldError{Field: "private", Message: "repository must be private"})
+	}
+	if r.Visibility == VisibilityPublic && private {
+		result = append(result, repositories.FieldError{Field: "private", Message: "repository must be public"})
+	}
+	return result
+}
+
+// CheckName returns every requirement of the rule on names that name breaks.
+func (r *Rule) CheckName(name string) []repositories.FieldError {
+	result := make([]repositories.FieldError, 0)
+	if r.NamePrefix != "" && !strings.HasPrefix(name, r.NamePrefix) {
+		result = append(result, repositories.FieldError{Field: "name", Message: fmt.Sprintf("name must start with '%s'", r.NamePrefix)})
+	}
+	if r.namePattern != nil && !r.namePattern.MatchString(name) {
+		result = append(result, repositories.FieldError{Field: "name", Message: fmt.Sprintf("name must match '%s'", r.NamePattern)})
+	}
+	lower := strings.ToLower(name)
+	for _, word := range r.ForbiddenWords {
+		word = strings.ToLower(word)
+		if word != "" && strings.Contains(lower, word) {
+			result = append(result, repositories.FieldError{Field: "name", Message: fmt.Sprintf("name must not contain '%s'", word)})
+		}
+	}
+	return result
+}
+
+func (r *Rule) hasRequirements() bool {
+	return r.NamePrefix != "" || r.NamePattern != "" || len(r.ForbiddenWords) > 0 ||
+		r.RequireDescription || r.Visibility != ""
+}
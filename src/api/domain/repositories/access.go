@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"golang.org/x/crypto/ssh"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxDeployKeyTitleLength = 100
+	maxSecretValueLength    = 48 * 1024
+	reservedSecretPrefix    = "GITHUB_"
+)
+
+var (
+	validSecretName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// DeployKeyRequest adds Key, or a key pair generated by the service when
+// Generate is set, as a deploy key. Keys are read only unless told otherwise.
+type DeployKeyRequest struct {
+	Title    string `json:"title" yaml:"title"`
+	Key      string `json:"key,omitempty" yaml:"key"`
+	ReadOnly *bool  `json:"read_only,omitempty" yaml:"read_only"`
+	Generate bool   `json:"generate,omitempty" yaml:"generate"`
+}
+
+// IsReadOnly reports whether the key can only pull, the default.
+func (r DeployKeyRequest) IsReadOnly() bool {
+	return r.ReadOnly == nil || *r.ReadOnly
+}
+
+func (r *DeployKeyRequest) Validate() errors.ApiError {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Key = strings.TrimSpace(r.Key)
+	return validationError("invalid deploy key", validateDeployKeys([]DeployKeyRequest{*r}, ""))
+}
+
+// DeployKey is an added deploy key. PrivateKey is only set, once, for keys
+// generated by the service: it is neither stored nor retrievable later.
+type DeployKey struct {
+	Id         int64  `json:"id"`
+	Title      string `json:"title"`
+	Key        string `json:"key"`
+	ReadOnly   bool   `json:"read_only"`
+	PrivateKey string `json:"private_key,omitempty"`
+}
+
+// ActionsSecret is a GitHub Actions secret of the repository. Its value is
+// never echoed back nor stored.
+type ActionsSecret struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value,omitempty" yaml:"value"`
+}
+
+// SetSecretRequest sets the value of the secret named in the url.
+type SetSecretRequest struct {
+	Value string `json:"value"`
+}
+
+type SetSecretResponse struct {
+	Name string `json:"name"`
+}
+
+func (s *ActionsSecret) Validate() errors.ApiError {
+	s.Name = strings.ToUpper(strings.TrimSpace(s.Name))
+	return validationError("invalid secret", validateSecrets([]ActionsSecret{*s}, ""))
+}
+
+// NormalizeSecrets trims and upper cases the names, as GitHub stores them.
+func NormalizeSecrets(secrets []ActionsSecret) []ActionsSecret {
+	if secrets == nil {
+		return nil
+	}
+	result := make([]ActionsSecret, len(secrets))
+	for index, secret := range secrets {
+		result[index] = ActionsSecret{Name: strings.ToUpper(strings.TrimSpace(secret.Name)), Value: secret.Value}
+	}
+	return result
+}
+
+func validateDeployKeys(keys []DeployKeyRequest, path string) []FieldError {
+	result := make([]FieldError, 0)
+	titles := make(map[string]bool)
+	for index, key := range keys {
+		current := fieldPath(path, index)
+		title := strings.TrimSpace(key.Title)
+		switch {
+		case title == "":
+			result = append(result, FieldError{Field: current + "title", Message: "title is required"})
+		case len([]rune(title)) > maxDeployKeyTitleLength:
+			result = append(result, FieldError{Field: current + "title", Message: fmt.Sprintf("title must be at most %d characters long", maxDeployKeyTitleLength)})
+		case titles[title]:
+			result = append(result, FieldError{Field: current + "title", Message: fmt.Sprintf("deploy key '%s' is listed more than once", title)})
+		}
+		titles[title] = true
+
+		switch {
+		case key.Generate && key.Key != "":
+			result = append(result, FieldError{Field: current + "key", Message: "key can not be set when generate is"})
+		case !key.Generate && key.Key == "":
+			result = append(result, FieldError{Field: current + "key", Message: "key is required unless generate is set"})
+		case key.Key != "":
+			if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.Key)); err != nil {
+				result = append(result, FieldError{Field: current + "key", Message: "key must be a public key in the authorized_keys format"})
+			}
+		}
+	}
+	return result
+}
+
+// validateSecrets checks secrets whose names are already upper cased, as
+// GitHub stores them.
+func validateSecrets(secrets []ActionsSecret, path string) []FieldError {
+	result := make([]FieldError, 0)
+	names := make(map[string]bool)
+	for index, secret := range secrets {
+		current := fieldPath(path, index)
+		switch {
+		case secret.Name == "":
+			result = append(result, FieldError{Field: current + "name", Message: "name is required"})
+		case !validSecretName.MatchString(secret.Name):
+			result = append(result, FieldError{Field: current + "name", Message: "name may only contain letters, digits and '_' and can not start with a digit"})
+		case strings.HasPrefix(secret.Name, reservedSecretPrefix):
+			result = append(result, FieldError{Field: current + "name", Message: fmt.Sprintf("name can not start with '%s'", reservedSecretPrefix)})
+		case names[secret.Name]:
+			result = append(result, FieldError{Field: current + "name", Message: fmt.Sprintf("secret '%s' is listed more than once", secret.Name)})
+		}
+		names[secret.Name] = true
+
+		if len(secret.Value) > maxSecretValueLength {
+			result = append(result, FieldError{Field: current + "value", Message: fmt.Sprintf("value must be at most %d bytes long", maxSecretValueLength)})
+		}
+	}
+	return result
+}
+
+// fieldPath prefixes the fields of the item at index of the list at path, or
+// of the single item when path is empty.
+func fieldPath(path string, index int) string {
+	if path == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s[%d].", path, index)
+}
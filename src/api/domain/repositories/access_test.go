@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const (
+	testPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl deploy"
+)
+
+func TestDeployKeyRequestValidate(t *testing.T) {
+	request := DeployKeyRequest{Title: " ci ", Key: testPublicKey}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, "ci", request.Title)
+	assert.True(t, request.IsReadOnly())
+
+	request = DeployKeyRequest{Title: "ci", Generate: true}
+	assert.Nil(t, request.Validate())
+
+	request = DeployKeyRequest{Key: "not a key"}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []interface{}{
+		FieldError{Field: "title", Message: "title is required"},
+		FieldError{Field: "key", Message: "key must be a public key in the authorized_keys format"},
+	}, err.Causes())
+
+	request = DeployKeyRequest{Title: "ci", Key: testPublicKey, Generate: true}
+	assert.NotNil(t, request.Validate())
+}
+
+func TestActionsSecretValidate(t *testing.T) {
+	secret := ActionsSecret{Name: " npm_token ", Value: "s3cret"}
+	assert.Nil(t, secret.Validate())
+	assert.EqualValues(t, "NPM_TOKEN", secret.Name)
+
+	for _, name := range []string{"", "1TOKEN", "NPM-TOKEN", "github_token"} {
+		secret = ActionsSecret{Name: name}
+		assert.NotNil(t, secret.Validate(), name)
+	}
+
+	secret = ActionsSecret{Name: "BIG", Value: strings.Repeat("a", maxSecretValueLength+1)}
+	assert.NotNil(t, secret.Validate())
+}
+
+func TestRepoSettingsRedactsSecrets(t *testing.T) {
+	settings := RepoSettings{
+		Secrets:  []ActionsSecret{{Name: "NPM_TOKEN", Value: "s3cret"}},
+		Webhooks: []Webhook{{Url: "https://ci.example.com/hook", Secret: "s3cret"}},
+	}
+	redacted := settings.Redacted()
+	assert.EqualValues(t, []ActionsSecret{{Name: "NPM_TOKEN"}}, redacted.Secrets)
+	assert.EqualValues(t, "", redacted.Webhooks[0].Secret)
+	assert.EqualValues(t, "s3cret", settings.Secrets[0].Value)
+}
+
+func TestRepoSettingsDuplicateSecrets(t *testing.T) {
+	settings := RepoSettings{Secrets: []ActionsSecret{{Name: "token"}, {Name: "TOKEN"}}}
+	assert.EqualValues(t, []FieldError{{Field: "secrets[1].name", Message: "secret 'TOKEN' is listed more than once"}}, settings.validate())
+}
+
+func TestCreateRepoResponseWithoutPrivateKeys(t *testing.T) {
+	response := CreateRepoResponse{DeployKeys: []DeployKey{{Title: "ci", PrivateKey: "private"}}}
+	assert.EqualValues(t, "", response.WithoutPrivateKeys().DeployKeys[0].PrivateKey)
+	assert.EqualValues(t, "private", response.DeployKeys[0].PrivateKey)
+}
@@ -1,35 +1,248 @@
 package repositories
 
 import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
 	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/url"
+	"regexp"
 	"strings"
 )
 
+const (
+	maxNameLength        = 100
+	maxDescriptionLength = 350
+	reservedNameSuffix   = ".git"
+)
+
+var (
+	validNameCharacters = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	reservedNames       = []string{".", ".."}
+)
+
+// CreateRepoRequest describes a new repository. Its settings are merged on
+// top of the ones of Preset, when set, by Validate.
 type CreateRepoRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Homepage    string `json:"homepage"`
+	Preset      string `json:"preset,omitempty"`
+	RepoSettings
 }
 
+// FieldError describes a single validation violation and is reported as one
+// of the causes of the resulting ApiError.
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
+
+// Validate merges the settings of the preset into the request and checks it
+// against GitHub's repository rules and the configured naming policy,
+// reporting every violation at once. Validating twice changes nothing.
 func (r *CreateRepoRequest) Validate() errors.ApiError {
 	r.Name = strings.TrimSpace(r.Name)
-	if r.Name == "" {
-		return errors.NewBadRequestError("invalid repository name")
+	r.Homepage = strings.TrimSpace(r.Homepage)
+
+	nameErrors := r.validateName()
+	causes := make([]interface{}, 0)
+	for _, current := range nameErrors {
+		causes = append(causes, current)
+	}
+	for _, current := range r.validateDetails() {
+		causes = append(causes, current)
+	}
+	for _, current := range r.applyPreset() {
+		causes = append(causes, current)
+	}
+	for _, current := range r.RepoSettings.validate() {
+		causes = append(causes, current)
+	}
+
+	if len(causes) == 0 {
+		return nil
+	}
+	if len(nameErrors) > 0 {
+		return errors.NewValidationError("invalid repository name", causes...)
+	}
+	return errors.NewValidationError("invalid repository request", causes...)
+}
+
+func (r *CreateRepoRequest) validateName() []FieldError {
+	return validateName("name", r.Name)
+}
+
+// validateName checks a repository name against GitHub's rules and the
+// configured naming policy, reporting violations against field.
+func validateName(field string, name string) []FieldError {
+	result := make([]FieldError, 0)
+	if name == "" {
+		return append(result, FieldError{Field: field, Message: fmt.Sprintf("%s is required", field)})
+	}
+
+	if len(name) > maxNameLength {
+		result = append(result, FieldError{Field: field, Message: fmt.Sprintf("%s must be at most %d characters long", field, maxNameLength)})
+	}
+	if !validNameCharacters.MatchString(name) {
+		result = append(result, FieldError{Field: field, Message: fmt.Sprintf("%s may only contain ASCII letters, digits, '.', '-' and '_'", field)})
+	}
+	for _, reserved := range reservedNames {
+		if name == reserved {
+			result = append(result, FieldError{Field: field, Message: fmt.Sprintf("%s '%s' is reserved", field, reserved)})
+		}
+	}
+	if strings.HasSuffix(strings.ToLower(name), reservedNameSuffix) {
+		result = append(result, FieldError{Field: field, Message: fmt.Sprintf("%s cannot end with '%s'", field, reservedNameSuffix)})
+	}
+
+	if policy := config.GetRepoNamePolicy(); policy != "" {
+		matcher, err := regexp.Compile(policy)
+		if err != nil {
+			result = append(result, FieldError{Field: field, Message: "naming policy is not a valid regular expression"})
+		} else if !matcher.MatchString(name) {
+			result = append(result, FieldError{Field: field, Message: fmt.Sprintf("%s does not match naming policy '%s'", field, policy)})
+		}
+	}
+	return result
+}
+
+func (r *CreateRepoRequest) validateDetails() []FieldError {
+	result := make([]FieldError, 0)
+	if len([]rune(r.Description)) > maxDescriptionLength {
+		result = append(result, FieldError{Field: "description", Message: fmt.Sprintf("description must be at most %d characters long", maxDescriptionLength)})
+	}
+
+	if r.Homepage != "" {
+		homepage, err := url.Parse(r.Homepage)
+		if err != nil || (homepage.Scheme != "http" && homepage.Scheme != "https") || homepage.Host == "" {
+			result = append(result, FieldError{Field: "homepage", Message: "homepage must be an absolute http or https url"})
+		}
+	}
+	return result
+}
+
+// applyPreset merges the settings of the request on top of the ones of its
+// preset, when set, and reports an unknown preset.
+func (r *CreateRepoRequest) applyPreset() []FieldError {
+	if r.Preset == "" {
+		return nil
+	}
+	preset, exists := PresetRegistry.Get(r.Preset)
+	if !exists {
+		return []FieldError{{Field: "preset", Message: fmt.Sprintf("unknown preset '%s'", r.Preset)}}
 	}
+	r.RepoSettings = preset.RepoSettings.Merge(r.RepoSettings)
 	return nil
 }
 
+// CreateRepoResponse describes the created repository with the settings it
+// was created with, or, when ApprovalId is set, the request waiting for an
+// admin to approve it. SettingsError and Files report what could not be
+// applied to a repository that was created nonetheless; DeployKeys the keys
+// added to it.
 type CreateRepoResponse struct {
-	Id    int64  `json:"id"`
-	Owner string `json:"owner"`
-	Name  string `json:"name"`
+	Id            int64            `json:"id"`
+	Owner         string           `json:"owner"`
+	Name          string           `json:"name"`
+	ApprovalId    string           `json:"approval_id,omitempty"`
+	Preset        string           `json:"preset,omitempty"`
+	Settings      *RepoSettings    `json:"settings,omitempty"`
+	SettingsError errors.ApiError  `json:"settings_error,omitempty"`
+	Files         []ScaffoldResult `json:"files,omitempty"`
+	DeployKeys    []DeployKey      `json:"deploy_keys,omitempty"`
+}
+
+// WithoutPrivateKeys returns a copy without the private keys of generated
+// deploy keys, which are only handed out once.
+func (r CreateRepoResponse) WithoutPrivateKeys() CreateRepoResponse {
+	if r.DeployKeys == nil {
+		return r
+	}
+	keys := make([]DeployKey, len(r.DeployKeys))
+	for index, key := range r.DeployKeys {
+		key.PrivateKey = ""
+		keys[index] = key
+	}
+	r.DeployKeys = keys
+	return r
+}
+
+// ScaffoldResult is the outcome of committing one scaffolded file.
+type ScaffoldResult struct {
+	Path      string          `json:"path"`
+	Committed bool            `json:"committed"`
+	CommitSha string          `json:"commit_sha,omitempty"`
+	Error     errors.ApiError `json:"error,omitempty"`
+}
+
+const (
+	DuplicatesReject   = "reject"
+	DuplicatesCollapse = "collapse"
+
+	SkipReasonDuplicate = "duplicate"
+	SkipReasonExists    = "exists"
+)
+
+// CreateReposOptions changes how a batch is processed.
+type CreateReposOptions struct {
+	// Atomic deletes the repositories already created when any of them fails.
+	Atomic bool `json:"atomic,omitempty"`
+	// Duplicates tells what happens to a request whose name was already used
+	// by an earlier request of the batch: DuplicatesReject, the default, fails
+	// it while DuplicatesCollapse only leaves it out.
+	Duplicates string `json:"duplicates,omitempty"`
+	// CheckExisting looks the names up on GitHub before creating anything and
+	// fails the requests for repositories that already exist.
+	CheckExisting bool `json:"check_existing,omitempty"`
+}
+
+func (o CreateReposOptions) Validate() errors.ApiError {
+	switch o.Duplicates {
+	case "", DuplicatesReject, DuplicatesCollapse:
+		return nil
+	}
+	return errors.NewBadRequestError(fmt.Sprintf("duplicates must be one of %s or %s", DuplicatesReject, DuplicatesCollapse))
 }
 
 type CreateReposResponse struct {
-	StatusCode int                        `json:"status"`
-	Results    []CreateRepositoriesResult `json:"results"`
+	StatusCode    int                        `json:"status"`
+	Results       []CreateRepositoriesResult `json:"results"`
+	Failure       *CreateRepositoriesResult  `json:"failure,omitempty"`
+	Compensations []CompensationResult       `json:"compensations,omitempty"`
 }
 
+// CompensationResult is the outcome of deleting a repository created by an
+// atomic batch that failed afterwards.
+type CompensationResult struct {
+	Index   int             `json:"index"`
+	Owner   string          `json:"owner"`
+	Name    string          `json:"name"`
+	Deleted bool            `json:"deleted"`
+	Error   errors.ApiError `json:"error,omitempty"`
+}
+
+// CreateRepositoriesResult is the outcome of one request of a batch. Skipped
+// tells why the request was never sent to GitHub and DuplicateOf, for
+// duplicates, the index of the request it repeats.
 type CreateRepositoriesResult struct {
-	Response *CreateRepoResponse `json:"repo"`
-	Error    errors.ApiError     `json:"error"`
+	Index       int                 `json:"index"`
+	Response    *CreateRepoResponse `json:"repo"`
+	Error       errors.ApiError     `json:"error"`
+	Skipped     string              `json:"skipped,omitempty"`
+	DuplicateOf *int                `json:"duplicate_of,omitempty"`
+}
+
+// CreateReposEvent is streamed while a batch is processed: one event per
+// repository result and a final one with the summary of the whole batch.
+type CreateReposEvent struct {
+	Result  *CreateRepositoriesResult `json:"result,omitempty"`
+	Summary *CreateReposSummary       `json:"summary,omitempty"`
+}
+
+type CreateReposSummary struct {
+	StatusCode int `json:"status"`
+	Total      int `json:"total"`
+	Succeeded  int `json:"succeeded"`
+	Failed     int `json:"failed"`
+	Skipped    int `json:"skipped,omitempty"`
 }
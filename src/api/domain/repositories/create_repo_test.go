@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateEmptyName(t *testing.T) {
+	request := CreateRepoRequest{Name: "   "}
+
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "invalid repository name", err.Message())
+	assert.EqualValues(t, 1, len(err.Causes()))
+	assert.EqualValues(t, FieldError{Field: "name", Message: "name is required"}, err.Causes()[0])
+}
+
+func TestValidateNoError(t *testing.T) {
+	request := CreateRepoRequest{Name: "  golang-tutorial_v2.0 ", Homepage: " https://github.com "}
+
+	err := request.Validate()
+	assert.Nil(t, err)
+	assert.EqualValues(t, "golang-tutorial_v2.0", request.Name)
+	assert.EqualValues(t, "https://github.com", request.Homepage)
+}
+
+func TestValidateReservedNames(t *testing.T) {
+	for _, name := range []string{".", ".."} {
+		request := CreateRepoRequest{Name: name}
+		err := request.Validate()
+		assert.NotNil(t, err)
+		assert.EqualValues(t, "invalid repository name", err.Message())
+		assert.Contains(t, err.Causes(), FieldError{Field: "name", Message: "name '" + name + "' is reserved"})
+	}
+
+	request := CreateRepoRequest{Name: "golang.GIT"}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []interface{}{FieldError{Field: "name", Message: "name cannot end with '.git'"}}, err.Causes())
+}
+
+func TestValidateAggregatesAllErrors(t *testing.T) {
+	request := CreateRepoRequest{
+		Name:        strings.Repeat("a", 100) + " b",
+		Description: strings.Repeat("d", 351),
+		Homepage:    "github.com",
+	}
+
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "invalid repository name", err.Message())
+	assert.EqualValues(t, []interface{}{
+		FieldError{Field: "name", Message: "name must be at most 100 characters long"},
+		FieldError{Field: "name", Message: "name may only contain ASCII letters, digits, '.', '-' and '_'"},
+		FieldError{Field: "description", Message: "description must be at most 350 characters long"},
+		FieldError{Field: "homepage", Message: "homepage must be an absolute http or https url"},
+	}, err.Causes())
+}
+
+func TestValidateInvalidDetailsOnly(t *testing.T) {
+	request := CreateRepoRequest{Name: "testing", Homepage: "ftp://example.com"}
+
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid repository request", err.Message())
+	assert.EqualValues(t, []interface{}{FieldError{Field: "homepage", Message: "homepage must be an absolute http or https url"}}, err.Causes())
+}
+
+func TestValidateNamingPolicy(t *testing.T) {
+	os.Setenv("REPO_NAME_POLICY", "^team-[a-z]+$")
+	defer os.Unsetenv("REPO_NAME_POLICY")
+
+	request := CreateRepoRequest{Name: "team-payments"}
+	assert.Nil(t, request.Validate())
+
+	request = CreateRepoRequest{Name: "payments"}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []interface{}{FieldError{Field: "name", Message: "name does not match naming policy '^team-[a-z]+$'"}}, err.Causes())
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"strings"
+)
+
+const (
+	DryRunActionCreate          = "create"
+	DryRunActionReject          = "reject"
+	DryRunActionRequireApproval = "require_approval"
+)
+
+// DryRunResult tells what would happen to one repository if the request was
+// sent for real. Policy names the rule that would hold its creation for
+// approval.
+type DryRunResult struct {
+	Index  int             `json:"index"`
+	Owner  string          `json:"owner,omitempty"`
+	Name   string          `json:"name"`
+	Action string          `json:"action"`
+	Policy string          `json:"policy,omitempty"`
+	Error  errors.ApiError `json:"error,omitempty"`
+}
+
+type DryRunResponse struct {
+	Create          int            `json:"create"`
+	Reject          int            `json:"reject"`
+	RequireApproval int            `json:"require_approval"`
+	Results         []DryRunResult `json:"results"`
+}
+
+// FindDuplicates returns, for every request whose name was already used by an
+// earlier request of the batch, the index of that first request. Names are
+// compared the way GitHub does: ignoring case and surrounding spaces.
+func FindDuplicates(requests []CreateRepoRequest) map[int]int {
+	result := make(map[int]int)
+	seen := make(map[string]int)
+	for index, request := range requests {
+		name := strings.ToLower(strings.TrimSpace(request.Name))
+		if name == "" {
+			continue
+		}
+		if first, exists := seen[name]; exists {
+			result[index] = first
+			continue
+		}
+		seen[name] = index
+	}
+	return result
+}
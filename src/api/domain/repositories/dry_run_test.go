@@ -0,0 +1,25 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	requests := []CreateRepoRequest{
+		{Name: "testing"},
+		{Name: " Testing "},
+		{Name: "other"},
+		{Name: ""},
+		{Name: "  "},
+		{Name: "TESTING"},
+	}
+
+	duplicates := FindDuplicates(requests)
+	assert.EqualValues(t, map[int]int{1: 0, 5: 0}, duplicates)
+}
+
+func TestFindDuplicatesNone(t *testing.T) {
+	assert.EqualValues(t, 0, len(FindDuplicates([]CreateRepoRequest{{Name: "a"}, {Name: "b"}})))
+	assert.EqualValues(t, 0, len(FindDuplicates(nil)))
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxLabelDescriptionLength = 100
+
+	LabelActionCreated = "created"
+	LabelActionUpdated = "updated"
+)
+
+var (
+	validLabelColor = regexp.MustCompile(`^[0-9a-f]{6}$`)
+
+	// StandardLabels are created when a request asks for labels without
+	// listing any.
+	StandardLabels []Label
+
+	defaultStandardLabels = []Label{
+		{Name: "bug", Color: "d73a4a", Description: "Something isn't working"},
+		{Name: "documentation", Color: "0075ca", Description: "Improvements or additions to documentation"},
+		{Name: "enhancement", Color: "a2eeef", Description: "New feature or request"},
+		{Name: "question", Color: "d876e3", Description: "Further information is requested"},
+		{Name: "good first issue", Color: "7057ff", Description: "Good for newcomers"},
+		{Name: "help wanted", Color: "008672", Description: "Extra attention is needed"},
+	}
+)
+
+func init() {
+	labels, err := LoadStandardLabels(config.GetRepoStandardLabelsPath())
+	if err != nil {
+		panic(err)
+	}
+	StandardLabels = labels
+}
+
+type Label struct {
+	Name        string `json:"name" yaml:"name"`
+	Color       string `json:"color" yaml:"color"`
+	Description string `json:"description,omitempty" yaml:"description"`
+}
+
+type labelsFile struct {
+	Labels []Label `yaml:"labels"`
+}
+
+// CreateLabelsRequest creates or updates every label, the standard ones when
+// none is listed.
+type CreateLabelsRequest struct {
+	Labels []Label `json:"labels"`
+}
+
+type LabelResult struct {
+	Name   string          `json:"name"`
+	Action string          `json:"action,omitempty"`
+	Error  errors.ApiError `json:"error,omitempty"`
+}
+
+func (r *CreateLabelsRequest) Validate() errors.ApiError {
+	if len(r.Labels) == 0 {
+		r.Labels = StandardLabels
+	}
+	r.Labels = NormalizeLabels(r.Labels)
+	return validationError("invalid labels", ValidateLabels(r.Labels, "labels"))
+}
+
+type CreateLabelsResponse struct {
+	StatusCode int           `json:"status"`
+	Results    []LabelResult `json:"results"`
+}
+
+// LoadStandardLabels reads the labels from a YAML or JSON file. An empty
+// path returns a set of common labels.
+func LoadStandardLabels(path string) ([]Label, error) {
+	if path == "" {
+		return defaultStandardLabels, nil
+	}
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file labelsFile
+	if err := yaml.UnmarshalStrict(bytes, &file); err != nil {
+		return nil, fmt.Errorf("invalid standard labels %s: %w", path, err)
+	}
+	labels := NormalizeLabels(file.Labels)
+	if violations := ValidateLabels(labels, "labels"); len(violations) > 0 {
+		return nil, fmt.Errorf("invalid standard labels %s: %s %s", path, violations[0].Field, violations[0].Message)
+	}
+	return labels, nil
+}
+
+// NormalizeLabels trims the names and lower cases the colors, which GitHub
+// accepts without a leading '#'.
+func NormalizeLabels(labels []Label) []Label {
+	if labels == nil {
+		return nil
+	}
+	result := make([]Label, len(labels))
+	for index, label := range labels {
+		result[index] = Label{
+			Name:        strings.TrimSpace(label.Name),
+			Color:       strings.ToLower(strings.TrimPrefix(strings.TrimSpace(label.Color), "#")),
+			Description: label.Description,
+		}
+	}
+	return result
+}
+
+// ValidateLabels checks normalized labels. Fields are prefixed with path.
+func ValidateLabels(labels []Label, path string) []FieldError {
+	result := make([]FieldError, 0)
+	names := make(map[string]bool)
+	for index, label := range labels {
+		current := fmt.Sprintf("%s[%d]", path, index)
+		switch {
+		case label.Name == "":
+			result = append(result, FieldError{Field: current + ".name", Message: "name is required"})
+		case names[strings.ToLower(label.Name)]:
+			result = append(result, FieldError{Field: current + ".name", Message: fmt.Sprintf("label '%s' is listed more than once", label.Name)})
+		}
+		names[strings.ToLower(label.Name)] = true
+
+		if !validLabelColor.MatchString(label.Color) {
+			result = append(result, FieldError{Field: current + ".color", Message: "color must be a hexadecimal color code such as d73a4a"})
+		}
+		if len([]rune(label.Description)) > maxLabelDescriptionLength {
+			result = append(result, FieldError{Field: current + ".description", Message: fmt.Sprintf("description must be at most %d characters long", maxLabelDescriptionLength)})
+		}
+	}
+	return result
+}
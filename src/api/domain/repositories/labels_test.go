@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateLabelsRequestDefaultsToStandardLabels(t *testing.T) {
+	request := CreateLabelsRequest{}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, StandardLabels, request.Labels)
+}
+
+func TestCreateLabelsRequestNormalizes(t *testing.T) {
+	request := CreateLabelsRequest{Labels: []Label{{Name: " bug ", Color: "#D73A4A"}}}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, []Label{{Name: "bug", Color: "d73a4a"}}, request.Labels)
+}
+
+func TestCreateLabelsRequestInvalid(t *testing.T) {
+	request := CreateLabelsRequest{Labels: []Label{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "Bug", Color: "red", Description: strings.Repeat("a", maxLabelDescriptionLength+1)},
+		{Color: "ffffff"},
+	}}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, []interface{}{
+		FieldError{Field: "labels[1].name", Message: "label 'Bug' is listed more than once"},
+		FieldError{Field: "labels[1].color", Message: "color must be a hexadecimal color code such as d73a4a"},
+		FieldError{Field: "labels[1].description", Message: "description must be at most 100 characters long"},
+		FieldError{Field: "labels[2].name", Message: "name is required"},
+	}, err.Causes())
+}
+
+func TestLoadStandardLabels(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "labels.yml")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("labels:\n  - name: triage\n    color: \"#FBCA04\"\n"), 0600))
+
+	labels, err := LoadStandardLabels(path)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []Label{{Name: "triage", Color: "fbca04"}}, labels)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("labels:\n  - name: triage\n    color: yellow\n"), 0600))
+	_, err = LoadStandardLabels(path)
+	assert.NotNil(t, err)
+}
+
+func TestTopicsValidate(t *testing.T) {
+	topics := Topics{}
+	assert.Nil(t, topics.Validate())
+	assert.EqualValues(t, []string{}, topics.Names)
+
+	topics = Topics{Names: []string{"Not Valid"}}
+	assert.NotNil(t, topics.Validate())
+}
+
+func TestUpdateRepoRequestValidate(t *testing.T) {
+	request := UpdateRepoRequest{}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "nothing to update", err.Message())
+
+	homepage := " ftp://example.com "
+	request = UpdateRepoRequest{Homepage: &homepage}
+	err = request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []interface{}{FieldError{Field: "homepage", Message: "homepage must be an absolute http or https url"}}, err.Causes())
+
+	empty := ""
+	request = UpdateRepoRequest{Description: &empty, Homepage: &empty}
+	assert.Nil(t, request.Validate())
+}
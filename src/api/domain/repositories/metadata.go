@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/url"
+	"strings"
+)
+
+type Topics struct {
+	Names []string `json:"names"`
+}
+
+func (t *Topics) Validate() errors.ApiError {
+	if t.Names == nil {
+		t.Names = []string{}
+	}
+	return validationError("invalid topics", ValidateTopics(t.Names, "names"))
+}
+
+// UpdateRepoRequest changes an existing repository: its metadata, its name
+// and whether it is archived. Fields left out are not changed; an empty
+// description or homepage clears it.
+type UpdateRepoRequest struct {
+	Description *string `json:"description"`
+	Homepage    *string `json:"homepage"`
+	Name        *string `json:"name"`
+	Archived    *bool   `json:"archived"`
+}
+
+func (r *UpdateRepoRequest) Validate() errors.ApiError {
+	if r.Description == nil && r.Homepage == nil && r.Name == nil && r.Archived == nil {
+		return errors.NewBadRequestError("nothing to update")
+	}
+
+	result := make([]FieldError, 0)
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		r.Name = &name
+		result = append(result, validateName("name", name)...)
+	}
+	if r.Description != nil && len([]rune(*r.Description)) > maxDescriptionLength {
+		result = append(result, FieldError{Field: "description", Message: fmt.Sprintf("description must be at most %d characters long", maxDescriptionLength)})
+	}
+	if r.Homepage != nil {
+		homepage := strings.TrimSpace(*r.Homepage)
+		r.Homepage = &homepage
+		if homepage != "" {
+			target, err := url.Parse(homepage)
+			if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+				result = append(result, FieldError{Field: "homepage", Message: "homepage must be an absolute http or https url"})
+			}
+		}
+	}
+	return validationError("invalid repository request", result)
+}
+
+// RepositoryMetadata is what classifies a repository.
+type RepositoryMetadata struct {
+	Owner       string   `json:"owner"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Homepage    string   `json:"homepage"`
+	Topics      []string `json:"topics"`
+	Archived    bool     `json:"archived"`
+}
+
+func validationError(message string, violations []FieldError) errors.ApiError {
+	if len(violations) == 0 {
+		return nil
+	}
+	causes := make([]interface{}, 0, len(violations))
+	for _, current := range violations {
+		causes = append(causes, current)
+	}
+	return errors.NewValidationError(message, causes...)
+}
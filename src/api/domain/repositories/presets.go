@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+)
+
+var (
+	PresetRegistry presetRegistryInterface
+)
+
+func init() {
+	registry, err := LoadPresets(config.GetRepoPresetsPath())
+	if err != nil {
+		panic(err)
+	}
+	PresetRegistry = registry
+}
+
+// Preset is a named set of settings requests refer to instead of repeating
+// them, such as go-service, library or docs.
+type Preset struct {
+	Name         string `json:"name" yaml:"name"`
+	RepoSettings `yaml:",inline"`
+}
+
+type presetRegistryInterface interface {
+	Get(name string) (*Preset, bool)
+}
+
+type presetRegistry struct {
+	presets map[string]Preset
+}
+
+type presetsFile struct {
+	Presets []Preset `yaml:"presets"`
+}
+
+// NewPresetRegistry checks every preset like the settings of a request.
+func NewPresetRegistry(presets ...Preset) (presetRegistryInterface, error) {
+	result := &presetRegistry{presets: make(map[string]Preset)}
+	for index, preset := range presets {
+		if preset.Name == "" {
+			return nil, fmt.Errorf("preset %d has no name", index)
+		}
+		if _, exists := result.presets[preset.Name]; exists {
+			return nil, fmt.Errorf("preset %s is defined more than once", preset.Name)
+		}
+		if violations := preset.validate(); len(violations) > 0 {
+			return nil, fmt.Errorf("preset %s: %s %s", preset.Name, violations[0].Field, violations[0].Message)
+		}
+		result.presets[preset.Name] = preset
+	}
+	return result, nil
+}
+
+// LoadPresets reads the presets from a YAML or JSON file. An empty path
+// returns a registry without presets.
+func LoadPresets(path string) (presetRegistryInterface, error) {
+	if path == "" {
+		return NewPresetRegistry()
+	}
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file presetsFile
+	if err := yaml.UnmarshalStrict(bytes, &file); err != nil {
+		return nil, fmt.Errorf("invalid presets %s: %w", path, err)
+	}
+	result, err := NewPresetRegistry(file.Presets...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid presets %s: %w", path, err)
+	}
+	return result, nil
+}
+
+func (r *presetRegistry) Get(name string) (*Preset, bool) {
+	preset, exists := r.presets[name]
+	if !exists {
+		return nil, false
+	}
+	return &preset, true
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func withPresets(presets []Preset, test func()) {
+	original := PresetRegistry
+	defer func() { PresetRegistry = original }()
+
+	PresetRegistry, _ = NewPresetRegistry(presets...)
+	test()
+}
+
+func TestValidateMergesPreset(t *testing.T) {
+	private := true
+	public := false
+	preset := Preset{Name: "go-service", RepoSettings: RepoSettings{
+		Private:           &private,
+		GitignoreTemplate: "Go",
+		Topics:            []string{"go", "service"},
+		BranchProtection:  []BranchProtection{{Branch: "main", RequiredApprovingReviews: 1}},
+	}}
+
+	withPresets([]Preset{preset}, func() {
+		request := CreateRepoRequest{
+			Name:         "api",
+			Preset:       "go-service",
+			RepoSettings: RepoSettings{Private: &public, Topics: []string{}},
+		}
+		assert.Nil(t, request.Validate())
+		assert.False(t, request.IsPrivate())
+		assert.EqualValues(t, "Go", request.GitignoreTemplate)
+		assert.EqualValues(t, []string{}, request.Topics)
+		assert.EqualValues(t, 1, len(request.BranchProtection))
+
+		assert.Nil(t, request.Validate())
+		assert.False(t, request.IsPrivate())
+		assert.EqualValues(t, []string{}, request.Topics)
+	})
+}
+
+func TestValidateUnknownPreset(t *testing.T) {
+	request := CreateRepoRequest{Name: "api", Preset: "missing"}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "invalid repository request", err.Message())
+	assert.EqualValues(t, []interface{}{FieldError{Field: "preset", Message: "unknown preset 'missing'"}}, err.Causes())
+}
+
+func TestValidateSettings(t *testing.T) {
+	request := CreateRepoRequest{Name: "api", RepoSettings: RepoSettings{
+		Topics:           []string{"Go", "-invalid"},
+		BranchProtection: []BranchProtection{{Branch: "main", RequiredApprovingReviews: 7}, {Branch: "main"}},
+		Webhooks:         []Webhook{{Url: "ci.example.com", ContentType: "xml"}},
+	}}
+
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []interface{}{
+		FieldError{Field: "topics[1]", Message: "topics must start with a letter or digit, contain only letters, digits and '-' and be at most 50 characters long"},
+		FieldError{Field: "branch_protection[0].required_approving_reviews", Message: "required approving reviews must be between 0 and 6"},
+		FieldError{Field: "branch_protection[1].branch", Message: "branch 'main' is protected more than once"},
+		FieldError{Field: "webhooks[0].url", Message: "url must be an absolute http or https url"},
+		FieldError{Field: "webhooks[0].content_type", Message: "content type must be json or form"},
+	}, err.Causes())
+}
+
+func TestRedacted(t *testing.T) {
+	settings := RepoSettings{Webhooks: []Webhook{{Url: "https://ci.example.com", Secret: "s3cret"}}}
+
+	redacted := settings.Redacted()
+	assert.EqualValues(t, "", redacted.Webhooks[0].Secret)
+	assert.EqualValues(t, "https://ci.example.com", redacted.Webhooks[0].Url)
+	assert.EqualValues(t, "s3cret", settings.Webhooks[0].Secret)
+}
+
+func TestLoadPresets(t *testing.T) {
+	registry, err := LoadPresets("")
+	assert.Nil(t, err)
+	_, exists := registry.Get("docs")
+	assert.False(t, exists)
+
+	path := filepath.Join(t.TempDir(), "presets.yaml")
+	ioutil.WriteFile(path, []byte("presets:\n  - name: docs\n    private: false\n    topics: [docs]\n    webhooks:\n      - url: https://docs.example.com/hook\n"), 0600)
+	registry, err = LoadPresets(path)
+	assert.Nil(t, err)
+	preset, exists := registry.Get("docs")
+	assert.True(t, exists)
+	assert.EqualValues(t, []string{"docs"}, preset.Topics)
+	assert.NotNil(t, preset.Private)
+	assert.EqualValues(t, "https://docs.example.com/hook", preset.Webhooks[0].Url)
+
+	ioutil.WriteFile(path, []byte("presets:\n  - name: docs\n  - name: docs\n"), 0600)
+	_, err = LoadPresets(path)
+	assert.Contains(t, err.Error(), "preset docs is defined more than once")
+
+	ioutil.WriteFile(path, []byte("presets:\n  - name: library\n    branch_protection:\n      - required_approving_reviews: 1\n"), 0600)
+	_, err = LoadPresets(path)
+	assert.Contains(t, err.Error(), "preset library: branch_protection[0].branch branch is required")
+}
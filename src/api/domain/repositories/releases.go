@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxReleaseNameLength = 255
+	maxReleaseBodyLength = 125000
+)
+
+var (
+	validSha          = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	invalidRefPattern = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]|\.\.|@\{|//`)
+)
+
+// CreateReleaseRequest publishes a release of TagName, creating the tag from
+// Target, a branch or a commit sha, when it does not exist yet.
+type CreateReleaseRequest struct {
+	TagName              string `json:"tag_name"`
+	Target               string `json:"target,omitempty"`
+	Name                 string `json:"name,omitempty"`
+	Body                 string `json:"body,omitempty"`
+	Draft                bool   `json:"draft"`
+	Prerelease           bool   `json:"prerelease"`
+	GenerateReleaseNotes bool   `json:"generate_release_notes"`
+}
+
+func (r *CreateReleaseRequest) Validate() errors.ApiError {
+	r.TagName = strings.TrimSpace(r.TagName)
+	r.Target = strings.TrimSpace(r.Target)
+
+	result := validateRefName("tag_name", r.TagName)
+	if r.Target != "" {
+		result = append(result, validateRefName("target", r.Target)...)
+	}
+	if len([]rune(r.Name)) > maxReleaseNameLength {
+		result = append(result, FieldError{Field: "name", Message: fmt.Sprintf("name must be at most %d characters long", maxReleaseNameLength)})
+	}
+	if len([]rune(r.Body)) > maxReleaseBodyLength {
+		result = append(result, FieldError{Field: "body", Message: fmt.Sprintf("body must be at most %d characters long", maxReleaseBodyLength)})
+	}
+	return validationError("invalid release request", result)
+}
+
+// CreateTagRequest creates the tag Name on commit Sha. Tags with a Message
+// are annotated, the others lightweight.
+type CreateTagRequest struct {
+	Name    string `json:"name"`
+	Sha     string `json:"sha"`
+	Message string `json:"message,omitempty"`
+}
+
+func (r *CreateTagRequest) Validate() errors.ApiError {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Sha = strings.ToLower(strings.TrimSpace(r.Sha))
+
+	result := validateRefName("name", r.Name)
+	if !validSha.MatchString(r.Sha) {
+		result = append(result, FieldError{Field: "sha", Message: "sha must be a full 40 characters commit sha"})
+	}
+	return validationError("invalid tag request", result)
+}
+
+type Release struct {
+	Id          int64          `json:"id"`
+	TagName     string         `json:"tag_name"`
+	Target      string         `json:"target"`
+	Name        string         `json:"name"`
+	Body        string         `json:"body"`
+	Draft       bool           `json:"draft"`
+	Prerelease  bool           `json:"prerelease"`
+	Url         string         `json:"url"`
+	CreatedAt   time.Time      `json:"created_at"`
+	PublishedAt *time.Time     `json:"published_at,omitempty"`
+	Assets      []ReleaseAsset `json:"assets"`
+}
+
+type ReleaseAsset struct {
+	Id          int64  `json:"id"`
+	Name        string `json:"name"`
+	Label       string `json:"label,omitempty"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	DownloadUrl string `json:"download_url"`
+}
+
+// UploadAssetRequest describes an asset whose Size bytes are streamed from
+// the request body.
+type UploadAssetRequest struct {
+	Name        string
+	Label       string
+	ContentType string
+	Size        int64
+}
+
+func (r *UploadAssetRequest) Validate() errors.ApiError {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.ContentType == "" {
+		r.ContentType = "application/octet-stream"
+	}
+
+	result := make([]FieldError, 0)
+	if r.Name == "" {
+		result = append(result, FieldError{Field: "name", Message: "name is required"})
+	} else if strings.ContainsAny(r.Name, "/\\") {
+		result = append(result, FieldError{Field: "name", Message: "name can not contain path separators"})
+	}
+	if r.Size <= 0 {
+		result = append(result, FieldError{Field: "size", Message: "asset can not be empty"})
+	}
+	return validationError("invalid asset", result)
+}
+
+type Tag struct {
+	Name string `json:"name"`
+	Sha  string `json:"sha"`
+}
+
+// validateRefName checks the few git reference name rules that matter for
+// tags and branches.
+func validateRefName(field string, name string) []FieldError {
+	result := make([]FieldError, 0)
+	switch {
+	case name == "":
+		result = append(result, FieldError{Field: field, Message: fmt.Sprintf("%s is required", field)})
+	case invalidRefPattern.MatchString(name) || name == "@" ||
+		strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") ||
+		strings.HasSuffix(name, ".") || strings.HasSuffix(name, ".lock"):
+		result = append(result, FieldError{Field: field, Message: fmt.Sprintf("%s '%s' is not a valid git reference name", field, name)})
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestCreateReleaseRequestValidate(t *testing.T) {
+	request := CreateReleaseRequest{TagName: " v1.0.0 ", Target: "main"}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, "v1.0.0", request.TagName)
+
+	request = CreateReleaseRequest{Target: "feature..x"}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, []interface{}{
+		FieldError{Field: "tag_name", Message: "tag_name is required"},
+		FieldError{Field: "target", Message: "target 'feature..x' is not a valid git reference name"},
+	}, err.Causes())
+}
+
+func TestValidateRefName(t *testing.T) {
+	for _, name := range []string{"v1.0.0", "release/2021-10", "v1.0.0-rc.1"} {
+		assert.EqualValues(t, 0, len(validateRefName("tag", name)), name)
+	}
+	for _, name := range []string{"v 1", "v1.lock", "/v1", "v1/", "v1.", "v1~1", "v1^", "a:b", "@", "v@{1}", "a//b"} {
+		assert.EqualValues(t, 1, len(validateRefName("tag", name)), name)
+	}
+}
+
+func TestCreateTagRequestValidate(t *testing.T) {
+	request := CreateTagRequest{Name: "v1", Sha: "ABCDEF0123456789ABCDEF0123456789ABCDEF01"}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, "abcdef0123456789abcdef0123456789abcdef01", request.Sha)
+
+	request = CreateTagRequest{Name: "v1", Sha: "abcdef0"}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []interface{}{FieldError{Field: "sha", Message: "sha must be a full 40 characters commit sha"}}, err.Causes())
+}
+
+func TestUploadAssetRequestValidate(t *testing.T) {
+	request := UploadAssetRequest{Name: "app.tar.gz", Size: 10}
+	assert.Nil(t, request.Validate())
+	assert.EqualValues(t, "application/octet-stream", request.ContentType)
+
+	request = UploadAssetRequest{Name: "../app", Size: 0}
+	err := request.Validate()
+	assert.NotNil(t, err)
+	assert.EqualValues(t, 2, len(err.Causes()))
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/scaffold"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	MaxRequiredReviews = 6
+
+	maxTopics = 20
+
+	WebhookContentTypeJson = "json"
+	WebhookContentTypeForm = "form"
+)
+
+var (
+	validTopic = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+)
+
+// RepoSettings are applied to a repository right after it is created. A
+// preset provides them and every field set in a request replaces the one of
+// its preset; an empty list, unlike a missing one, clears the preset's list.
+type RepoSettings struct {
+	Private           *bool              `json:"private,omitempty" yaml:"private"`
+	LicenseTemplate   string             `json:"license_template,omitempty" yaml:"license_template"`
+	GitignoreTemplate string             `json:"gitignore_template,omitempty" yaml:"gitignore_template"`
+	Topics            []string           `json:"topics,omitempty" yaml:"topics"`
+	BranchProtection  []BranchProtection `json:"branch_protection,omitempty" yaml:"branch_protection"`
+	Webhooks          []Webhook          `json:"webhooks,omitempty" yaml:"webhooks"`
+	Labels            []Label            `json:"labels,omitempty" yaml:"labels"`
+	DeployKeys        []DeployKeyRequest `json:"deploy_keys,omitempty" yaml:"deploy_keys"`
+	Secrets           []ActionsSecret    `json:"secrets,omitempty" yaml:"secrets"`
+	// Scaffold lists the files committed from the configured templates, by
+	// their path in the repository.
+	Scaffold []string `json:"scaffold,omitempty" yaml:"scaffold"`
+}
+
+type BranchProtection struct {
+	Branch                   string   `json:"branch" yaml:"branch"`
+	RequiredApprovingReviews int      `json:"required_approving_reviews" yaml:"required_approving_reviews"`
+	EnforceAdmins            bool     `json:"enforce_admins" yaml:"enforce_admins"`
+	RequiredStatusChecks     []string `json:"required_status_checks,omitempty" yaml:"required_status_checks"`
+}
+
+type Webhook struct {
+	Url         string   `json:"url" yaml:"url"`
+	ContentType string   `json:"content_type,omitempty" yaml:"content_type"`
+	Events      []string `json:"events,omitempty" yaml:"events"`
+	Secret      string   `json:"secret,omitempty" yaml:"secret"`
+}
+
+// IsPrivate reports the visibility, public unless told otherwise.
+func (s RepoSettings) IsPrivate() bool {
+	return s.Private != nil && *s.Private
+}
+
+// IsZero reports whether no setting is set at all.
+func (s RepoSettings) IsZero() bool {
+	return s.Private == nil && s.LicenseTemplate == "" && s.GitignoreTemplate == "" &&
+		s.Topics == nil && s.BranchProtection == nil && s.Webhooks == nil && s.Labels == nil && s.Scaffold == nil &&
+		s.DeployKeys == nil && s.Secrets == nil
+}
+
+// Merge returns s with every field set in overrides replaced.
+func (s RepoSettings) Merge(overrides RepoSettings) RepoSettings {
+	if overrides.Private != nil {
+		s.Private = overrides.Private
+	}
+	if overrides.LicenseTemplate != "" {
+		s.LicenseTemplate = overrides.LicenseTemplate
+	}
+	if overrides.GitignoreTemplate != "" {
+		s.GitignoreTemplate = overrides.GitignoreTemplate
+	}
+	if overrides.Topics != nil {
+		s.Topics = overrides.Topics
+	}
+	if overrides.BranchProtection != nil {
+		s.BranchProtection = overrides.BranchProtection
+	}
+	if overrides.Webhooks != nil {
+		s.Webhooks = overrides.Webhooks
+	}
+	if overrides.Labels != nil {
+		s.Labels = overrides.Labels
+	}
+	if overrides.Scaffold != nil {
+		s.Scaffold = overrides.Scaffold
+	}
+	if overrides.DeployKeys != nil {
+		s.DeployKeys = overrides.DeployKeys
+	}
+	if overrides.Secrets != nil {
+		s.Secrets = overrides.Secrets
+	}
+	return s
+}
+
+// Redacted returns a copy without webhook secrets nor Actions secret values,
+// safe to be echoed back or stored.
+func (s RepoSettings) Redacted() RepoSettings {
+	if s.Webhooks != nil {
+		webhooks := make([]Webhook, len(s.Webhooks))
+		for index, webhook := range s.Webhooks {
+			webhook.Secret = ""
+			webhooks[index] = webhook
+		}
+		s.Webhooks = webhooks
+	}
+	if s.Secrets != nil {
+		secrets := make([]ActionsSecret, len(s.Secrets))
+		for index, secret := range s.Secrets {
+			secrets[index] = ActionsSecret{Name: secret.Name}
+		}
+		s.Secrets = secrets
+	}
+	return s
+}
+
+// NeedsInitialCommit reports whether the repository must be created with a
+// default branch for the settings to be applied. Scaffolded files create it
+// with their first commit.
+func (s RepoSettings) NeedsInitialCommit() bool {
+	return s.LicenseTemplate != "" || s.GitignoreTemplate != "" ||
+		(len(s.BranchProtection) > 0 && len(s.Scaffold) == 0)
+}
+
+func (s RepoSettings) validate() []FieldError {
+	result := ValidateTopics(s.Topics, "topics")
+	result = append(result, ValidateBranchProtection(s.BranchProtection, "branch_protection")...)
+	result = append(result, ValidateLabels(NormalizeLabels(s.Labels), "labels")...)
+	result = append(result, validateDeployKeys(s.DeployKeys, "deploy_keys")...)
+	result = append(result, validateSecrets(NormalizeSecrets(s.Secrets), "secrets")...)
+
+	for index, webhook := range s.Webhooks {
+		path := fmt.Sprintf("webhooks[%d]", index)
+		target, err := url.Parse(webhook.Url)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			result = append(result, FieldError{Field: path + ".url", Message: "url must be an absolute http or https url"})
+		}
+		if webhook.ContentType != "" && webhook.ContentType != WebhookContentTypeJson && webhook.ContentType != WebhookContentTypeForm {
+			result = append(result, FieldError{Field: path + ".content_type", Message: "content type must be json or form"})
+		}
+	}
+
+	files := make(map[string]bool)
+	for index, path := range s.Scaffold {
+		current := fmt.Sprintf("scaffold[%d]", index)
+		switch {
+		case !scaffold.TemplateRegistry.Exists(path):
+			result = append(result, FieldError{Field: current, Message: fmt.Sprintf("unknown scaffold template '%s'", path)})
+		case files[path]:
+			result = append(result, FieldError{Field: current, Message: fmt.Sprintf("file '%s' is scaffolded more than once", path)})
+		}
+		files[path] = true
+	}
+	return result
+}
+
+// ValidateTopics checks the topics as GitHub does once they are lower cased.
+// Fields are prefixed with path.
+func ValidateTopics(topics []string, path string) []FieldError {
+	result := make([]FieldError, 0)
+	if len(topics) > maxTopics {
+		result = append(result, FieldError{Field: path, Message: fmt.Sprintf("at most %d topics are allowed", maxTopics)})
+	}
+	for index, topic := range topics {
+		if !validTopic.MatchString(strings.ToLower(strings.TrimSpace(topic))) {
+			result = append(result, FieldError{
+				Field:   fmt.Sprintf("%s[%d]", path, index),
+				Message: "topics must start with a letter or digit, contain only letters, digits and '-' and be at most 50 characters long",
+			})
+		}
+	}
+	return result
+}
+
+// ValidateBranchProtection checks that every branch is named, protected only
+// once and requires a number of reviews GitHub accepts. Fields are prefixed
+// with path.
+func ValidateBranchProtection(protections []BranchProtection, path string) []FieldError {
+	result := make([]FieldError, 0)
+	branches := make(map[string]bool)
+	for index, protection := range protections {
+		current := fmt.Sprintf("%s[%d]", path, index)
+		switch {
+		case protection.Branch == "":
+			result = append(result, FieldError{Field: current + ".branch", Message: "branch is required"})
+		case branches[protection.Branch]:
+			result = append(result, FieldError{Field: current + ".branch", Message: fmt.Sprintf("branch '%s' is protected more than once", protection.Branch)})
+		}
+		branches[protection.Branch] = true
+
+		if protection.RequiredApprovingReviews < 0 || protection.RequiredApprovingReviews > MaxRequiredReviews {
+			result = append(result, FieldError{
+				Field:   current + ".required_approving_reviews",
+				Message: fmt.Sprintf("required approving reviews must be between 0 and %d", MaxRequiredReviews),
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	maxLoginLength = 39
+)
+
+var (
+	validLogin = regexp.MustCompile(`^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$`)
+)
+
+// TransferRepoRequest moves a repository to another user or organization,
+// optionally renaming it and granting teams of the new owner access to it.
+type TransferRepoRequest struct {
+	NewOwner string  `json:"new_owner"`
+	NewName  string  `json:"new_name,omitempty"`
+	TeamIds  []int64 `json:"team_ids,omitempty"`
+}
+
+func (r *TransferRepoRequest) Validate() errors.ApiError {
+	r.NewOwner = strings.TrimSpace(r.NewOwner)
+	r.NewName = strings.TrimSpace(r.NewName)
+
+	result := make([]FieldError, 0)
+	switch {
+	case r.NewOwner == "":
+		result = append(result, FieldError{Field: "new_owner", Message: "new_owner is required"})
+	case len(r.NewOwner) > maxLoginLength || !validLogin.MatchString(r.NewOwner):
+		result = append(result, FieldError{Field: "new_owner", Message: fmt.Sprintf("new_owner must be a GitHub login of at most %d letters, digits or single hyphens", maxLoginLength)})
+	}
+	if r.NewName != "" {
+		result = append(result, validateName("new_name", r.NewName)...)
+	}
+	return validationError("invalid transfer request", result)
+}
+
+// TransferRepoResponse describes the repository once GitHub has accepted to
+// transfer it, which happens in the background.
+type TransferRepoResponse struct {
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+	NewOwner string `json:"new_owner"`
+	NewName  string `json:"new_name"`
+}
@@ -0,0 +1,117 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	templateSuffix = ".tmpl"
+)
+
+var (
+	TemplateRegistry templateRegistryInterface
+)
+
+func init() {
+	registry, err := LoadTemplates(config.GetScaffoldTemplatesDir())
+	if err != nil {
+		panic(err)
+	}
+	TemplateRegistry = registry
+}
+
+// Data is what templates can refer to, such as {{.Name}} or {{.Year}}.
+type Data struct {
+	Owner       string
+	Name        string
+	FullName    string
+	Description string
+	Homepage    string
+	Private     bool
+	Topics      []string
+	ClientId    string
+	Preset      string
+	Year        int
+}
+
+type templateRegistryInterface interface {
+	Paths() []string
+	Exists(path string) bool
+	Render(path string, data Data) ([]byte, error)
+}
+
+type templateRegistry struct {
+	templates map[string]*template.Template
+}
+
+// LoadTemplates parses every file below dir as a Go text/template. The path
+// of the file relative to dir, without a .tmpl suffix, is the path it is
+// committed to, e.g. .github/workflows/ci.yml.tmpl. An empty dir returns a
+// registry without templates.
+func LoadTemplates(dir string) (templateRegistryInterface, error) {
+	result := &templateRegistry{templates: make(map[string]*template.Template)}
+	if dir == "" {
+		return result, nil
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(relative), templateSuffix)
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		parsed, err := template.New(name).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("invalid scaffold template %s: %w", relative, err)
+		}
+		result.templates[name] = parsed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Paths returns the path of every template, sorted.
+func (r *templateRegistry) Paths() []string {
+	result := make([]string, 0, len(r.templates))
+	for path := range r.templates {
+		result = append(result, path)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (r *templateRegistry) Exists(path string) bool {
+	_, exists := r.templates[path]
+	return exists
+}
+
+func (r *templateRegistry) Render(path string, data Data) ([]byte, error) {
+	current, exists := r.templates[path]
+	if !exists {
+		return nil, fmt.Errorf("unknown scaffold template %s", path)
+	}
+	var result bytes.Buffer
+	if err := current.Execute(&result, data); err != nil {
+		return nil, err
+	}
+	return result.Bytes(), nil
+}
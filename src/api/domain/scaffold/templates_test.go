@@ -0,0 +1,65 @@
+package scaffold
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTemplate(t *testing.T, dir string, path string, content string) {
+	full := filepath.Join(dir, filepath.FromSlash(path))
+	assert.Nil(t, os.MkdirAll(filepath.Dir(full), 0700))
+	assert.Nil(t, ioutil.WriteFile(full, []byte(content), 0600))
+}
+
+func TestLoadTemplates(t *testing.T) {
+	registry, err := LoadTemplates("")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{}, registry.Paths())
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "README.md.tmpl", "# {{.Name}}\n\n{{.Description}}\n")
+	writeTemplate(t, dir, "CODEOWNERS", "* @{{.Owner}}\n")
+	writeTemplate(t, dir, ".github/workflows/ci.yml.tmpl", "name: ci\n")
+
+	registry, err = LoadTemplates(dir)
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{".github/workflows/ci.yml", "CODEOWNERS", "README.md"}, registry.Paths())
+	assert.True(t, registry.Exists("README.md"))
+	assert.False(t, registry.Exists("README.md.tmpl"))
+
+	content, err := registry.Render("README.md", Data{Name: "api", Description: "The api"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "# api\n\nThe api\n", string(content))
+
+	content, err = registry.Render("CODEOWNERS", Data{Owner: "EBKopec"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "* @EBKopec\n", string(content))
+
+	_, err = registry.Render("LICENSE", Data{})
+	assert.EqualValues(t, "unknown scaffold template LICENSE", err.Error())
+}
+
+func TestLoadTemplatesInvalid(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "README.md.tmpl", "# {{.Name")
+
+	registry, err := LoadTemplates(dir)
+	assert.Nil(t, registry)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid scaffold template README.md.tmpl")
+
+	_, err = LoadTemplates(filepath.Join(dir, "missing"))
+	assert.NotNil(t, err)
+}
+
+func TestRenderUnknownField(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "README.md", "{{.Team}}")
+	registry, _ := LoadTemplates(dir)
+
+	_, err := registry.Render("README.md", Data{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,90 @@
+package github_provider
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"net/http"
+	"time"
+)
+
+const (
+	headerBearerFormat = "Bearer %s"
+
+	urlInstallationToken = "https://api.github.com/app/installations/%d/access_tokens"
+
+	// GitHub rejects app tokens valid for more than 10 minutes and advises to
+	// backdate them against clock drift.
+	appJwtLifetime = 9 * time.Minute
+	appJwtBackdate = time.Minute
+)
+
+// CreateInstallationToken exchanges the credentials of a GitHub App for a
+// token of one of its installations, valid for about an hour.
+func CreateInstallationToken(appId int64, privateKey string, installationId int64) (*github.InstallationToken, *github.GithubErrorResponse) {
+	jwt, err := newAppJwt(appId, privateKey, time.Now())
+	if err != nil {
+		return nil, &github.GithubErrorResponse{
+			StatusCode: http.StatusInternalServerError,
+			Message:    fmt.Sprintf("error when trying to sign github app token: %s", err.Error()),
+		}
+	}
+
+	var result github.InstallationToken
+	url := fmt.Sprintf(urlInstallationToken, installationId)
+	if err := sendJsonWithAuthorization(fmt.Sprintf(headerBearerFormat, jwt), http.MethodPost, url, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// newAppJwt returns the RS256 JSON Web Token authenticating as the app.
+func newAppJwt(appId int64, privateKey string, now time.Time) (string, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appJwtBackdate).Unix(),
+		"exp": now.Add(appJwtLifetime).Unix(),
+		"iss": fmt.Sprintf("%d", appId),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey accepts the PKCS#1 keys GitHub generates as well as PKCS#8.
+func parsePrivateKey(privateKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.New("private key is not a valid RSA key")
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not a valid RSA key")
+	}
+	return rsaKey, nil
+}
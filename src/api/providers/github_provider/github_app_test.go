@@ -0,0 +1,81 @@
+package github_provider
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newPrivateKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	encoded := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, string(encoded)
+}
+
+func TestNewAppJwt(t *testing.T) {
+	key, encoded := newPrivateKey(t)
+	now := time.Unix(1633046400, 0)
+
+	jwt, err := newAppJwt(42, encoded, now)
+	assert.Nil(t, err)
+	parts := strings.Split(jwt, ".")
+	assert.EqualValues(t, 3, len(parts))
+
+	claimsJson, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]interface{}
+	json.Unmarshal(claimsJson, &claims)
+	assert.EqualValues(t, "42", claims["iss"])
+	assert.EqualValues(t, 1633046340, claims["iat"])
+	assert.EqualValues(t, 1633046940, claims["exp"])
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.Nil(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+}
+
+func TestNewAppJwtInvalidKey(t *testing.T) {
+	_, err := newAppJwt(42, "not a key", time.Now())
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "private key is not PEM encoded", err.Error())
+
+	_, err = newAppJwt(42, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("garbage")})), time.Now())
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "private key is not a valid RSA key", err.Error())
+}
+
+func TestCreateInstallationToken(t *testing.T) {
+	_, encoded := newPrivateKey(t)
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/app/installations/7/access_tokens",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"token": "ghs_abc","expires_at": "2021-10-01T01:00:00Z"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+
+	token, err := CreateInstallationToken(42, encoded, 7)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghs_abc", token.Token)
+	assert.EqualValues(t, time.Date(2021, 10, 1, 1, 0, 0, 0, time.UTC), token.ExpiresAt)
+}
+
+func TestCreateInstallationTokenInvalidKey(t *testing.T) {
+	token, err := CreateInstallationToken(42, "not a key", 7)
+	assert.Nil(t, token)
+	assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode)
+	assert.EqualValues(t, "error when trying to sign github app token: private key is not PEM encoded", err.Message)
+}
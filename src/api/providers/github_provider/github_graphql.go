@@ -0,0 +1,136 @@
+package github_provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"net/http"
+	"strings"
+)
+
+const (
+	urlGraphql = "https://api.github.com/graphql"
+
+	// GitHub rejects queries that could return more than 500,000 nodes, and a
+	// single query with hundreds of top level fields times out well before
+	// that. Bulk reads are cut in queries of at most graphqlMaxAliases fields.
+	graphqlMaxAliases = 100
+)
+
+// Query sends a GraphQL query and decodes its data into target, when set.
+// GitHub resolves what it can: the errors of the fields it could not resolve
+// are returned along with the data. Only a query that resolved nothing fails.
+func Query(accessToken string, request github.GraphqlRequest, target interface{}) ([]github.GraphqlError, *github.GithubErrorResponse) {
+	var response github.GraphqlResponse
+	if err := sendJson(accessToken, http.MethodPost, urlGraphql, request, &response); err != nil {
+		return nil, err
+	}
+
+	data := bytes.TrimSpace(response.Data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		if len(response.Errors) == 0 {
+			return nil, &github.GithubErrorResponse{
+				StatusCode: http.StatusBadGateway,
+				Message:    "github graphql response has no data",
+			}
+		}
+		return nil, getGraphqlErrorResponse(response.Errors[0])
+	}
+
+	if target != nil {
+		if err := json.Unmarshal(data, target); err != nil {
+			return nil, &github.GithubErrorResponse{
+				StatusCode: http.StatusInternalServerError,
+				Message:    "error when trying to unmarshal github graphql response",
+			}
+		}
+	}
+	return response.Errors, nil
+}
+
+// RepositoriesExist tells, for every ref, whether the repository exists and
+// is visible to the token. Refs are looked up graphqlMaxAliases at a time and
+// the lookup stops before a query the rate limit would refuse.
+func RepositoriesExist(accessToken string, refs []github.RepositoryRef) ([]bool, *github.GithubErrorResponse) {
+	result := make([]bool, 0, len(refs))
+	for start := 0; start < len(refs); start += graphqlMaxAliases {
+		end := start + graphqlMaxAliases
+		if end > len(refs) {
+			end = len(refs)
+		}
+
+		exist, rateLimit, err := repositoriesExist(accessToken, refs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, exist...)
+
+		if end < len(refs) && rateLimit.Remaining < rateLimit.Cost {
+			return nil, &github.GithubErrorResponse{
+				StatusCode: http.StatusForbidden,
+				Message:    fmt.Sprintf("github graphql rate limit exceeded until %s", rateLimit.ResetAt.Format("15:04:05 MST")),
+			}
+		}
+	}
+	return result, nil
+}
+
+func repositoriesExist(accessToken string, refs []github.RepositoryRef) ([]bool, github.GraphqlRateLimit, *github.GithubErrorResponse) {
+	request := github.GraphqlRequest{Variables: make(map[string]interface{})}
+	declarations := make([]string, 0, 2*len(refs))
+	fields := make([]string, 0, len(refs)+1)
+	for index, ref := range refs {
+		declarations = append(declarations, fmt.Sprintf("$o%d: String!, $n%d: String!", index, index))
+		fields = append(fields, fmt.Sprintf("r%d: repository(owner: $o%d, name: $n%d) { id }", index, index, index))
+		request.Variables[fmt.Sprintf("o%d", index)] = ref.Owner
+		request.Variables[fmt.Sprintf("n%d", index)] = ref.Name
+	}
+	fields = append(fields, "rateLimit { cost remaining nodeCount resetAt }")
+	request.Query = fmt.Sprintf("query(%s) {\n%s\n}", strings.Join(declarations, ", "), strings.Join(fields, "\n"))
+
+	var data map[string]json.RawMessage
+	errs, err := Query(accessToken, request, &data)
+	if err != nil {
+		return nil, github.GraphqlRateLimit{}, err
+	}
+	for _, graphqlErr := range errs {
+		if graphqlErr.Type != github.GraphqlErrorNotFound {
+			return nil, github.GraphqlRateLimit{}, getGraphqlErrorResponse(graphqlErr)
+		}
+	}
+
+	var rateLimit github.GraphqlRateLimit
+	if raw, ok := data["rateLimit"]; ok {
+		_ = json.Unmarshal(raw, &rateLimit)
+	}
+
+	result := make([]bool, len(refs))
+	for index := range refs {
+		repo := bytes.TrimSpace(data[fmt.Sprintf("r%d", index)])
+		result[index] = len(repo) > 0 && !bytes.Equal(repo, []byte("null"))
+	}
+	return result, rateLimit, nil
+}
+
+// getGraphqlErrorResponse maps a GraphQL error to the status the REST API
+// would have answered, so callers handle both the same way.
+func getGraphqlErrorResponse(err github.GraphqlError) *github.GithubErrorResponse {
+	statusCode := http.StatusBadGateway
+	message := err.Message
+	switch err.Type {
+	case github.GraphqlErrorNotFound:
+		statusCode = http.StatusNotFound
+	case github.GraphqlErrorForbidden:
+		statusCode = http.StatusForbidden
+	case github.GraphqlErrorRateLimited:
+		statusCode = http.StatusForbidden
+		if !strings.Contains(strings.ToLower(message), "rate limit") {
+			message = fmt.Sprintf("github graphql rate limit exceeded: %s", message)
+		}
+	}
+	return &github.GithubErrorResponse{
+		StatusCode: statusCode,
+		Message:    message,
+	}
+}
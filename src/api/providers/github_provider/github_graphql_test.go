@@ -0,0 +1,116 @@
+package github_provider
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func addGraphqlMock(body string) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/graphql",
+		HttpMethod: http.MethodPost,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+		BodyText:   body,
+	})
+}
+
+func TestQueryDecodesData(t *testing.T) {
+	addGraphqlMock(`{"data": {"viewer": {"login": "EBKopec"}}}`)
+
+	var result struct {
+		Viewer struct {
+			Login string `json:"login"`
+		} `json:"viewer"`
+	}
+	errs, err := Query("token", github.GraphqlRequest{Query: "{ viewer { login } }"}, &result)
+
+	assert.Nil(t, err)
+	assert.Empty(t, errs)
+	assert.EqualValues(t, "EBKopec", result.Viewer.Login)
+}
+
+func TestQueryNoData(t *testing.T) {
+	addGraphqlMock(`{"data": null, "errors": [{"type": "FORBIDDEN", "message": "Resource protected by organization SAML enforcement."}]}`)
+
+	errs, err := Query("token", github.GraphqlRequest{Query: "{ viewer { login } }"}, nil)
+
+	assert.Nil(t, errs)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualValues(t, "Resource protected by organization SAML enforcement.", err.Message)
+}
+
+func TestQueryRateLimited(t *testing.T) {
+	addGraphqlMock(`{"errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded for user ID 1."}]}`)
+
+	_, err := Query("token", github.GraphqlRequest{Query: "{ viewer { login } }"}, nil)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualValues(t, "API rate limit exceeded for user ID 1.", err.Message)
+}
+
+func TestQueryHttpError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/graphql",
+		HttpMethod: http.MethodPost,
+		Response:   &http.Response{StatusCode: http.StatusUnauthorized},
+		BodyText:   `{"message": "Bad credentials"}`,
+	})
+
+	_, err := Query("token", github.GraphqlRequest{Query: "{ viewer { login } }"}, nil)
+
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.StatusCode)
+	assert.EqualValues(t, "Bad credentials", err.Message)
+}
+
+func TestRepositoriesExist(t *testing.T) {
+	addGraphqlMock(`{
+		"data": {"r0": {"id": "R_1"}, "r1": null, "rateLimit": {"cost": 1, "remaining": 4999}},
+		"errors": [{"type": "NOT_FOUND", "path": ["r1"], "message": "Could not resolve to a Repository with the name 'EBKopec/missing'."}]
+	}`)
+
+	result, err := RepositoriesExist("token", []github.RepositoryRef{
+		{Owner: "EBKopec", Name: "api"},
+		{Owner: "EBKopec", Name: "missing"},
+	})
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, []bool{true, false}, result)
+}
+
+func TestRepositoriesExistUnexpectedError(t *testing.T) {
+	addGraphqlMock(`{
+		"data": {"r0": null, "rateLimit": {"cost": 1, "remaining": 4999}},
+		"errors": [{"type": "FORBIDDEN", "path": ["r0"], "message": "Resource not accessible by integration"}]
+	}`)
+
+	result, err := RepositoriesExist("token", []github.RepositoryRef{{Owner: "EBKopec", Name: "api"}})
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+}
+
+func TestRepositoriesExistStopsBeforeRateLimit(t *testing.T) {
+	addGraphqlMock(`{"data": {"r0": null, "rateLimit": {"cost": 1, "remaining": 0, "resetAt": "2021-10-01T12:00:00Z"}}}`)
+
+	refs := make([]github.RepositoryRef, graphqlMaxAliases+1)
+	result, err := RepositoriesExist("token", refs)
+
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualValues(t, "github graphql rate limit exceeded until 12:00:00 UTC", err.Message)
+}
+
+func TestGraphqlErrorField(t *testing.T) {
+	assert.EqualValues(t, "r1", github.GraphqlError{Path: []interface{}{"r1", "id"}}.Field())
+	assert.EqualValues(t, "", github.GraphqlError{}.Field())
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
 	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 )
 
 const (
@@ -15,6 +17,30 @@ const (
 	headerAuthorizationFormat = "token %s"
 
 	urlCreateRepo = "https://api.github.com/user/repos"
+	urlRepo       = "https://api.github.com/repos/%s/%s"
+	urlUser       = "https://api.github.com/user"
+
+	urlTopics           = urlRepo + "/topics"
+	urlCollaborators    = urlRepo + "/collaborators?affiliation=direct&per_page=100"
+	urlCollaborator     = urlRepo + "/collaborators/%s"
+	urlBranchProtection = urlRepo + "/branches/%s/protection"
+	urlHooks            = urlRepo + "/hooks"
+	urlContents         = urlRepo + "/contents/%s"
+	urlLabels           = urlRepo + "/labels"
+	urlLabel            = urlRepo + "/labels/%s"
+	urlTransfer         = urlRepo + "/transfer"
+	urlReleases         = urlRepo + "/releases"
+	urlRelease          = urlRepo + "/releases/%d"
+	urlReleaseByTag     = urlRepo + "/releases/tags/%s"
+	urlTags             = urlRepo + "/tags?per_page=100"
+	urlGitTags          = urlRepo + "/git/tags"
+	urlGitRefs          = urlRepo + "/git/refs"
+	urlDeployKeys       = urlRepo + "/keys"
+	urlActionsPublicKey = urlRepo + "/actions/secrets/public-key"
+	urlActionsSecret    = urlRepo + "/actions/secrets/%s"
+	urlReleaseAssets    = "https://uploads.github.com/repos/%s/%s/releases/%d/assets?%s"
+
+	headerContentType = "Content-Type"
 )
 
 func getAuthorizationHeader(accessToken string) string {
@@ -45,15 +71,7 @@ func CreateRepo(accessToken string, request github.CreateRepoRequest) (*github.C
 	defer response.Body.Close()
 
 	if response.StatusCode > 299 {
-		var errResponse github.GithubErrorResponse
-		if err := json.Unmarshal(bytes, &errResponse); err != nil {
-			return nil, &github.GithubErrorResponse{
-				StatusCode: http.StatusInternalServerError,
-				Message: "invalid json response body",
-			}
-		}
-		errResponse.StatusCode = response.StatusCode
-		return nil, &errResponse
+		return nil, getErrorResponse(response.StatusCode, bytes)
 	}
 
 	var result github.CreateRepoResponse
@@ -67,4 +85,317 @@ func CreateRepo(accessToken string, request github.CreateRepoRequest) (*github.C
 
 	return &result, nil
 
-}
\ No newline at end of file
+}
+
+func DeleteRepo(accessToken string, owner string, name string) *github.GithubErrorResponse {
+	headers := http.Header{}
+	headers.Set(headerAuthorization, getAuthorizationHeader(accessToken))
+
+	response, err := restclient.Delete(fmt.Sprintf(urlRepo, owner, name), headers)
+	if err != nil {
+		log.Println(fmt.Sprintf("error when trying to delete repo %s/%s in github: %s", owner, name, err.Error()))
+		return &github.GithubErrorResponse{
+			StatusCode: http.StatusInternalServerError,
+			Message:    err.Error()}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode > 299 {
+		bytes, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return &github.GithubErrorResponse{
+				StatusCode: http.StatusInternalServerError,
+				Message:    "invalid response body",
+			}
+		}
+		return getErrorResponse(response.StatusCode, bytes)
+	}
+	return nil
+}
+
+func GetRepo(accessToken string, owner string, name string) (*github.Repository, *github.GithubErrorResponse) {
+	var result github.Repository
+	if err := getJson(accessToken, fmt.Sprintf(urlRepo, owner, name), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func GetAuthenticatedUser(accessToken string) (*github.User, *github.GithubErrorResponse) {
+	var result github.User
+	if err := getJson(accessToken, urlUser, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func UpdateRepo(accessToken string, owner string, name string, request github.UpdateRepoRequest) (*github.Repository, *github.GithubErrorResponse) {
+	var result github.Repository
+	if err := sendJson(accessToken, http.MethodPatch, fmt.Sprintf(urlRepo, owner, name), request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TransferRepo asks GitHub to move the repository to request.NewOwner. The
+// transfer completes in the background.
+func TransferRepo(accessToken string, owner string, name string, request github.TransferRepoRequest) (*github.Repository, *github.GithubErrorResponse) {
+	var result github.Repository
+	if err := sendJson(accessToken, http.MethodPost, fmt.Sprintf(urlTransfer, owner, name), request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func GetTopics(accessToken string, owner string, name string) ([]string, *github.GithubErrorResponse) {
+	var result github.Topics
+	if err := getJson(accessToken, fmt.Sprintf(urlTopics, owner, name), &result); err != nil {
+		return nil, err
+	}
+	return result.Names, nil
+}
+
+func ReplaceTopics(accessToken string, owner string, name string, topics []string) *github.GithubErrorResponse {
+	return sendJson(accessToken, http.MethodPut, fmt.Sprintf(urlTopics, owner, name), github.Topics{Names: topics}, nil)
+}
+
+// GetCollaborators returns the first 100 direct collaborators of the repository.
+func GetCollaborators(accessToken string, owner string, name string) ([]github.Collaborator, *github.GithubErrorResponse) {
+	result := make([]github.Collaborator, 0)
+	if err := getJson(accessToken, fmt.Sprintf(urlCollaborators, owner, name), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AddCollaborator invites login to the repository, or changes the permission
+// of an existing collaborator.
+func AddCollaborator(accessToken string, owner string, name string, login string, permission string) *github.GithubErrorResponse {
+	request := github.AddCollaboratorRequest{Permission: permission}
+	return sendJson(accessToken, http.MethodPut, fmt.Sprintf(urlCollaborator, owner, name, login), request, nil)
+}
+
+func GetBranchProtection(accessToken string, owner string, name string, branch string) (*github.BranchProtection, *github.GithubErrorResponse) {
+	var result github.BranchProtection
+	if err := getJson(accessToken, fmt.Sprintf(urlBranchProtection, owner, name, url.PathEscape(branch)), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func UpdateBranchProtection(accessToken string, owner string, name string, branch string, request github.UpdateBranchProtectionRequest) *github.GithubErrorResponse {
+	return sendJson(accessToken, http.MethodPut, fmt.Sprintf(urlBranchProtection, owner, name, url.PathEscape(branch)), request, nil)
+}
+
+func CreateHook(accessToken string, owner string, name string, request github.CreateHookRequest) (*github.Hook, *github.GithubErrorResponse) {
+	var result github.Hook
+	if err := sendJson(accessToken, http.MethodPost, fmt.Sprintf(urlHooks, owner, name), request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func GetContent(accessToken string, owner string, name string, path string) (*github.FileContent, *github.GithubErrorResponse) {
+	var result github.FileContent
+	if err := getJson(accessToken, fmt.Sprintf(urlContents, owner, name, path), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateOrUpdateFile commits the file at path, Content being base64 encoded.
+func CreateOrUpdateFile(accessToken string, owner string, name string, path string, request github.CreateFileRequest) (*github.CreateFileResponse, *github.GithubErrorResponse) {
+	var result github.CreateFileResponse
+	if err := sendJson(accessToken, http.MethodPut, fmt.Sprintf(urlContents, owner, name, path), request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func CreateLabel(accessToken string, owner string, name string, label github.Label) (*github.Label, *github.GithubErrorResponse) {
+	var result github.Label
+	if err := sendJson(accessToken, http.MethodPost, fmt.Sprintf(urlLabels, owner, name), label, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateLabel changes the color and description of the label named
+// label.Name.
+func UpdateLabel(accessToken string, owner string, name string, label github.Label) (*github.Label, *github.GithubErrorResponse) {
+	var result github.Label
+	if err := sendJson(accessToken, http.MethodPatch, fmt.Sprintf(urlLabel, owner, name, url.PathEscape(label.Name)), label, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func CreateRelease(accessToken string, owner string, name string, request github.CreateReleaseRequest) (*github.Release, *github.GithubErrorResponse) {
+	var result github.Release
+	if err := sendJson(accessToken, http.MethodPost, fmt.Sprintf(urlReleases, owner, name), request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetReleases returns the latest releases of the repository, drafts included
+// when the token can push to it.
+func GetReleases(accessToken string, owner string, name string) ([]github.Release, *github.GithubErrorResponse) {
+	var result []github.Release
+	if err := getJson(accessToken, fmt.Sprintf(urlReleases, owner, name)+"?per_page=100", &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func GetRelease(accessToken string, owner string, name string, releaseId int64) (*github.Release, *github.GithubErrorResponse) {
+	var result github.Release
+	if err := getJson(accessToken, fmt.Sprintf(urlRelease, owner, name, releaseId), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetReleaseByTag returns the published release of tag; drafts are not
+// attached to a tag yet and are never found.
+func GetReleaseByTag(accessToken string, owner string, name string, tag string) (*github.Release, *github.GithubErrorResponse) {
+	var result github.Release
+	if err := getJson(accessToken, fmt.Sprintf(urlReleaseByTag, owner, name, url.PathEscape(tag)), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UploadReleaseAsset streams size bytes of content to the uploads host as an
+// asset of the release.
+func UploadReleaseAsset(accessToken string, owner string, name string, releaseId int64, asset string, label string, contentType string, content io.Reader, size int64) (*github.ReleaseAsset, *github.GithubErrorResponse) {
+	query := url.Values{}
+	query.Set("name", asset)
+	if label != "" {
+		query.Set("label", label)
+	}
+	headers := http.Header{}
+	headers.Set(headerAuthorization, getAuthorizationHeader(accessToken))
+	headers.Set(headerContentType, contentType)
+
+	target := fmt.Sprintf(urlReleaseAssets, owner, name, releaseId, query.Encode())
+	response, err := restclient.DoRaw(http.MethodPost, target, content, size, headers)
+
+	var result github.ReleaseAsset
+	if err := readResponse(http.MethodPost, target, response, err, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func GetTags(accessToken string, owner string, name string) ([]github.Tag, *github.GithubErrorResponse) {
+	var result []github.Tag
+	if err := getJson(accessToken, fmt.Sprintf(urlTags, owner, name), &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func CreateTagObject(accessToken string, owner string, name string, request github.CreateTagObjectRequest) (*github.TagObject, *github.GithubErrorResponse) {
+	var result github.TagObject
+	if err := sendJson(accessToken, http.MethodPost, fmt.Sprintf(urlGitTags, owner, name), request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func CreateRef(accessToken string, owner string, name string, request github.CreateRefRequest) (*github.Reference, *github.GithubErrorResponse) {
+	var result github.Reference
+	if err := sendJson(accessToken, http.MethodPost, fmt.Sprintf(urlGitRefs, owner, name), request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func CreateDeployKey(accessToken string, owner string, name string, request github.CreateDeployKeyRequest) (*github.DeployKey, *github.GithubErrorResponse) {
+	var result github.DeployKey
+	if err := sendJson(accessToken, http.MethodPost, fmt.Sprintf(urlDeployKeys, owner, name), request, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func GetActionsPublicKey(accessToken string, owner string, name string) (*github.ActionsPublicKey, *github.GithubErrorResponse) {
+	var result github.ActionsPublicKey
+	if err := getJson(accessToken, fmt.Sprintf(urlActionsPublicKey, owner, name), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateOrUpdateActionsSecret stores a secret encrypted with the public key
+// of the repository. GitHub answers 201 for a new secret, 204 otherwise.
+func CreateOrUpdateActionsSecret(accessToken string, owner string, name string, secret string, request github.CreateOrUpdateSecretRequest) *github.GithubErrorResponse {
+	return sendJson(accessToken, http.MethodPut, fmt.Sprintf(urlActionsSecret, owner, name, secret), request, nil)
+}
+
+func getJson(accessToken string, url string, target interface{}) *github.GithubErrorResponse {
+	return sendJson(accessToken, http.MethodGet, url, nil, target)
+}
+
+func sendJson(accessToken string, method string, url string, body interface{}, target interface{}) *github.GithubErrorResponse {
+	return sendJsonWithAuthorization(getAuthorizationHeader(accessToken), method, url, body, target)
+}
+
+// sendJsonWithAuthorization sends body to url and decodes the response into
+// target, when set.
+func sendJsonWithAuthorization(authorization string, method string, url string, body interface{}, target interface{}) *github.GithubErrorResponse {
+	headers := http.Header{}
+	headers.Set(headerAuthorization, authorization)
+
+	response, err := restclient.Do(method, url, body, headers)
+	return readResponse(method, url, response, err, target)
+}
+
+// readResponse decodes the response to a request sent to url into target,
+// when set, or into the error GitHub reported.
+func readResponse(method string, url string, response *http.Response, err error, target interface{}) *github.GithubErrorResponse {
+	if err != nil {
+		log.Println(fmt.Sprintf("error when trying to %s %s in github: %s", method, url, err.Error()))
+		return &github.GithubErrorResponse{
+			StatusCode: http.StatusInternalServerError,
+			Message:    err.Error()}
+	}
+	defer response.Body.Close()
+
+	bytes, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return &github.GithubErrorResponse{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "invalid response body",
+		}
+	}
+
+	if response.StatusCode > 299 {
+		return getErrorResponse(response.StatusCode, bytes)
+	}
+
+	if target == nil || len(bytes) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(bytes, target); err != nil {
+		log.Println(fmt.Sprintf("error when trying to unmarshal github response from %s: %s", url, err.Error()))
+		return &github.GithubErrorResponse{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "error when trying to unmarshal github response",
+		}
+	}
+	return nil
+}
+
+func getErrorResponse(statusCode int, bytes []byte) *github.GithubErrorResponse {
+	var errResponse github.GithubErrorResponse
+	if err := json.Unmarshal(bytes, &errResponse); err != nil {
+		return &github.GithubErrorResponse{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "invalid json response body",
+		}
+	}
+	errResponse.StatusCode = statusCode
+	return &errResponse
+}
@@ -21,6 +21,7 @@ func TestConstants(t *testing.T){
 	assert.EqualValues(t, "Authorization", headerAuthorization)
 	assert.EqualValues(t, "token %s", headerAuthorizationFormat)
 	assert.EqualValues(t, "https://api.github.com/user/repos", urlCreateRepo)
+	assert.EqualValues(t, "https://api.github.com/repos/%s/%s", urlRepo)
 
 }
 
@@ -133,3 +134,464 @@ func TestCreateRepoNoError(t *testing.T) {
 	assert.EqualValues(t, "golang-tutorial", response.Name)
 	assert.EqualValues(t, "EBKopec/golang-tutorial", response.FullName)
 }
+
+func TestDeleteRepoErrorRestClient(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial",
+		HttpMethod: http.MethodDelete,
+		Err:        errors.New("invalid restclient response"),
+	})
+	err := DeleteRepo("", "EBKopec", "golang-tutorial")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode)
+	assert.EqualValues(t, "invalid restclient response", err.Message)
+}
+
+func TestDeleteRepoForbidden(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial",
+		HttpMethod: http.MethodDelete,
+		BodyText:   `{"message": "Must have admin rights to Repository."}`,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+		},
+	})
+	err := DeleteRepo("", "EBKopec", "golang-tutorial")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+	assert.EqualValues(t, "Must have admin rights to Repository.", err.Message)
+}
+
+func TestDeleteRepoNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusNoContent,
+		},
+	})
+	err := DeleteRepo("", "EBKopec", "golang-tutorial")
+	assert.Nil(t, err)
+}
+
+func TestGetRepoNotFound(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/missing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+		},
+	})
+	response, err := GetRepo("", "EBKopec", "missing")
+	assert.Nil(t, response)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+	assert.EqualValues(t, "Not Found", err.Message)
+}
+
+func TestGetRepoInvalidSuccessResponse(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": "123"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	response, err := GetRepo("", "EBKopec", "golang-tutorial")
+	assert.Nil(t, response)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusInternalServerError, err.StatusCode)
+	assert.EqualValues(t, "error when trying to unmarshal github response", err.Message)
+}
+
+func TestGetRepoNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 123,"name": "golang-tutorial","full_name": "EBKopec/golang-tutorial","private":true,"topics":["go"]}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	response, err := GetRepo("", "EBKopec", "golang-tutorial")
+	assert.Nil(t, err)
+	assert.NotNil(t, response)
+	assert.EqualValues(t, 123, response.Id)
+	assert.EqualValues(t, "EBKopec/golang-tutorial", response.FullName)
+	assert.True(t, response.Private)
+	assert.EqualValues(t, []string{"go"}, response.Topics)
+}
+
+func TestGetAuthenticatedUserNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1,"login": "EBKopec"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	response, err := GetAuthenticatedUser("")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "EBKopec", response.Login)
+}
+
+func TestUpdateRepoNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial",
+		HttpMethod: http.MethodPatch,
+		BodyText:   `{"id": 123,"name": "golang-tutorial","description": "updated"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	description := "updated"
+	response, err := UpdateRepo("", "EBKopec", "golang-tutorial", github.UpdateRepoRequest{Description: &description})
+	assert.Nil(t, err)
+	assert.EqualValues(t, "updated", response.Description)
+}
+
+func TestReplaceTopicsError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/topics",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"message": "Validation Failed"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+		},
+	})
+	err := ReplaceTopics("", "EBKopec", "golang-tutorial", []string{"Not Valid"})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.EqualValues(t, "Validation Failed", err.Message)
+}
+
+func TestTransferRepoForbidden(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/transfer",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"message": "You must be an admin to transfer a repository."}`,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+		},
+	})
+	repository, err := TransferRepo("", "EBKopec", "golang-tutorial", github.TransferRepoRequest{NewOwner: "golang"})
+	assert.Nil(t, repository)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.StatusCode)
+}
+
+func TestCreateReleaseNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/releases",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 1, "tag_name": "v1.0.0", "draft": true, "created_at": "2021-10-01T10:00:00Z", "published_at": null}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+	release, err := CreateRelease("", "EBKopec", "golang-tutorial", github.CreateReleaseRequest{TagName: "v1.0.0", Draft: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, release.Id)
+	assert.True(t, release.Draft)
+	assert.Nil(t, release.PublishedAt)
+}
+
+func TestGetReleaseByTagEscapesTag(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/releases/tags/release%2F1.0",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+		},
+	})
+	release, err := GetReleaseByTag("", "EBKopec", "golang-tutorial", "release/1.0")
+	assert.Nil(t, release)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+}
+
+func TestUploadReleaseAssetNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://uploads.github.com/repos/EBKopec/golang-tutorial/releases/1/assets?label=Linux+build&name=app.tar.gz",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 9, "name": "app.tar.gz", "label": "Linux build", "size": 4, "state": "uploaded"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+	asset, err := UploadReleaseAsset("", "EBKopec", "golang-tutorial", 1, "app.tar.gz", "Linux build", "application/gzip", strings.NewReader("data"), 4)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 9, asset.Id)
+	assert.EqualValues(t, "uploaded", asset.State)
+}
+
+func TestCreateRefAlreadyExists(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/git/refs",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"message": "Reference already exists"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+		},
+	})
+	ref, err := CreateRef("", "EBKopec", "golang-tutorial", github.CreateRefRequest{Ref: "refs/tags/v1", Sha: "abc"})
+	assert.Nil(t, ref)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "Reference already exists", err.Message)
+}
+
+func TestCreateDeployKeyNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/keys",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 5, "title": "ci", "key": "ssh-ed25519 AAAA", "read_only": true, "verified": true}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+	key, err := CreateDeployKey("", "EBKopec", "golang-tutorial", github.CreateDeployKeyRequest{Title: "ci", Key: "ssh-ed25519 AAAA", ReadOnly: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 5, key.Id)
+	assert.True(t, key.ReadOnly)
+}
+
+func TestGetActionsPublicKeyNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/actions/secrets/public-key",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"key_id": "012345678912345678", "key": "2Sg8iYjAxxmI2LvUXpJjkYrMxURPc8r+dB7TJyvv1234"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	key, err := GetActionsPublicKey("", "EBKopec", "golang-tutorial")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "012345678912345678", key.KeyId)
+}
+
+func TestCreateOrUpdateActionsSecretNoContent(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/actions/secrets/NPM_TOKEN",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusNoContent,
+		},
+	})
+	err := CreateOrUpdateActionsSecret("", "EBKopec", "golang-tutorial", "NPM_TOKEN", github.CreateOrUpdateSecretRequest{EncryptedValue: "c2VhbGVk", KeyId: "1"})
+	assert.Nil(t, err)
+}
+
+func TestGetTopicsNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/topics",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"names": ["go", "api"]}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	topics, err := GetTopics("", "EBKopec", "golang-tutorial")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"go", "api"}, topics)
+}
+
+func TestCreateLabelAlreadyExists(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/labels",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"message": "Validation Failed", "errors": [{"resource": "Label", "code": "already_exists", "field": "name"}]}`,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+		},
+	})
+	label, err := CreateLabel("", "EBKopec", "golang-tutorial", github.Label{Name: "bug", Color: "d73a4a"})
+	assert.Nil(t, label)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.StatusCode)
+}
+
+func TestUpdateLabelEscapesName(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/labels/good%20first%20issue",
+		HttpMethod: http.MethodPatch,
+		BodyText:   `{"id": 7, "name": "good first issue", "color": "7057ff"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	label, err := UpdateLabel("", "EBKopec", "golang-tutorial", github.Label{Name: "good first issue", Color: "7057ff"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 7, label.Id)
+	assert.EqualValues(t, "7057ff", label.Color)
+}
+
+func TestGetCollaboratorsNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/collaborators?affiliation=direct&per_page=100",
+		HttpMethod: http.MethodGet,
+		BodyText:   `[{"id": 1,"login": "octocat","permissions": {"pull": true,"triage": true,"push": true}}]`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	response, err := GetCollaborators("", "EBKopec", "golang-tutorial")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(response))
+	assert.EqualValues(t, "octocat", response[0].Login)
+	assert.EqualValues(t, "push", response[0].Permissions.Permission())
+}
+
+func TestAddCollaboratorNoContent(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/collaborators/octocat",
+		HttpMethod: http.MethodPut,
+		Response: &http.Response{
+			StatusCode: http.StatusNoContent,
+		},
+	})
+	err := AddCollaborator("", "EBKopec", "golang-tutorial", "octocat", "push")
+	assert.Nil(t, err)
+}
+
+func TestGetBranchProtectionNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/branches/main/protection",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"enforce_admins": {"enabled": true},"required_pull_request_reviews": {"required_approving_review_count": 2}}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	response, err := GetBranchProtection("", "EBKopec", "golang-tutorial", "main")
+	assert.Nil(t, err)
+	assert.True(t, response.EnforceAdmins.Enabled)
+	assert.EqualValues(t, 2, response.RequiredPullRequestReviews.RequiredApprovingReviewCount)
+	assert.Nil(t, response.RequiredStatusChecks)
+}
+
+func TestUpdateBranchProtectionNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/branches/main/protection",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"url": "https://api.github.com/repos/EBKopec/golang-tutorial/branches/main/protection"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	err := UpdateBranchProtection("", "EBKopec", "golang-tutorial", "main", github.UpdateBranchProtectionRequest{EnforceAdmins: true})
+	assert.Nil(t, err)
+}
+
+func TestUpdateBranchProtectionEscapesBranch(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/branches/release%2F1.0/protection",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"url": "https://api.github.com/repos/EBKopec/golang-tutorial/branches/release%2F1.0/protection"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusOK,
+		},
+	})
+	err := UpdateBranchProtection("", "EBKopec", "golang-tutorial", "release/1.0", github.UpdateBranchProtectionRequest{EnforceAdmins: true})
+	assert.Nil(t, err)
+}
+
+func TestCreateHookNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/hooks",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 1, "name": "web", "active": true, "events": ["push"], "config": {"url": "https://ci.example.com/hook", "content_type": "json"}}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+	hook, err := CreateHook("", "EBKopec", "golang-tutorial", github.CreateHookRequest{
+		Name:   github.HookNameWeb,
+		Active: true,
+		Events: []string{"push"},
+		Config: github.HookConfig{Url: "https://ci.example.com/hook", ContentType: "json"},
+	})
+	assert.Nil(t, err)
+	assert.NotNil(t, hook)
+	assert.EqualValues(t, 1, hook.Id)
+	assert.EqualValues(t, "https://ci.example.com/hook", hook.Config.Url)
+}
+
+func TestCreateHookError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/hooks",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"message": "Validation Failed"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+		},
+	})
+	hook, err := CreateHook("", "EBKopec", "golang-tutorial", github.CreateHookRequest{})
+	assert.Nil(t, hook)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.StatusCode)
+	assert.EqualValues(t, "Validation Failed", err.Message)
+}
+
+func TestGetContentNotFound(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/contents/README.md",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusNotFound,
+		},
+	})
+	content, err := GetContent("", "EBKopec", "golang-tutorial", "README.md")
+	assert.Nil(t, content)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.StatusCode)
+}
+
+func TestCreateOrUpdateFileNoError(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/golang-tutorial/contents/.github/workflows/ci.yml",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"content": {"type": "file", "path": ".github/workflows/ci.yml", "sha": "abc"}, "commit": {"sha": "def"}}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+	response, err := CreateOrUpdateFile("", "EBKopec", "golang-tutorial", ".github/workflows/ci.yml", github.CreateFileRequest{Message: "Add ci", Content: "bmFtZTogY2kK"})
+	assert.Nil(t, err)
+	assert.NotNil(t, response)
+	assert.EqualValues(t, "abc", response.Content.Sha)
+	assert.EqualValues(t, "def", response.Commit.Sha)
+}
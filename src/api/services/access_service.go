@@ -0,0 +1,157 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
+	"github.com/evertonkopec/golang-microservices-main/src/api/providers/github_provider"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/crypto"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+)
+
+type accessService struct{}
+
+type accessServiceInterface interface {
+	AddDeployKey(clientId string, owner string, name string, request repositories.DeployKeyRequest) (*repositories.DeployKey, errors.ApiError)
+	SetSecret(clientId string, owner string, name string, secret repositories.ActionsSecret) (*repositories.SetSecretResponse, errors.ApiError)
+}
+
+var (
+	AccessService accessServiceInterface
+)
+
+func init() {
+	AccessService = &accessService{}
+}
+
+func (s *accessService) AddDeployKey(clientId string, owner string, name string, request repositories.DeployKeyRequest) (*repositories.DeployKey, errors.ApiError) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return addDeployKey(token, owner, name, request)
+}
+
+func (s *accessService) SetSecret(clientId string, owner string, name string, secret repositories.ActionsSecret) (*repositories.SetSecretResponse, errors.ApiError) {
+	if err := secret.Validate(); err != nil {
+		return nil, err
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	publicKey, apiErr := getActionsPublicKey(token, owner, name)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if err := setSecret(token, owner, name, publicKey, secret); err != nil {
+		return nil, err
+	}
+	return &repositories.SetSecretResponse{Name: secret.Name}, nil
+}
+
+// provisionAccess adds the deploy keys and then sets the Actions secrets of
+// settings, stopping at the first failure. The keys added until then are
+// returned either way, so that generated private keys are not lost.
+func provisionAccess(clientId string, owner string, name string, settings repositories.RepoSettings) ([]repositories.DeployKey, errors.ApiError) {
+	if len(settings.DeployKeys) == 0 && len(settings.Secrets) == 0 {
+		return nil, nil
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var result []repositories.DeployKey
+	for _, request := range settings.DeployKeys {
+		key, err := addDeployKey(token, owner, name, request)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, *key)
+	}
+
+	if len(settings.Secrets) == 0 {
+		return result, nil
+	}
+	publicKey, apiErr := getActionsPublicKey(token, owner, name)
+	if apiErr != nil {
+		return result, apiErr
+	}
+	for _, secret := range repositories.NormalizeSecrets(settings.Secrets) {
+		if err := setSecret(token, owner, name, publicKey, secret); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// addDeployKey adds the key of the request or, when asked to, a freshly
+// generated one whose private half is only returned here.
+func addDeployKey(token string, owner string, name string, request repositories.DeployKeyRequest) (*repositories.DeployKey, errors.ApiError) {
+	publicKey, privateKey := request.Key, ""
+	if request.Generate {
+		var err error
+		if publicKey, privateKey, err = crypto.GenerateSSHKey(fmt.Sprintf("%s@%s/%s", request.Title, owner, name)); err != nil {
+			return nil, errors.Wrap(err, http.StatusInternalServerError, "error when trying to generate deploy key")
+		}
+	}
+
+	key, err := github_provider.CreateDeployKey(token, owner, name, github.CreateDeployKeyRequest{
+		Title:    request.Title,
+		Key:      publicKey,
+		ReadOnly: request.IsReadOnly(),
+	})
+	if err != nil {
+		option_b.Error("error when trying to add deploy key", err,
+			option_b.Field("repository", fmt.Sprintf("%s/%s", owner, name)),
+			option_b.Field("title", request.Title))
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	return &repositories.DeployKey{
+		Id:         key.Id,
+		Title:      key.Title,
+		Key:        key.Key,
+		ReadOnly:   key.ReadOnly,
+		PrivateKey: privateKey,
+	}, nil
+}
+
+func getActionsPublicKey(token string, owner string, name string) (*github.ActionsPublicKey, errors.ApiError) {
+	publicKey, err := github_provider.GetActionsPublicKey(token, owner, name)
+	if err != nil {
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	return publicKey, nil
+}
+
+// setSecret encrypts the value with the public key of the repository, so the
+// value only ever travels to GitHub in a sealed box.
+func setSecret(token string, owner string, name string, publicKey *github.ActionsPublicKey, secret repositories.ActionsSecret) errors.ApiError {
+	key, err := base64.StdEncoding.DecodeString(publicKey.Key)
+	if err != nil {
+		return errors.Wrap(err, http.StatusBadGateway, "github returned an invalid actions public key")
+	}
+	sealed, err := crypto.SealAnonymous(key, []byte(secret.Value))
+	if err != nil {
+		return errors.Wrap(err, http.StatusBadGateway, "github returned an invalid actions public key")
+	}
+
+	request := github.CreateOrUpdateSecretRequest{
+		EncryptedValue: base64.StdEncoding.EncodeToString(sealed),
+		KeyId:          publicKey.KeyId,
+	}
+	if err := github_provider.CreateOrUpdateActionsSecret(token, owner, name, secret.Name, request); err != nil {
+		option_b.Error("error when trying to set actions secret", err,
+			option_b.Field("repository", fmt.Sprintf("%s/%s", owner, name)),
+			option_b.Field("secret", secret.Name))
+		return errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/approvals"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/policies"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+)
+
+type approvalsService struct{}
+
+type approvalsServiceInterface interface {
+	Request(clientId string, day string, request repositories.CreateRepoRequest, decision policies.Decision) (*repositories.CreateRepoResponse, errors.ApiError)
+	Find(callerId string, status string) ([]approvals.Approval, errors.ApiError)
+	Get(callerId string, id string) (*approvals.Approval, errors.ApiError)
+	Decide(callerId string, id string, decision approvals.DecisionRequest) (*approvals.Approval, errors.ApiError)
+}
+
+var (
+	ApprovalsService approvalsServiceInterface
+)
+
+func init() {
+	ApprovalsService = &approvalsService{}
+}
+
+// Request queues the repository request, counted against the quota of day,
+// until an admin decides on it.
+func (s *approvalsService) Request(clientId string, day string, request repositories.CreateRepoRequest, decision policies.Decision) (*repositories.CreateRepoResponse, errors.ApiError) {
+	approval, err := approvals.ApprovalsDao.Save(approvals.Approval{
+		ClientId:   clientId,
+		QuotaDay:   day,
+		Request:    request,
+		Rule:       decision.Rule,
+		Violations: decision.Violations,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	option_b.Info("repository request waiting for approval",
+		option_b.Field("client_id", clientId),
+		option_b.Field("approval_id", approval.Id),
+		option_b.Field("rule", decision.Rule))
+	return &repositories.CreateRepoResponse{Name: request.Name, ApprovalId: approval.Id}, nil
+}
+
+// Find returns the approvals with status, every one of them for admins and
+// their own for everybody else.
+func (s *approvalsService) Find(callerId string, status string) ([]approvals.Approval, errors.ApiError) {
+	switch status {
+	case "", approvals.StatusPending, approvals.StatusApproved, approvals.StatusRejected:
+	default:
+		return nil, errors.NewBadRequestError(fmt.Sprintf("invalid status %s", status))
+	}
+
+	filter := approvals.Filter{Status: status}
+	if !clients.ClientRegistry.Get(callerId).Admin {
+		filter.ClientId = callerId
+	}
+	result := approvals.ApprovalsDao.Find(filter)
+	for index, approval := range result {
+		result[index] = approval.Redacted()
+	}
+	return result, nil
+}
+
+func (s *approvalsService) Get(callerId string, id string) (*approvals.Approval, errors.ApiError) {
+	approval, err := approvals.ApprovalsDao.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.ClientId != callerId && !clients.ClientRegistry.Get(callerId).Admin {
+		return nil, errors.NewNotFoundApiError(fmt.Sprintf("approval %s not found", id))
+	}
+	result := approval.Redacted()
+	return &result, nil
+}
+
+// Decide approves or rejects a pending request. A pending request holds the
+// quota of the day it was asked for. A rejected one gives it back; an
+// approved one is created right away with the credentials of the client that
+// asked for it and counts against the quota of the day it is created on
+// instead, which fails the creation when that quota is exhausted.
+func (s *approvalsService) Decide(callerId string, id string, decision approvals.DecisionRequest) (*approvals.Approval, errors.ApiError) {
+	if !clients.ClientRegistry.Get(callerId).Admin {
+		return nil, errors.NewApiError(http.StatusForbidden, "only admins can decide on approvals")
+	}
+	if err := decision.Validate(); err != nil {
+		return nil, err
+	}
+
+	approval, err := approvals.ApprovalsDao.Decide(id, decision, callerId)
+	if err != nil {
+		return nil, err
+	}
+	option_b.Info("repository request decided",
+		option_b.Field("client_id", approval.ClientId),
+		option_b.Field("approval_id", approval.Id),
+		option_b.Field("status", approval.Status),
+		option_b.Field("decided_by", callerId))
+
+	if approval.Status == approvals.StatusRejected {
+		clients.UsageDao.RefundRepos(approval.ClientId, approval.QuotaDay, 1)
+		return approval, nil
+	}
+
+	clients.UsageDao.RefundRepos(approval.ClientId, approval.QuotaDay, 1)
+	repo, createErr := createApproved(*approval)
+
+	// generated private keys are handed to the deciding admin only
+	var stored *repositories.CreateRepoResponse
+	if repo != nil {
+		withoutKeys := repo.WithoutPrivateKeys()
+		stored = &withoutKeys
+	}
+	completed, err := approvals.ApprovalsDao.Complete(approval.Id, stored, createErr)
+	if err != nil {
+		return nil, err
+	}
+	result := completed.Redacted()
+	result.Repo = repo
+	return &result, nil
+}
+
+// createApproved creates the repository of an approved request against the
+// quota of today. A creation coalesced with an identical one in flight gets
+// its quota back from createAndRecord, so only failures are refunded here.
+func createApproved(approval approvals.Approval) (*repositories.CreateRepoResponse, errors.ApiError) {
+	day, err := consumeRepoQuota(approval.ClientId, 1)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := (&reposService{}).createAndRecord(approval.ClientId, day, approval.Request)
+	if err != nil {
+		clients.UsageDao.RefundRepos(approval.ClientId, day, 1)
+	}
+	return repo, err
+}
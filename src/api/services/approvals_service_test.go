@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/approvals"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/policies"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// withPolicies runs test with rules, a fresh approvals dao and an admin
+// client, restoring the originals afterwards.
+func withPolicies(rules []policies.Rule, test func()) {
+	originalEngine, originalDao := policies.PolicyEngine, approvals.ApprovalsDao
+	defer func() { policies.PolicyEngine, approvals.ApprovalsDao = originalEngine, originalDao }()
+
+	policies.PolicyEngine, _ = policies.NewEngine(rules...)
+	approvals.ApprovalsDao = approvals.NewApprovalsDao()
+	withClients([]clients.Client{{Id: "admin", Admin: true}}, test)
+}
+
+var (
+	productionRule = policies.Rule{Name: "production", Match: "^prod-", Outcome: policies.OutcomeRequireApproval}
+	prefixRule     = policies.Rule{Name: "prefix", Clients: []string{"team-a"}, NamePrefix: "team-a-", Outcome: policies.OutcomeDeny}
+)
+
+func TestCreateRepoDeniedByPolicy(t *testing.T) {
+	withPolicies([]policies.Rule{prefixRule}, func() {
+		result, err := RepositoryService.CreateRepo("team-a", repositories.CreateRepoRequest{Name: "service"})
+		assert.Nil(t, result)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusForbidden, err.Status())
+		assert.EqualValues(t, "repository request denied by policy prefix", err.Message())
+		assert.EqualValues(t, []interface{}{repositories.FieldError{Field: "name", Message: "name must start with 'team-a-'"}}, err.Causes())
+		assert.EqualValues(t, 0, clients.UsageDao.Get("team-a").ReposCreated)
+	})
+}
+
+func TestCreateRepoRequiresApproval(t *testing.T) {
+	withPolicies([]policies.Rule{productionRule}, func() {
+		restclient.FlushMocks()
+
+		result, err := RepositoryService.CreateRepo("team-a", repositories.CreateRepoRequest{Name: "prod-api"})
+		assert.Nil(t, err)
+		assert.NotNil(t, result)
+		assert.NotEmpty(t, result.ApprovalId)
+		assert.EqualValues(t, 0, result.Id)
+		assert.EqualValues(t, 1, clients.UsageDao.Get("team-a").ReposCreated)
+
+		pending, err := ApprovalsService.Find("admin", approvals.StatusPending)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, len(pending))
+		assert.EqualValues(t, "production", pending[0].Rule)
+
+		own, _ := ApprovalsService.Find("team-b", "")
+		assert.EqualValues(t, 0, len(own))
+		_, err = ApprovalsService.Get("team-b", result.ApprovalId)
+		assert.EqualValues(t, http.StatusNotFound, err.Status())
+		approval, err := ApprovalsService.Get("team-a", result.ApprovalId)
+		assert.Nil(t, err)
+		assert.EqualValues(t, approvals.StatusPending, approval.Status)
+	})
+}
+
+func TestDecideApproveCreatesRepo(t *testing.T) {
+	withPolicies([]policies.Rule{productionRule}, func() {
+		restclient.FlushMocks()
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/user/repos",
+			HttpMethod: http.MethodPost,
+			BodyText:   `{ "id": 123, "name":"prod-api", "owner":{"login":"EBKopec" }}`,
+			Response:   &http.Response{StatusCode: http.StatusCreated},
+		})
+		result, _ := RepositoryService.CreateRepo("team-a", repositories.CreateRepoRequest{Name: "prod-api"})
+
+		approval, err := ApprovalsService.Decide("team-a", result.ApprovalId, approvals.DecisionRequest{Decision: approvals.DecisionApprove})
+		assert.Nil(t, approval)
+		assert.EqualValues(t, http.StatusForbidden, err.Status())
+		assert.EqualValues(t, "only admins can decide on approvals", err.Message())
+
+		_, err = ApprovalsService.Decide("admin", result.ApprovalId, approvals.DecisionRequest{Decision: "later"})
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+
+		approval, err = ApprovalsService.Decide("admin", result.ApprovalId, approvals.DecisionRequest{Decision: approvals.DecisionApprove, Reason: "planned"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, approvals.StatusApproved, approval.Status)
+		assert.EqualValues(t, "admin", approval.DecidedBy)
+		assert.Nil(t, approval.Error)
+		assert.NotNil(t, approval.Repo)
+		assert.EqualValues(t, 123, approval.Repo.Id)
+		assert.EqualValues(t, "EBKopec", approval.Repo.Owner)
+		assert.EqualValues(t, 1, clients.UsageDao.Get("team-a").ReposCreated)
+
+		_, err = ApprovalsService.Decide("admin", result.ApprovalId, approvals.DecisionRequest{Decision: approvals.DecisionReject})
+		assert.EqualValues(t, http.StatusConflict, err.Status())
+	})
+}
+
+func TestDecideRejectRefundsQuota(t *testing.T) {
+	withPolicies([]policies.Rule{productionRule}, func() {
+		result, _ := RepositoryService.CreateRepo("team-a", repositories.CreateRepoRequest{Name: "prod-api"})
+		assert.EqualValues(t, 1, clients.UsageDao.Get("team-a").ReposCreated)
+
+		approval, err := ApprovalsService.Decide("admin", result.ApprovalId, approvals.DecisionRequest{Decision: approvals.DecisionReject, Reason: "not now"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, approvals.StatusRejected, approval.Status)
+		assert.Nil(t, approval.Repo)
+		assert.EqualValues(t, 0, clients.UsageDao.Get("team-a").ReposCreated)
+	})
+}
+
+func TestDecideRejectRefundsDayOfRequest(t *testing.T) {
+	withPolicies([]policies.Rule{productionRule}, func() {
+		held, _ := approvals.ApprovalsDao.Save(approvals.Approval{
+			ClientId: "team-a",
+			QuotaDay: "2021-10-01",
+			Request:  repositories.CreateRepoRequest{Name: "prod-api"},
+			Rule:     productionRule.Name,
+		})
+		clients.UsageDao.ConsumeRepos("team-a", 1, 10)
+
+		_, err := ApprovalsService.Decide("admin", held.Id, approvals.DecisionRequest{Decision: approvals.DecisionReject})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, clients.UsageDao.Get("team-a").ReposCreated)
+	})
+}
+
+func TestDecideApproveChargesDayOfCreation(t *testing.T) {
+	withPolicies([]policies.Rule{productionRule}, func() {
+		addCreateRepoMock()
+		held, _ := approvals.ApprovalsDao.Save(approvals.Approval{
+			ClientId: "team-a",
+			QuotaDay: "2021-10-01",
+			Request:  repositories.CreateRepoRequest{Name: "prod-api"},
+			Rule:     productionRule.Name,
+		})
+
+		approval, err := ApprovalsService.Decide("admin", held.Id, approvals.DecisionRequest{Decision: approvals.DecisionApprove})
+		assert.Nil(t, err)
+		assert.Nil(t, approval.Error)
+		assert.NotNil(t, approval.Repo)
+		assert.EqualValues(t, 1, clients.UsageDao.Get("team-a").ReposCreated)
+	})
+}
+
+func TestDecideApproveQuotaExceeded(t *testing.T) {
+	withPolicies([]policies.Rule{productionRule}, func() {
+		withClients([]clients.Client{{Id: "admin", Admin: true}, {Id: "team-a", Quotas: clients.Quotas{ReposPerDay: 1}}}, func() {
+			addCreateRepoMock()
+			held, _ := approvals.ApprovalsDao.Save(approvals.Approval{
+				ClientId: "team-a",
+				QuotaDay: "2021-10-01",
+				Request:  repositories.CreateRepoRequest{Name: "prod-api"},
+				Rule:     productionRule.Name,
+			})
+			clients.UsageDao.ConsumeRepos("team-a", 1, 1)
+
+			approval, err := ApprovalsService.Decide("admin", held.Id, approvals.DecisionRequest{Decision: approvals.DecisionApprove})
+			assert.Nil(t, err)
+			assert.EqualValues(t, approvals.StatusApproved, approval.Status)
+			assert.Nil(t, approval.Repo)
+			assert.NotNil(t, approval.Error)
+			assert.EqualValues(t, http.StatusTooManyRequests, approval.Error.Status())
+			assert.EqualValues(t, 1, clients.UsageDao.Get("team-a").ReposCreated)
+		})
+	})
+}
+
+func TestDecideCoalescedApprovalsRefundOnce(t *testing.T) {
+	for _, status := range []int{http.StatusCreated, http.StatusUnprocessableEntity} {
+		withPolicies([]policies.Rule{productionRule}, func() {
+			addCreateRepoMock()
+			restclient.AddMockups(restclient.Mock{
+				Url:        "https://api.github.com/user/repos",
+				HttpMethod: http.MethodPost,
+				BodyText:   `{ "id": 123, "name":"prod-api", "owner":{"login":"EBKopec" }}`,
+				Response:   &http.Response{StatusCode: status},
+			})
+			// a repository created beforehand shows refunds going too far
+			clients.UsageDao.ConsumeRepos("team-a", 1, 10)
+			ids := make([]string, 3)
+			for i := range ids {
+				result, _ := RepositoryService.CreateRepo("team-a", repositories.CreateRepoRequest{Name: "prod-api"})
+				ids[i] = result.ApprovalId
+			}
+			assert.EqualValues(t, 4, clients.UsageDao.Get("team-a").ReposCreated)
+
+			unlock := creationLocks.Lock(creationLockKey("EBKopec", "prod-api"))
+			var wg sync.WaitGroup
+			for _, id := range ids {
+				wg.Add(1)
+				go func(id string) {
+					defer wg.Done()
+					_, err := ApprovalsService.Decide("admin", id, approvals.DecisionRequest{Decision: approvals.DecisionApprove})
+					assert.Nil(t, err)
+				}(id)
+			}
+			time.Sleep(50 * time.Millisecond)
+			unlock()
+			wg.Wait()
+
+			expected := 1
+			if status == http.StatusCreated {
+				expected = 2
+			}
+			assert.EqualValues(t, expected, clients.UsageDao.Get("team-a").ReposCreated)
+		})
+	}
+}
+
+func TestFindApprovalsInvalidStatus(t *testing.T) {
+	result, err := ApprovalsService.Find("admin", "unknown")
+	assert.Nil(t, result)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestCreateReposWithPendingApproval(t *testing.T) {
+	withPolicies([]policies.Rule{productionRule}, func() {
+		restclient.FlushMocks()
+		requests := []repositories.CreateRepoRequest{{Name: "prod-api"}, {Name: "prod-web"}}
+
+		result, err := RepositoryService.CreateRepos(context.Background(), "team-a", requests, repositories.CreateReposOptions{})
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusAccepted, result.StatusCode)
+		assert.NotEmpty(t, result.Results[0].Response.ApprovalId)
+		assert.NotEmpty(t, result.Results[1].Response.ApprovalId)
+
+		result, err = RepositoryService.CreateRepos(context.Background(), "team-a", requests, repositories.CreateReposOptions{Atomic: true})
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusUnprocessableEntity, result.StatusCode)
+		assert.EqualValues(t, "repository requires approval by policy production and can not be part of an atomic batch", result.Failure.Error.Message())
+		assert.EqualValues(t, 2, len(approvals.ApprovalsDao.Find(approvals.Filter{Status: approvals.StatusPending})))
+	})
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+)
+
+type auditService struct{}
+
+type auditServiceInterface interface {
+	FindRepositoryRecords(callerId string, filter audit.RecordFilter) ([]audit.RepositoryRecord, errors.ApiError)
+}
+
+var (
+	AuditService auditServiceInterface
+)
+
+func init() {
+	AuditService = &auditService{}
+}
+
+// FindRepositoryRecords returns the records matching filter, those of every
+// client for admins and their own for everybody else.
+func (s *auditService) FindRepositoryRecords(callerId string, filter audit.RecordFilter) ([]audit.RepositoryRecord, errors.ApiError) {
+	if !clients.ClientRegistry.Get(callerId).Admin {
+		if filter.ClientId != "" && filter.ClientId != callerId {
+			return nil, errors.NewApiError(http.StatusForbidden, "clients can only read their own audit records")
+		}
+		filter.ClientId = callerId
+	}
+
+	switch filter.Status {
+	case "", audit.StatusSucceeded, audit.StatusFailed:
+	default:
+		return nil, errors.NewBadRequestError("status must be either succeeded or failed")
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, errors.NewBadRequestError("from must be before to")
+	}
+	return audit.Store.Find(filter)
+}
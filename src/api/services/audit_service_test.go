@@ -0,0 +1,56 @@
+package services
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFindRepositoryRecordsInvalidStatus(t *testing.T) {
+	records, err := AuditService.FindRepositoryRecords("client", audit.RecordFilter{Status: "unknown"})
+	assert.Nil(t, records)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "status must be either succeeded or failed", err.Message())
+}
+
+func TestFindRepositoryRecordsInvalidRange(t *testing.T) {
+	now := time.Now()
+	records, err := AuditService.FindRepositoryRecords("client", audit.RecordFilter{From: now, To: now.Add(-time.Hour)})
+	assert.Nil(t, records)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "from must be before to", err.Message())
+}
+
+func TestFindRepositoryRecords(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+	audit.Store.Save(audit.RepositoryRecord{ClientId: "client", Status: audit.StatusSucceeded})
+	audit.Store.Save(audit.RepositoryRecord{ClientId: "other", Status: audit.StatusSucceeded})
+
+	records, err := AuditService.FindRepositoryRecords("client", audit.RecordFilter{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(records))
+	assert.EqualValues(t, "client", records[0].ClientId)
+
+	records, err = AuditService.FindRepositoryRecords("client", audit.RecordFilter{ClientId: "other"})
+	assert.Nil(t, records)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "clients can only read their own audit records", err.Message())
+
+	withClients([]clients.Client{{Id: "admin", Admin: true}}, func() {
+		records, err = AuditService.FindRepositoryRecords("admin", audit.RecordFilter{})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 2, len(records))
+
+		records, err = AuditService.FindRepositoryRecords("admin", audit.RecordFilter{ClientId: "other"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, len(records))
+	})
+}
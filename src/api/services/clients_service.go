@@ -0,0 +1,37 @@
+package services
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+)
+
+type clientsService struct{}
+
+type clientsServiceInterface interface {
+	GetUsage(callerId string, clientId string) (*clients.UsageReport, errors.ApiError)
+}
+
+var (
+	ClientsService clientsServiceInterface
+)
+
+func init() {
+	ClientsService = &clientsService{}
+}
+
+// GetUsage returns what clientId consumed today. Clients can only read their
+// own usage.
+func (s *clientsService) GetUsage(callerId string, clientId string) (*clients.UsageReport, errors.ApiError) {
+	if callerId != clientId {
+		return nil, errors.NewApiError(http.StatusForbidden, "clients can only read their own usage")
+	}
+
+	result := clients.UsageDao.Get(clientId)
+	result.Quotas = clients.ClientRegistry.Get(clientId).Quotas
+	result.ReposRemaining = result.Quotas.ReposPerDay - result.ReposCreated
+	if result.ReposRemaining < 0 {
+		result.ReposRemaining = 0
+	}
+	return &result, nil
+}
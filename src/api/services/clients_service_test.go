@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// withClients runs test against a fresh usage dao and a registry holding
+// registered, restoring the originals afterwards.
+func withClients(registered []clients.Client, test func()) {
+	originalRegistry, originalUsage := clients.ClientRegistry, clients.UsageDao
+	defer func() { clients.ClientRegistry, clients.UsageDao = originalRegistry, originalUsage }()
+
+	clients.ClientRegistry = clients.NewRegistry(registered...)
+	clients.UsageDao, _ = clients.NewUsageDao("")
+	test()
+}
+
+func TestCreateRepoDailyQuotaExceeded(t *testing.T) {
+	withClients([]clients.Client{{Id: "limited", Quotas: clients.Quotas{ReposPerDay: 1}}}, func() {
+		restclient.FlushMocks()
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/user/repos",
+			HttpMethod: http.MethodPost,
+			BodyText:   `{ "id": 123, "name":"testing", "owner":{"login":"EBKopec" }}`,
+			Response:   &http.Response{StatusCode: http.StatusCreated},
+		})
+
+		result, err := RepositoryService.CreateRepo("limited", repositories.CreateRepoRequest{Name: "testing"})
+		assert.Nil(t, err)
+		assert.NotNil(t, result)
+
+		result, err = RepositoryService.CreateRepo("limited", repositories.CreateRepoRequest{Name: "testing"})
+		assert.Nil(t, result)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+		assert.EqualValues(t, 1, clients.UsageDao.Get("limited").ReposCreated)
+	})
+}
+
+func TestCreateRepoFailureIsRefunded(t *testing.T) {
+	withClients(nil, func() {
+		result, err := RepositoryService.CreateRepo("client", repositories.CreateRepoRequest{})
+		assert.Nil(t, result)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, 0, clients.UsageDao.Get("client").ReposCreated)
+	})
+}
+
+func TestCreateReposQuotaCountsOnlyCreatedRepos(t *testing.T) {
+	withClients([]clients.Client{{Id: "limited", Quotas: clients.Quotas{ReposPerDay: 2, MaxBatchSize: 2}}}, func() {
+		restclient.FlushMocks()
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/user/repos",
+			HttpMethod: http.MethodPost,
+			BodyText:   `{ "id": 123, "name":"testing", "owner":{"login":"EBKopec" }}`,
+			Response:   &http.Response{StatusCode: http.StatusCreated},
+		})
+		requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: ".."}}
+
+		result, err := RepositoryService.CreateRepos(context.Background(), "limited", requests, repositories.CreateReposOptions{})
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusPartialContent, result.StatusCode)
+		assert.EqualValues(t, 1, clients.UsageDao.Get("limited").ReposCreated)
+
+		_, err = RepositoryService.CreateRepos(context.Background(), "limited", requests, repositories.CreateReposOptions{})
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+
+		_, err = RepositoryService.CreateRepos(context.Background(), "limited", append(requests, requests[0]), repositories.CreateReposOptions{})
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusBadRequest, err.Status())
+		assert.EqualValues(t, "at most 2 repositories can be created in a single batch", err.Message())
+	})
+}
+
+func TestCreateJobConcurrentJobsExceeded(t *testing.T) {
+	withClients([]clients.Client{{Id: "limited", Quotas: clients.Quotas{MaxConcurrentJobs: 1}}}, func() {
+		clients.UsageDao.StartJob("limited", 1)
+
+		job, err := JobsService.CreateJob("limited", []repositories.CreateRepoRequest{{Name: "testing"}}, repositories.CreateReposOptions{})
+		assert.Nil(t, job)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusTooManyRequests, err.Status())
+		assert.EqualValues(t, 0, clients.UsageDao.Get("limited").ReposCreated)
+	})
+}
+
+func TestGetUsage(t *testing.T) {
+	withClients([]clients.Client{{Id: "client", Quotas: clients.Quotas{ReposPerDay: 10}}}, func() {
+		usage, err := ClientsService.GetUsage("other", "client")
+		assert.Nil(t, usage)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusForbidden, err.Status())
+
+		clients.UsageDao.ConsumeRepos("client", 4, 10)
+		usage, err = ClientsService.GetUsage("client", "client")
+		assert.Nil(t, err)
+		assert.EqualValues(t, 10, usage.Quotas.ReposPerDay)
+		assert.EqualValues(t, 4, usage.ReposCreated)
+		assert.EqualValues(t, 6, usage.ReposRemaining)
+	})
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	stderrors "errors"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/credentials"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/providers/github_provider"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// installation tokens are renewed this long before they expire so that
+	// requests started with them do not fail halfway
+	installationTokenMargin = 5 * time.Minute
+)
+
+type credentialsService struct {
+	lock   sync.Mutex
+	tokens map[string]github.InstallationToken
+	now    func() time.Time
+}
+
+type credentialsServiceInterface interface {
+	AccessToken(clientId string) (string, errors.ApiError)
+}
+
+var (
+	CredentialsService credentialsServiceInterface
+)
+
+func init() {
+	CredentialsService = &credentialsService{
+		tokens: make(map[string]github.InstallationToken),
+		now:    time.Now,
+	}
+}
+
+// AccessToken returns the GitHub token to act on behalf of clientId. Without
+// client credentials every client shares the configured token; with them,
+// clients that have none are refused.
+func (s *credentialsService) AccessToken(clientId string) (string, errors.ApiError) {
+	if credentials.CredentialsDao == nil {
+		token, err := config.GetGithubAccessToken()
+		if err != nil {
+			return "", errors.Wrap(err, http.StatusInternalServerError, "error when trying to read the GitHub access token")
+		}
+		return token, nil
+	}
+	if clientId == "" {
+		return "", errors.NewUnauthorizedError("X-Client-Id header is required")
+	}
+
+	credential, err := credentials.CredentialsDao.Get(clientId)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrNotFound) {
+			return "", errors.NewUnauthorizedError(fmt.Sprintf("unknown client %s", clientId))
+		}
+		return "", err
+	}
+
+	if credential.Type != credentials.TypeGithubApp {
+		return credential.Token, nil
+	}
+	return s.installationToken(clientId, *credential)
+}
+
+// installationToken returns the cached token of the installation, asking
+// GitHub for a new one when it is about to expire.
+func (s *credentialsService) installationToken(clientId string, credential credentials.Credential) (string, errors.ApiError) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if cached, exists := s.tokens[clientId]; exists && s.now().Add(installationTokenMargin).Before(cached.ExpiresAt) {
+		return cached.Token, nil
+	}
+
+	token, err := github_provider.CreateInstallationToken(credential.AppId, credential.PrivateKey, credential.InstallationId)
+	if err != nil {
+		return "", errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	s.tokens[clientId] = *token
+	return token.Token, nil
+}
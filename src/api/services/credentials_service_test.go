@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/credentials"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/crypto"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/secrets"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// withCredentials runs test with a secret store holding saved.
+func withCredentials(t *testing.T, saved map[string]credentials.Credential, test func()) {
+	key, _ := crypto.NewKey()
+	store, err := secrets.NewFileStore(filepath.Join(t.TempDir(), "secrets.json"), key)
+	assert.Nil(t, err)
+	dao := credentials.NewSecretsDao(store)
+	for clientId, credential := range saved {
+		assert.Nil(t, dao.Save(clientId, credential))
+	}
+
+	defer func() { credentials.CredentialsDao = nil }()
+	credentials.CredentialsDao = dao
+	test()
+}
+
+func newCredentialsService(now time.Time) *credentialsService {
+	return &credentialsService{
+		tokens: make(map[string]github.InstallationToken),
+		now:    func() time.Time { return now },
+	}
+}
+
+func TestAccessTokenSharedToken(t *testing.T) {
+	token, err := newCredentialsService(time.Now()).AccessToken("anyone")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "", token)
+}
+
+func TestAccessTokenUnknownClient(t *testing.T) {
+	withCredentials(t, nil, func() {
+		service := newCredentialsService(time.Now())
+
+		token, err := service.AccessToken("")
+		assert.EqualValues(t, "", token)
+		assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+		assert.EqualValues(t, "X-Client-Id header is required", err.Message())
+
+		token, err = service.AccessToken("stranger")
+		assert.EqualValues(t, "", token)
+		assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+		assert.EqualValues(t, "unknown client stranger", err.Message())
+	})
+}
+
+func TestAccessTokenPersonalAccessToken(t *testing.T) {
+	withCredentials(t, map[string]credentials.Credential{
+		"team-a": {Type: credentials.TypePersonalAccessToken, Token: "ghp_a"},
+		"team-b": {Type: credentials.TypeToken, Token: "gho_b"},
+	}, func() {
+		service := newCredentialsService(time.Now())
+
+		token, err := service.AccessToken("team-a")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "ghp_a", token)
+
+		token, err = service.AccessToken("team-b")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "gho_b", token)
+	})
+}
+
+func TestAccessTokenGithubAppIsCached(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	privateKey := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+
+	withCredentials(t, map[string]credentials.Credential{
+		"team-app": {Type: credentials.TypeGithubApp, AppId: 1, InstallationId: 7, PrivateKey: privateKey},
+	}, func() {
+		restclient.FlushMocks()
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/app/installations/7/access_tokens",
+			HttpMethod: http.MethodPost,
+			BodyText:   `{"token": "ghs_first","expires_at": "2021-10-01T01:00:00Z"}`,
+			Response:   &http.Response{StatusCode: http.StatusCreated},
+		})
+		service := newCredentialsService(time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC))
+
+		token, err := service.AccessToken("team-app")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "ghs_first", token)
+
+		restclient.FlushMocks()
+		token, err = service.AccessToken("team-app")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "ghs_first", token)
+
+		service.now = func() time.Time { return time.Date(2021, 10, 1, 0, 56, 0, 0, time.UTC) }
+		token, err = service.AccessToken("team-app")
+		assert.EqualValues(t, "", token)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCreateRepoUsesClientCredential(t *testing.T) {
+	withCredentials(t, nil, func() {
+		result, err := RepositoryService.CreateRepo("stranger", repositories.CreateRepoRequest{Name: "testing"})
+		assert.Nil(t, result)
+		assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+
+		batch, err := RepositoryService.CreateRepos(context.Background(), "stranger", []repositories.CreateRepoRequest{{Name: "testing"}}, repositories.CreateReposOptions{})
+		assert.Nil(t, batch.Results)
+		assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+	})
+}
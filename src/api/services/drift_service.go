@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/drift"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type driftService struct {
+	lock sync.Mutex
+}
+
+type driftServiceInterface interface {
+	Manage(clientId string, owner string, spec manifests.RepositorySpec)
+	Unmanage(owner string, name string)
+	UpdateSpec(owner string, name string, update func(spec *manifests.RepositorySpec))
+	Move(owner string, name string, newOwner string, newName string)
+	Check() []drift.Report
+	RunCheck(callerId string) ([]drift.Report, errors.ApiError)
+	GetReports(callerId string, clientId string, driftedOnly bool) ([]drift.Report, errors.ApiError)
+}
+
+var (
+	DriftService driftServiceInterface
+)
+
+func init() {
+	DriftService = &driftService{}
+}
+
+// StartDriftReconciler restores the managed repositories from the audit store,
+// unless they were persisted before a restart, and checks them for drift
+// every interval until ctx is done.
+func StartDriftReconciler(ctx context.Context, interval time.Duration) {
+	if !drift.DriftDao.Restored() {
+		restoreManagedRepositories()
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				DriftService.Check()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// restoreManagedRepositories replays the audit records so that repositories
+// created before a restart, and not deleted since, are watched again with the
+// settings they were last updated to. Existing repositories a manifest
+// started managing have no creation to replay, which is why managed
+// repositories are better persisted.
+func restoreManagedRepositories() {
+	records, err := audit.Store.Find(audit.RecordFilter{Status: audit.StatusSucceeded})
+	if err != nil {
+		option_b.Error("error when trying to restore managed repositories", err)
+		return
+	}
+	for _, record := range records {
+		switch {
+		case record.Action == audit.ActionCreate && record.Request != nil:
+			DriftService.Manage(record.ClientId, record.Owner, createdRepoSpec(*record.Request))
+		case record.Action == audit.ActionDelete:
+			DriftService.Unmanage(record.Owner, record.Name)
+		case record.Action == audit.ActionRename || record.Action == audit.ActionTransfer:
+			DriftService.Move(record.Owner, record.Name, record.NewOwner, record.NewName)
+		case record.Action == audit.ActionUpdate && record.Changes != nil:
+			changes := *record.Changes
+			DriftService.UpdateSpec(record.Owner, record.Name, func(spec *manifests.RepositorySpec) {
+				applyChanges(spec, changes)
+			})
+		}
+	}
+}
+
+func (s *driftService) Manage(clientId string, owner string, spec manifests.RepositorySpec) {
+	drift.DriftDao.Manage(drift.ManagedRepository{ClientId: clientId, Owner: owner, Spec: spec})
+}
+
+func (s *driftService) Unmanage(owner string, name string) {
+	drift.DriftDao.Unmanage(owner, name)
+}
+
+// UpdateSpec changes the expected configuration of a managed repository, so
+// changes made through the service are not reported, or reverted, as drift.
+func (s *driftService) UpdateSpec(owner string, name string, update func(spec *manifests.RepositorySpec)) {
+	drift.DriftDao.Update(owner, name, func(repository *drift.ManagedRepository) {
+		update(&repository.Spec)
+	})
+}
+
+// applyChanges sets the fields of spec an update changed.
+func applyChanges(spec *manifests.RepositorySpec, changes audit.RepositoryChanges) {
+	if changes.Description != nil {
+		spec.Description = changes.Description
+	}
+	if changes.Homepage != nil {
+		spec.Homepage = changes.Homepage
+	}
+	if changes.Private != nil {
+		spec.Private = changes.Private
+	}
+	if changes.HasIssues != nil {
+		spec.HasIssues = changes.HasIssues
+	}
+	if changes.HasProjects != nil {
+		spec.HasProjects = changes.HasProjects
+	}
+	if changes.HasWiki != nil {
+		spec.HasWiki = changes.HasWiki
+	}
+	if changes.Topics != nil {
+		spec.Topics = *changes.Topics
+	}
+	for login, permission := range changes.Collaborators {
+		if spec.Collaborators == nil {
+			spec.Collaborators = make(map[string]string)
+		}
+		spec.Collaborators[login] = permission
+	}
+	for _, protection := range changes.BranchProtection {
+		spec.SetBranchProtection(manifests.BranchProtectionSpec(protection))
+	}
+}
+
+// Move keeps watching a managed repository once it is renamed or transferred.
+func (s *driftService) Move(owner string, name string, newOwner string, newName string) {
+	drift.DriftDao.Update(owner, name, func(repository *drift.ManagedRepository) {
+		repository.Owner = newOwner
+		repository.Spec.Name = newName
+	})
+}
+
+// Check compares every managed repository with its expected configuration,
+// reverting the drift of the fields configured for remediation. Checks never
+// overlap.
+func (s *driftService) Check() []drift.Report {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	remediateFields := config.GetDriftRemediateFields()
+	result := make([]drift.Report, 0)
+	for _, repository := range drift.DriftDao.GetManaged() {
+		report := s.check(repository, remediateFields)
+		drift.DriftDao.SaveReport(report)
+		result = append(result, report)
+	}
+	return result
+}
+
+func (s *driftService) check(repository drift.ManagedRepository, remediateFields []string) drift.Report {
+	report := drift.Report{
+		ClientId:  repository.ClientId,
+		Owner:     repository.Owner,
+		Name:      repository.Spec.Name,
+		CheckedAt: time.Now().UTC(),
+	}
+
+	var state *manifests.RepositoryState
+	token, err := CredentialsService.AccessToken(repository.ClientId)
+	if err == nil {
+		state, err = getRepositoryState(token, repository.Owner, repository.Spec)
+	}
+	if err != nil {
+		option_b.Error("error when trying to check repository drift", err,
+			option_b.Field("client_id", report.ClientId),
+			option_b.Field("repository", report.Owner+"/"+report.Name))
+		report.Error = err
+		return report
+	}
+	if state == nil {
+		report.Drifted = true
+		report.Error = errors.NewNotFoundApiError("repository no longer exists")
+		option_b.Info("managed repository is missing",
+			option_b.Field("event", "drift_detected"),
+			option_b.Field("client_id", report.ClientId),
+			option_b.Field("repository", report.Owner+"/"+report.Name))
+		return report
+	}
+
+	plan := manifests.Diff(repository.Owner, repository.Spec, state)
+	report.Changes = plan.Changes
+	report.Drifted = len(plan.Changes) > 0
+	if !report.Drifted {
+		return report
+	}
+
+	fields := make([]string, 0, len(plan.Changes))
+	remediation := plan
+	remediation.Changes = nil
+	for _, change := range plan.Changes {
+		fields = append(fields, change.Field)
+		if drift.ShouldRemediate(change.Field, remediateFields) {
+			remediation.Changes = append(remediation.Changes, change)
+		}
+	}
+	option_b.Info("repository drift detected",
+		option_b.Field("event", "drift_detected"),
+		option_b.Field("client_id", report.ClientId),
+		option_b.Field("repository", report.Owner+"/"+report.Name),
+		option_b.Field("fields", fields))
+
+	if len(remediation.Changes) == 0 {
+		return report
+	}
+	if _, err := applyRepositoryPlan(report.ClientId, token, remediation, repository.Spec); err != nil {
+		report.Error = err
+		return report
+	}
+	for _, change := range remediation.Changes {
+		report.Remediated = append(report.Remediated, change.Field)
+	}
+	option_b.Info("repository drift remediated",
+		option_b.Field("event", "drift_remediated"),
+		option_b.Field("client_id", report.ClientId),
+		option_b.Field("repository", report.Owner+"/"+report.Name),
+		option_b.Field("fields", report.Remediated))
+	return report
+}
+
+// RunCheck runs a check on behalf of callerId. Since it may revert changes of
+// every client, only admins can run it.
+func (s *driftService) RunCheck(callerId string) ([]drift.Report, errors.ApiError) {
+	if !clients.ClientRegistry.Get(callerId).Admin {
+		return nil, errors.NewApiError(http.StatusForbidden, "only admins can run drift checks")
+	}
+	return s.Check(), nil
+}
+
+// GetReports returns the latest reports, only those of clientId when set.
+// Admins read the reports of every client, everybody else only their own.
+func (s *driftService) GetReports(callerId string, clientId string, driftedOnly bool) ([]drift.Report, errors.ApiError) {
+	if !clients.ClientRegistry.Get(callerId).Admin {
+		if clientId != "" && clientId != callerId {
+			return nil, errors.NewApiError(http.StatusForbidden, "clients can only read their own drift reports")
+		}
+		clientId = callerId
+	}
+
+	result := make([]drift.Report, 0)
+	for _, report := range drift.DriftDao.GetReports() {
+		if clientId != "" && report.ClientId != clientId {
+			continue
+		}
+		if driftedOnly && !report.Drifted {
+			continue
+		}
+		result = append(result, report)
+	}
+	return result, nil
+}
+
+// createdRepoSpec returns the configuration CreateRepo gives a repository,
+// settings of its preset included.
+func createdRepoSpec(request repositories.CreateRepoRequest) manifests.RepositorySpec {
+	private := request.IsPrivate()
+	result := manifests.RepositorySpec{
+		Name:        request.Name,
+		Description: &request.Description,
+		Homepage:    &request.Homepage,
+		Private:     &private,
+	}
+	if len(request.Topics) > 0 {
+		result.Topics = manifests.NormalizeTopics(request.Topics)
+	}
+	for _, protection := range request.BranchProtection {
+		result.BranchProtection = append(result.BranchProtection, manifests.BranchProtectionSpec(protection))
+	}
+	return result
+}
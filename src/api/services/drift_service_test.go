@@ -0,0 +1,202 @@
+package services
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/drift"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func resetManagedRepositories() {
+	for _, repository := range drift.DriftDao.GetManaged() {
+		drift.DriftDao.Unmanage(repository.Owner, repository.Spec.Name)
+	}
+}
+
+func TestDriftCheckNoDrift(t *testing.T) {
+	resetManagedRepositories()
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1,"name": "testing","description": "a test"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	DriftService.Manage("client", "EBKopec", createdRepoSpec(repositories.CreateRepoRequest{Name: "testing", Description: "a test"}))
+
+	reports := DriftService.Check()
+	assert.EqualValues(t, 1, len(reports))
+	assert.False(t, reports[0].Drifted)
+	assert.Nil(t, reports[0].Error)
+	assert.EqualValues(t, 0, len(getReports("", true)))
+	assert.EqualValues(t, 1, len(getReports("client", false)))
+	assert.EqualValues(t, 0, len(getReports("other", false)))
+}
+
+func TestDriftCheckReportsDrift(t *testing.T) {
+	resetManagedRepositories()
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1,"name": "testing","description": "changed in the ui","private": true}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	DriftService.Manage("client", "EBKopec", createdRepoSpec(repositories.CreateRepoRequest{Name: "testing", Description: "a test"}))
+
+	reports := DriftService.Check()
+	assert.EqualValues(t, 1, len(reports))
+	assert.True(t, reports[0].Drifted)
+	assert.EqualValues(t, []manifests.Change{
+		{Field: "description", From: "changed in the ui", To: "a test"},
+		{Field: "private", From: true, To: false},
+	}, reports[0].Changes)
+	assert.EqualValues(t, 0, len(reports[0].Remediated))
+	assert.EqualValues(t, 1, len(getReports("client", true)))
+}
+
+func TestDriftCheckRemediates(t *testing.T) {
+	os.Setenv("DRIFT_REMEDIATE_FIELDS", "private")
+	defer os.Unsetenv("DRIFT_REMEDIATE_FIELDS")
+
+	resetManagedRepositories()
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1,"name": "testing","description": "changed in the ui","private": true}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing",
+		HttpMethod: http.MethodPatch,
+		BodyText:   `{"id": 1,"name": "testing","description": "changed in the ui","private": false}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	DriftService.Manage("client", "EBKopec", createdRepoSpec(repositories.CreateRepoRequest{Name: "testing", Description: "a test"}))
+
+	reports := DriftService.Check()
+	assert.EqualValues(t, 1, len(reports))
+	assert.True(t, reports[0].Drifted)
+	assert.Nil(t, reports[0].Error)
+	assert.EqualValues(t, []string{"private"}, reports[0].Remediated)
+}
+
+func TestDriftCheckMissingRepository(t *testing.T) {
+	resetManagedRepositories()
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+	DriftService.Manage("client", "EBKopec", manifests.RepositorySpec{Name: "testing"})
+
+	reports := DriftService.Check()
+	assert.True(t, reports[0].Drifted)
+	assert.EqualValues(t, http.StatusNotFound, reports[0].Error.Status())
+	assert.EqualValues(t, "repository no longer exists", reports[0].Error.Message())
+}
+
+func TestRestoreManagedRepositories(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+	resetManagedRepositories()
+
+	created := audit.NewRepositoryRecord("client", audit.ActionCreate, "EBKopec", "kept", http.StatusCreated, nil)
+	created.Request = &repositories.CreateRepoRequest{Name: "kept"}
+	audit.Store.Save(created)
+	deleted := audit.NewRepositoryRecord("client", audit.ActionCreate, "EBKopec", "deleted", http.StatusCreated, nil)
+	deleted.Request = &repositories.CreateRepoRequest{Name: "deleted"}
+	audit.Store.Save(deleted)
+	audit.Store.Save(audit.NewRepositoryRecord("client", audit.ActionDelete, "EBKopec", "deleted", http.StatusNoContent, nil))
+
+	restoreManagedRepositories()
+	managed := drift.DriftDao.GetManaged()
+	assert.EqualValues(t, 1, len(managed))
+	assert.EqualValues(t, "kept", managed[0].Spec.Name)
+	assert.EqualValues(t, "client", managed[0].ClientId)
+}
+
+// getReports returns the reports callerId can read.
+func getReports(callerId string, driftedOnly bool) []drift.Report {
+	reports, _ := DriftService.GetReports(callerId, "", driftedOnly)
+	return reports
+}
+
+func TestGetReportsOfAnotherClient(t *testing.T) {
+	resetManagedRepositories()
+	DriftService.Manage("client", "EBKopec", manifests.RepositorySpec{Name: "testing"})
+	drift.DriftDao.SaveReport(drift.Report{ClientId: "client", Owner: "EBKopec", Name: "testing"})
+
+	reports, err := DriftService.GetReports("other", "client", false)
+	assert.Nil(t, reports)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "clients can only read their own drift reports", err.Message())
+
+	withClients([]clients.Client{{Id: "admin", Admin: true}}, func() {
+		reports, err = DriftService.GetReports("admin", "client", false)
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, len(reports))
+	})
+}
+
+func TestRunCheckRequiresAdmin(t *testing.T) {
+	resetManagedRepositories()
+
+	reports, err := DriftService.RunCheck("client")
+	assert.Nil(t, reports)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, err.Status())
+	assert.EqualValues(t, "only admins can run drift checks", err.Message())
+
+	withClients([]clients.Client{{Id: "admin", Admin: true}}, func() {
+		reports, err = DriftService.RunCheck("admin")
+		assert.Nil(t, err)
+		assert.EqualValues(t, 0, len(reports))
+	})
+}
+
+func TestRestoreManagedRepositoriesReplaysUpdates(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+	resetManagedRepositories()
+
+	created := audit.NewRepositoryRecord("client", audit.ActionCreate, "EBKopec", "testing", http.StatusCreated, nil)
+	created.Request = &repositories.CreateRepoRequest{Name: "testing", Description: "a test"}
+	audit.Store.Save(created)
+	description, topics, private := "updated", []string{"go"}, true
+	updated := audit.NewRepositoryRecord("client", audit.ActionUpdate, "EBKopec", "testing", http.StatusOK, nil)
+	updated.Changes = &audit.RepositoryChanges{
+		Description:      &description,
+		Private:          &private,
+		Topics:           &topics,
+		Collaborators:    map[string]string{"octocat": "push"},
+		BranchProtection: []repositories.BranchProtection{{Branch: "main", RequiredApprovingReviews: 1}},
+	}
+	audit.Store.Save(updated)
+	failed := audit.NewRepositoryRecord("client", audit.ActionUpdate, "EBKopec", "testing", http.StatusOK, errors.NewApiError(http.StatusForbidden, "denied"))
+	failed.Changes = &audit.RepositoryChanges{Topics: &[]string{}}
+	audit.Store.Save(failed)
+
+	restoreManagedRepositories()
+	managed := drift.DriftDao.GetManaged()
+	assert.EqualValues(t, 1, len(managed))
+	assert.EqualValues(t, "updated", *managed[0].Spec.Description)
+	assert.EqualValues(t, "", *managed[0].Spec.Homepage)
+	assert.EqualValues(t, []string{"go"}, managed[0].Spec.Topics)
+	assert.True(t, *managed[0].Spec.Private)
+	assert.EqualValues(t, map[string]string{"octocat": "push"}, managed[0].Spec.Collaborators)
+	assert.EqualValues(t, []manifests.BranchProtectionSpec{{Branch: "main", RequiredApprovingReviews: 1}}, managed[0].Spec.BranchProtection)
+}
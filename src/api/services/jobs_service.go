@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/jobs"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+	"sync"
+)
+
+type jobsService struct {
+	repos   *reposService
+	lock    sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+type jobsServiceInterface interface {
+	CreateJob(clientId string, requests []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (*jobs.Job, errors.ApiError)
+	GetJob(clientId string, jobId string) (*jobs.Job, errors.ApiError)
+	CancelJob(clientId string, jobId string) (*jobs.Job, errors.ApiError)
+}
+
+var (
+	JobsService jobsServiceInterface
+)
+
+func init() {
+	JobsService = &jobsService{
+		repos:   &reposService{},
+		cancels: make(map[string]context.CancelFunc),
+	}
+}
+
+// CreateJob validates the batch and processes it in the background, returning
+// the pending job right away. Atomic batches can not be processed that way.
+func (s *jobsService) CreateJob(clientId string, requests []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (*jobs.Job, errors.ApiError) {
+	if err := validateBatch(clientId, requests); err != nil {
+		return nil, err
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	if options.Atomic {
+		return nil, errors.NewBadRequestError("atomic batches can not be processed asynchronously")
+	}
+	if _, err := CredentialsService.AccessToken(clientId); err != nil {
+		return nil, err
+	}
+	if err := clients.UsageDao.StartJob(clientId, clients.ClientRegistry.Get(clientId).Quotas.MaxConcurrentJobs); err != nil {
+		return nil, err
+	}
+	day, err := consumeRepoQuota(clientId, len(requests))
+	if err != nil {
+		clients.UsageDao.FinishJob(clientId)
+		return nil, err
+	}
+
+	job, err := s.saveJob(clientId, requests, options)
+	if err != nil {
+		clients.UsageDao.RefundRepos(clientId, day, len(requests))
+		clients.UsageDao.FinishJob(clientId)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.lock.Lock()
+	s.cancels[job.Id] = cancel
+	s.lock.Unlock()
+
+	go s.process(ctx, job.Id, clientId, day, requests, options)
+	return job, nil
+}
+
+func (s *jobsService) saveJob(clientId string, requests []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (*jobs.Job, errors.ApiError) {
+	jobId, err := newJobId()
+	if err != nil {
+		return nil, errors.Wrap(err, http.StatusInternalServerError, "error when trying to create job id")
+	}
+	job := jobs.NewJob(jobId, clientId, requests)
+	job.Options = options
+	if err := jobs.JobDao.Save(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *jobsService) GetJob(clientId string, jobId string) (*jobs.Job, errors.ApiError) {
+	job, err := jobs.JobDao.Get(jobId)
+	if err != nil {
+		return nil, err
+	}
+	// jobs of other clients are not disclosed
+	if job.ClientId != clientId {
+		return nil, errors.NewNotFoundApiError(fmt.Sprintf("job %s not found", jobId))
+	}
+	return job, nil
+}
+
+// CancelJob stops dispatching the remaining items of a job. Items already
+// running are left to finish.
+func (s *jobsService) CancelJob(clientId string, jobId string) (*jobs.Job, errors.ApiError) {
+	job, err := s.GetJob(clientId, jobId)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFinished() {
+		return nil, errors.NewConflictError(fmt.Sprintf("job %s already finished", jobId))
+	}
+
+	s.lock.Lock()
+	cancel := s.cancels[jobId]
+	s.lock.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	option_b.Info("job cancellation requested",
+		option_b.Field("client_id", clientId),
+		option_b.Field("job_id", jobId))
+	return job, nil
+}
+
+func (s *jobsService) process(ctx context.Context, jobId string, clientId string, day string, requests []repositories.CreateRepoRequest, options repositories.CreateReposOptions) {
+	jobs.JobDao.Update(jobId, func(job *jobs.Job) { job.Start() })
+
+	// the job is already accepted, so when GitHub can not tell which names
+	// exist every item left fails with the reason
+	skipped, err := screenBatch(clientId, requests, options)
+	if err != nil {
+		for index := range requests {
+			if _, isSkipped := skipped[index]; !isSkipped {
+				skipped[index] = repositories.CreateRepositoriesResult{Index: index, Error: err}
+			}
+		}
+	}
+	input := make(chan repositories.CreateRepositoriesResult)
+	go s.repos.dispatchRepos(ctx, clientId, day, requests, skipped, input, func(index int) {
+		jobs.JobDao.Update(jobId, func(job *jobs.Job) { job.StartItem(index) })
+	})
+
+	for i := 0; i < len(requests); i++ {
+		result := <-input
+		cancelled := result.Response == nil && stderrors.Is(result.Error, context.Canceled)
+		jobs.JobDao.Update(jobId, func(job *jobs.Job) { job.FinishItem(result, cancelled) })
+	}
+
+	// the slot of the job is released before it shows up as finished, so a
+	// client can start another job as soon as it sees this one done
+	s.lock.Lock()
+	cancel := s.cancels[jobId]
+	delete(s.cancels, jobId)
+	s.lock.Unlock()
+	cancel()
+	clients.UsageDao.FinishJob(clientId)
+
+	job, _ := jobs.JobDao.Update(jobId, func(job *jobs.Job) { job.Finish() })
+	option_b.Info("job finished",
+		option_b.Field("client_id", clientId),
+		option_b.Field("job_id", jobId),
+		option_b.Field("state", job.State),
+		option_b.Field("succeeded", job.Counts.Succeeded),
+		option_b.Field("failed", job.Counts.Failed),
+		option_b.Field("skipped", job.Counts.Skipped),
+		option_b.Field("cancelled", job.Counts.Cancelled))
+}
+
+func newJobId() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/jobs"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func waitForJob(t *testing.T, clientId string, jobId string) *jobs.Job {
+	for i := 0; i < 100; i++ {
+		job, err := JobsService.GetJob(clientId, jobId)
+		assert.Nil(t, err)
+		if job.IsFinished() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", jobId)
+	return nil
+}
+
+func TestCreateJobInvalidBatch(t *testing.T) {
+	job, err := JobsService.CreateJob("client", nil, repositories.CreateReposOptions{})
+	assert.Nil(t, job)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestCreateJobProcessesInBackground(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "id": 123, "name":"testing", "owner":{"login":"EBKopec" }}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+
+	job, err := JobsService.CreateJob("client", []repositories.CreateRepoRequest{{Name: "testing"}, {}}, repositories.CreateReposOptions{})
+	assert.Nil(t, err)
+	assert.NotNil(t, job)
+	assert.EqualValues(t, jobs.StatePending, job.State)
+	assert.EqualValues(t, 32, len(job.Id))
+
+	finished := waitForJob(t, "client", job.Id)
+	assert.EqualValues(t, jobs.StateCompleted, finished.State)
+	assert.EqualValues(t, jobs.Counts{Succeeded: 1, Failed: 1}, finished.Counts)
+	assert.EqualValues(t, jobs.StateSucceeded, finished.Items[0].State)
+	assert.EqualValues(t, 123, finished.Items[0].Repo.Id)
+	assert.EqualValues(t, jobs.StateFailed, finished.Items[1].State)
+	assert.EqualValues(t, "invalid repository name", finished.Items[1].Error.Message())
+	assert.NotNil(t, finished.StartedAt)
+	assert.NotNil(t, finished.FinishedAt)
+
+	_, cancelErr := JobsService.CancelJob("client", job.Id)
+	assert.NotNil(t, cancelErr)
+	assert.EqualValues(t, http.StatusConflict, cancelErr.Status())
+}
+
+func TestGetJobFromAnotherClient(t *testing.T) {
+	job := jobs.NewJob("other-client-job", "client-a", []repositories.CreateRepoRequest{{Name: "a"}})
+	jobs.JobDao.Save(job)
+
+	result, err := JobsService.GetJob("client-b", job.Id)
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	assert.EqualValues(t, "job other-client-job not found", err.Message())
+}
+
+func TestProcessCancelledJob(t *testing.T) {
+	requests := []repositories.CreateRepoRequest{{Name: "a"}, {Name: "b"}}
+	job := jobs.NewJob("cancelled-job", "client", requests)
+	jobs.JobDao.Save(job)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	service := &jobsService{repos: &reposService{}, cancels: map[string]context.CancelFunc{job.Id: cancel}}
+	cancel()
+	service.process(ctx, job.Id, "client", "", requests, repositories.CreateReposOptions{})
+
+	result, err := service.GetJob("client", job.Id)
+	assert.Nil(t, err)
+	assert.EqualValues(t, jobs.StateCancelled, result.State)
+	assert.EqualValues(t, jobs.Counts{Cancelled: 2}, result.Counts)
+	assert.EqualValues(t, 0, len(service.cancels))
+}
+
+func TestCreateJobCollapsesDuplicates(t *testing.T) {
+	addCreateRepoMock()
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: " Testing "}}
+
+	job, err := JobsService.CreateJob("client", requests, repositories.CreateReposOptions{Duplicates: repositories.DuplicatesCollapse})
+	assert.Nil(t, err)
+	assert.EqualValues(t, repositories.DuplicatesCollapse, job.Options.Duplicates)
+
+	finished := waitForJob(t, "client", job.Id)
+	assert.EqualValues(t, jobs.Counts{Succeeded: 1, Skipped: 1}, finished.Counts)
+	assert.EqualValues(t, jobs.StateSkipped, finished.Items[1].State)
+	assert.EqualValues(t, repositories.SkipReasonDuplicate, finished.Items[1].Skipped)
+	assert.EqualValues(t, 0, *finished.Items[1].DuplicateOf)
+	assert.Nil(t, finished.Items[1].Error)
+}
+
+func TestCreateJobChecksExisting(t *testing.T) {
+	addCreateRepoMock()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/graphql",
+		HttpMethod: http.MethodPost,
+		BodyText: `{
+			"data": {"r0": {"id": "R_1"}, "r1": null, "rateLimit": {"cost": 1, "remaining": 4999}},
+			"errors": [{"type": "NOT_FOUND", "path": ["r1"], "message": "Could not resolve to a Repository with the name 'EBKopec/testing-2'."}]
+		}`,
+		Response: &http.Response{StatusCode: http.StatusOK},
+	})
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: "testing-2"}}
+
+	job, err := JobsService.CreateJob("client", requests, repositories.CreateReposOptions{CheckExisting: true})
+	assert.Nil(t, err)
+	assert.True(t, job.Options.CheckExisting)
+
+	finished := waitForJob(t, "client", job.Id)
+	assert.EqualValues(t, jobs.Counts{Succeeded: 1, Failed: 1}, finished.Counts)
+	assert.EqualValues(t, jobs.StateFailed, finished.Items[0].State)
+	assert.EqualValues(t, repositories.SkipReasonExists, finished.Items[0].Skipped)
+	assert.EqualValues(t, "repository EBKopec/testing already exists", finished.Items[0].Error.Message())
+	assert.EqualValues(t, jobs.StateSucceeded, finished.Items[1].State)
+}
+
+func TestCreateJobAtomic(t *testing.T) {
+	job, err := JobsService.CreateJob("client", []repositories.CreateRepoRequest{{Name: "testing"}}, repositories.CreateReposOptions{Atomic: true})
+	assert.Nil(t, job)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "atomic batches can not be processed asynchronously", err.Message())
+}
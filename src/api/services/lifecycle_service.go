@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/policies"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
+	"github.com/evertonkopec/golang-microservices-main/src/api/providers/github_provider"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+	"strings"
+)
+
+type lifecycleService struct{}
+
+type lifecycleServiceInterface interface {
+	UpdateRepo(clientId string, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.RepositoryMetadata, errors.ApiError)
+	TransferRepo(clientId string, owner string, name string, request repositories.TransferRepoRequest) (*repositories.TransferRepoResponse, errors.ApiError)
+}
+
+var (
+	LifecycleService lifecycleServiceInterface
+)
+
+func init() {
+	LifecycleService = &lifecycleService{}
+}
+
+// UpdateRepo changes the metadata of the repository, renames it and archives
+// or unarchives it in a single call to GitHub. Every change is audited,
+// successful or not.
+func (s *lifecycleService) UpdateRepo(clientId string, owner string, name string, request repositories.UpdateRepoRequest) (*repositories.RepositoryMetadata, errors.ApiError) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if request.Name != nil {
+		if err := checkNamePolicy(clientId, *request.Name); err != nil {
+			recordLifecycle(clientId, owner, name, request, err)
+			return nil, err
+		}
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	update := github.UpdateRepoRequest{
+		Description: request.Description,
+		Homepage:    request.Homepage,
+		Name:        request.Name,
+		Archived:    request.Archived,
+	}
+	repository, err := github_provider.UpdateRepo(token, owner, name, update)
+	if err != nil {
+		apiErr = lifecycleError(updateAction(request), owner, name, err)
+	}
+	recordLifecycle(clientId, owner, name, request, apiErr)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	if request.Name != nil {
+		DriftService.Move(owner, name, owner, repository.Name)
+	}
+	DriftService.UpdateSpec(owner, repository.Name, func(spec *manifests.RepositorySpec) {
+		applyChanges(spec, metadataChanges(request))
+	})
+
+	return &repositories.RepositoryMetadata{
+		Owner:       repository.Owner.Login,
+		Name:        repository.Name,
+		Description: repository.Description,
+		Homepage:    repository.Homepage,
+		Topics:      repository.Topics,
+		Archived:    repository.Archived,
+	}, nil
+}
+
+// TransferRepo asks GitHub to move the repository to another owner. GitHub
+// accepts the transfer and completes it in the background.
+func (s *lifecycleService) TransferRepo(clientId string, owner string, name string, request repositories.TransferRepoRequest) (*repositories.TransferRepoResponse, errors.ApiError) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	result := repositories.TransferRepoResponse{Owner: owner, Name: name, NewOwner: request.NewOwner, NewName: request.NewName}
+	if result.NewName == "" {
+		result.NewName = name
+	}
+
+	if request.NewName != "" {
+		apiErr = checkNamePolicy(clientId, request.NewName)
+	}
+	transfer := github.TransferRepoRequest{NewOwner: request.NewOwner, NewName: request.NewName, TeamIds: request.TeamIds}
+	if apiErr == nil {
+		if _, err := github_provider.TransferRepo(token, owner, name, transfer); err != nil {
+			apiErr = lifecycleError(audit.ActionTransfer, owner, name, err)
+		}
+	}
+
+	record := audit.NewRepositoryRecord(clientId, audit.ActionTransfer, owner, name, http.StatusAccepted, apiErr)
+	record.NewOwner, record.NewName = result.NewOwner, result.NewName
+	saveRecord(record)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	DriftService.Move(owner, name, result.NewOwner, result.NewName)
+	return &result, nil
+}
+
+// checkNamePolicy fails renames to a name a policy denies or holds for
+// approval, since only creations can wait for an admin.
+func checkNamePolicy(clientId string, name string) errors.ApiError {
+	decision := policies.PolicyEngine.EvaluateName(clientId, name)
+	switch decision.Outcome {
+	case policies.OutcomeDeny:
+		return policyDeniedError(decision)
+	case policies.OutcomeRequireApproval:
+		return errors.NewApiError(http.StatusUnprocessableEntity, fmt.Sprintf("repository name %s requires approval by policy %s and can only be given at creation", name, decision.Rule))
+	}
+	return nil
+}
+
+// updateAction names what request does to the repository in error messages.
+func updateAction(request repositories.UpdateRepoRequest) string {
+	switch {
+	case request.Name != nil:
+		return audit.ActionRename
+	case request.Archived != nil && *request.Archived:
+		return audit.ActionArchive
+	case request.Archived != nil:
+		return audit.ActionUnarchive
+	}
+	return "update"
+}
+
+// recordLifecycle audits the rename, the metadata changes and the archive
+// change of request, if any. Changes are recorded under the new name of a
+// renamed repository so that they replay in order.
+func recordLifecycle(clientId string, owner string, name string, request repositories.UpdateRepoRequest, err errors.ApiError) {
+	if request.Name != nil {
+		record := audit.NewRepositoryRecord(clientId, audit.ActionRename, owner, name, http.StatusOK, err)
+		record.NewOwner, record.NewName = owner, *request.Name
+		saveRecord(record)
+		if err == nil {
+			name = *request.Name
+		}
+	}
+	if request.Description != nil || request.Homepage != nil {
+		recordUpdate(clientId, owner, name, metadataChanges(request), err)
+	}
+	if request.Archived != nil {
+		action := audit.ActionUnarchive
+		if *request.Archived {
+			action = audit.ActionArchive
+		}
+		saveRecord(audit.NewRepositoryRecord(clientId, action, owner, name, http.StatusOK, err))
+	}
+}
+
+func metadataChanges(request repositories.UpdateRepoRequest) audit.RepositoryChanges {
+	return audit.RepositoryChanges{Description: request.Description, Homepage: request.Homepage}
+}
+
+// lifecycleError tells why GitHub refused action: 422 reports the offending
+// fields, such as a name already taken by the new owner, as causes; 403 means
+// the token lacks admin rights on the repository or the repository is
+// archived and so read-only.
+func lifecycleError(action string, owner string, name string, err *github.GithubErrorResponse) errors.ApiError {
+	option_b.Error(fmt.Sprintf("error when trying to %s repository", action), err,
+		option_b.Field("repository", fmt.Sprintf("%s/%s", owner, name)))
+
+	message := fmt.Sprintf("github refused to %s %s/%s: %s", action, owner, name, err.Message)
+	switch {
+	case err.StatusCode == http.StatusUnprocessableEntity:
+		causes := make([]interface{}, 0, len(err.Errors))
+		for _, current := range err.Errors {
+			cause := repositories.FieldError{Field: current.Field, Message: current.Message}
+			if cause.Message == "" {
+				cause.Message = current.Code
+			}
+			causes = append(causes, cause)
+		}
+		return errors.NewApiErrorWithCauses(http.StatusUnprocessableEntity, message, causes...)
+	case err.StatusCode == http.StatusForbidden && !strings.Contains(strings.ToLower(err.Message), "rate limit"):
+		return errors.Wrap(err, http.StatusForbidden, message)
+	}
+	return errors.NewUpstreamError(err, err.StatusCode, err.Message)
+}
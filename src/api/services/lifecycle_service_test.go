@@ -0,0 +1,227 @@
+package services
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/drift"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/policies"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// withLifecycle runs test against a fresh audit store with EBKopec/api managed.
+func withLifecycle(test func()) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+	resetManagedRepositories()
+	defer resetManagedRepositories()
+	DriftService.Manage("client", "EBKopec", createdRepoSpec(repositories.CreateRepoRequest{Name: "api", Description: "old"}))
+
+	restclient.FlushMocks()
+	test()
+}
+
+func TestUpdateRepoMetadata(t *testing.T) {
+	withLifecycle(func() {
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/repos/EBKopec/api",
+			HttpMethod: http.MethodPatch,
+			BodyText:   `{"id": 1, "name": "api", "owner": {"login": "EBKopec"}, "description": "new", "topics": ["go"]}`,
+			Response:   &http.Response{StatusCode: http.StatusOK},
+		})
+
+		description := "new"
+		result, err := LifecycleService.UpdateRepo("client", "EBKopec", "api", repositories.UpdateRepoRequest{Description: &description})
+		assert.Nil(t, err)
+		assert.EqualValues(t, repositories.RepositoryMetadata{Owner: "EBKopec", Name: "api", Description: "new", Topics: []string{"go"}}, *result)
+		assert.EqualValues(t, "new", *drift.DriftDao.GetManaged()[0].Spec.Description)
+
+		records, _ := audit.Store.Find(audit.RecordFilter{})
+		assert.EqualValues(t, 1, len(records))
+		assert.EqualValues(t, audit.ActionUpdate, records[0].Action)
+		assert.EqualValues(t, "new", *records[0].Changes.Description)
+		assert.Nil(t, records[0].Changes.Homepage)
+	})
+}
+
+func TestUpdateRepoRenameAndDescriptionReplay(t *testing.T) {
+	withLifecycle(func() {
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/repos/EBKopec/api",
+			HttpMethod: http.MethodPatch,
+			BodyText:   `{"id": 1, "name": "service", "owner": {"login": "EBKopec"}, "description": "new"}`,
+			Response:   &http.Response{StatusCode: http.StatusOK},
+		})
+		created := audit.NewRepositoryRecord("client", audit.ActionCreate, "EBKopec", "api", http.StatusCreated, nil)
+		created.Request = &repositories.CreateRepoRequest{Name: "api", Description: "old"}
+		saveRecord(created)
+
+		description, newName := "new", "service"
+		_, err := LifecycleService.UpdateRepo("client", "EBKopec", "api", repositories.UpdateRepoRequest{Name: &newName, Description: &description})
+		assert.Nil(t, err)
+
+		updates, _ := audit.Store.Find(audit.RecordFilter{Action: audit.ActionUpdate})
+		assert.EqualValues(t, 1, len(updates))
+		assert.EqualValues(t, "service", updates[0].Name)
+
+		resetManagedRepositories()
+		restoreManagedRepositories()
+		managed := drift.DriftDao.GetManaged()
+		assert.EqualValues(t, 1, len(managed))
+		assert.EqualValues(t, "service", managed[0].Spec.Name)
+		assert.EqualValues(t, "new", *managed[0].Spec.Description)
+	})
+}
+
+func TestUpdateRepoRenameAndArchive(t *testing.T) {
+	withLifecycle(func() {
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/repos/EBKopec/api",
+			HttpMethod: http.MethodPatch,
+			BodyText:   `{"id": 1, "name": "service", "owner": {"login": "EBKopec"}, "archived": true}`,
+			Response:   &http.Response{StatusCode: http.StatusOK},
+		})
+
+		newName, archived := "service", true
+		result, err := LifecycleService.UpdateRepo("client", "EBKopec", "api", repositories.UpdateRepoRequest{Name: &newName, Archived: &archived})
+		assert.Nil(t, err)
+		assert.EqualValues(t, "service", result.Name)
+		assert.True(t, result.Archived)
+		assert.EqualValues(t, "service", drift.DriftDao.GetManaged()[0].Spec.Name)
+
+		records, _ := audit.Store.Find(audit.RecordFilter{Status: audit.StatusSucceeded})
+		assert.EqualValues(t, 2, len(records))
+		actions := []string{records[0].Action, records[1].Action}
+		assert.ElementsMatch(t, []string{audit.ActionRename, audit.ActionArchive}, actions)
+
+		renames, _ := audit.Store.Find(audit.RecordFilter{Action: audit.ActionRename})
+		assert.EqualValues(t, "api", renames[0].Name)
+		assert.EqualValues(t, "EBKopec", renames[0].NewOwner)
+		assert.EqualValues(t, "service", renames[0].NewName)
+	})
+}
+
+func TestUpdateRepoRenameNameTaken(t *testing.T) {
+	withLifecycle(func() {
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/repos/EBKopec/api",
+			HttpMethod: http.MethodPatch,
+			BodyText:   `{"message": "Repository creation failed.", "errors": [{"resource": "Repository", "code": "custom", "field": "name", "message": "name already exists on this account"}]}`,
+			Response:   &http.Response{StatusCode: http.StatusUnprocessableEntity},
+		})
+
+		newName := "taken"
+		result, err := LifecycleService.UpdateRepo("client", "EBKopec", "api", repositories.UpdateRepoRequest{Name: &newName})
+		assert.Nil(t, result)
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusUnprocessableEntity, err.Status())
+		assert.EqualValues(t, "github refused to rename EBKopec/api: Repository creation failed.", err.Message())
+		assert.EqualValues(t, []interface{}{repositories.FieldError{Field: "name", Message: "name already exists on this account"}}, err.Causes())
+		assert.EqualValues(t, "api", drift.DriftDao.GetManaged()[0].Spec.Name)
+
+		records, _ := audit.Store.Find(audit.RecordFilter{Action: audit.ActionRename, Status: audit.StatusFailed})
+		assert.EqualValues(t, 1, len(records))
+		assert.EqualValues(t, http.StatusUnprocessableEntity, records[0].StatusCode)
+	})
+}
+
+func TestUpdateRepoRenameDeniedByPolicy(t *testing.T) {
+	withLifecycle(func() {
+		withPolicies([]policies.Rule{prefixRule, productionRule}, func() {
+			newName := "service"
+			result, err := LifecycleService.UpdateRepo("team-a", "EBKopec", "api", repositories.UpdateRepoRequest{Name: &newName})
+			assert.Nil(t, result)
+			assert.EqualValues(t, http.StatusForbidden, err.Status())
+			assert.EqualValues(t, "repository request denied by policy prefix", err.Message())
+
+			newName = "prod-api"
+			_, err = LifecycleService.UpdateRepo("client", "EBKopec", "api", repositories.UpdateRepoRequest{Name: &newName})
+			assert.EqualValues(t, http.StatusUnprocessableEntity, err.Status())
+			assert.EqualValues(t, "repository name prod-api requires approval by policy production and can only be given at creation", err.Message())
+
+			records, _ := audit.Store.Find(audit.RecordFilter{Action: audit.ActionRename, Status: audit.StatusFailed})
+			assert.EqualValues(t, 2, len(records))
+			assert.EqualValues(t, "api", drift.DriftDao.GetManaged()[0].Spec.Name)
+		})
+	})
+}
+
+func TestUpdateRepoArchivedIsReadOnly(t *testing.T) {
+	withLifecycle(func() {
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/repos/EBKopec/api",
+			HttpMethod: http.MethodPatch,
+			BodyText:   `{"message": "Repository was archived so is read-only."}`,
+			Response:   &http.Response{StatusCode: http.StatusForbidden},
+		})
+
+		archived := true
+		_, err := LifecycleService.UpdateRepo("client", "EBKopec", "api", repositories.UpdateRepoRequest{Archived: &archived})
+		assert.NotNil(t, err)
+		assert.EqualValues(t, http.StatusForbidden, err.Status())
+		assert.EqualValues(t, "github refused to archive EBKopec/api: Repository was archived so is read-only.", err.Message())
+	})
+}
+
+func TestTransferRepoInvalidRequest(t *testing.T) {
+	result, err := LifecycleService.TransferRepo("client", "EBKopec", "api", repositories.TransferRepoRequest{NewOwner: "-golang", NewName: "api.git"})
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, 2, len(err.Causes()))
+}
+
+func TestTransferRepo(t *testing.T) {
+	withLifecycle(func() {
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/repos/EBKopec/api/transfer",
+			HttpMethod: http.MethodPost,
+			BodyText:   `{"id": 1, "name": "api", "owner": {"login": "EBKopec"}}`,
+			Response:   &http.Response{StatusCode: http.StatusAccepted},
+		})
+
+		result, err := LifecycleService.TransferRepo("client", "EBKopec", "api", repositories.TransferRepoRequest{NewOwner: "golang"})
+		assert.Nil(t, err)
+		assert.EqualValues(t, repositories.TransferRepoResponse{Owner: "EBKopec", Name: "api", NewOwner: "golang", NewName: "api"}, *result)
+
+		managed := drift.DriftDao.GetManaged()
+		assert.EqualValues(t, "golang", managed[0].Owner)
+
+		records, _ := audit.Store.Find(audit.RecordFilter{Action: audit.ActionTransfer})
+		assert.EqualValues(t, 1, len(records))
+		assert.EqualValues(t, http.StatusAccepted, records[0].StatusCode)
+		assert.EqualValues(t, "golang", records[0].NewOwner)
+	})
+}
+
+func TestTransferRepoNewNameDeniedByPolicy(t *testing.T) {
+	withLifecycle(func() {
+		withPolicies([]policies.Rule{prefixRule}, func() {
+			result, err := LifecycleService.TransferRepo("team-a", "EBKopec", "api", repositories.TransferRepoRequest{NewOwner: "golang", NewName: "service"})
+			assert.Nil(t, result)
+			assert.EqualValues(t, http.StatusForbidden, err.Status())
+			assert.EqualValues(t, []interface{}{repositories.FieldError{Field: "name", Message: "name must start with 'team-a-'"}}, err.Causes())
+
+			records, _ := audit.Store.Find(audit.RecordFilter{Action: audit.ActionTransfer, Status: audit.StatusFailed})
+			assert.EqualValues(t, 1, len(records))
+			assert.EqualValues(t, "EBKopec", drift.DriftDao.GetManaged()[0].Owner)
+		})
+	})
+}
+
+func TestRestoreManagedRepositoriesAfterRename(t *testing.T) {
+	withLifecycle(func() {
+		saveRecord(audit.NewRepositoryRecord("client", audit.ActionCreate, "EBKopec", "api", http.StatusCreated, nil))
+		record := audit.NewRepositoryRecord("client", audit.ActionRename, "EBKopec", "api", http.StatusOK, nil)
+		record.NewOwner, record.NewName = "EBKopec", "service"
+		saveRecord(record)
+
+		restoreManagedRepositories()
+		managed := drift.DriftDao.GetManaged()
+		assert.EqualValues(t, 1, len(managed))
+		assert.EqualValues(t, "service", managed[0].Spec.Name)
+	})
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/policies"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
+	"github.com/evertonkopec/golang-microservices-main/src/api/providers/github_provider"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+	"strings"
+)
+
+type manifestsService struct{}
+
+type manifestsServiceInterface interface {
+	Plan(clientId string, manifest manifests.Manifest) (*manifests.Plan, errors.ApiError)
+	Apply(clientId string, manifest manifests.Manifest) (*manifests.ApplyResponse, errors.ApiError)
+}
+
+var (
+	ManifestsService manifestsServiceInterface
+)
+
+func init() {
+	ManifestsService = &manifestsService{}
+}
+
+// Plan compares every repository of the manifest with what GitHub reports and
+// returns the changes needed to converge, without changing anything.
+func (s *manifestsService) Plan(clientId string, manifest manifests.Manifest) (*manifests.Plan, errors.ApiError) {
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	user, err := github_provider.GetAuthenticatedUser(token)
+	if err != nil {
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+
+	var result manifests.Plan
+	for _, spec := range manifest.Repositories {
+		state, err := getRepositoryState(token, user.Login, spec)
+		if err != nil {
+			result.Add(manifests.RepositoryPlan{Owner: user.Login, Name: spec.Name, Error: err})
+			continue
+		}
+		result.Add(manifests.Diff(user.Login, spec, state))
+	}
+
+	option_b.Info("manifest planned",
+		option_b.Field("client_id", clientId),
+		option_b.Field("create", result.Create),
+		option_b.Field("update", result.Update),
+		option_b.Field("failed", result.Failed))
+	return &result, nil
+}
+
+// Apply plans the manifest and carries the plan out repository by repository.
+// A repository stops at its first failing change; the others go on.
+func (s *manifestsService) Apply(clientId string, manifest manifests.Manifest) (*manifests.ApplyResponse, errors.ApiError) {
+	plan, err := s.Plan(clientId, manifest)
+	if err != nil {
+		return nil, err
+	}
+	token, err := CredentialsService.AccessToken(clientId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := manifests.ApplyResponse{StatusCode: http.StatusOK}
+	failed := 0
+	for index, current := range plan.Repositories {
+		applied := manifests.RepositoryResult{RepositoryPlan: current}
+		if current.Error == nil && current.Action != manifests.ActionNoOp {
+			applied.ApprovalId, applied.Error = applyRepositoryPlan(clientId, token, current, manifest.Repositories[index])
+			applied.Applied = applied.Error == nil && applied.ApprovalId == ""
+		}
+		if applied.Error != nil {
+			failed++
+		} else if applied.ApprovalId == "" {
+			DriftService.Manage(clientId, current.Owner, manifest.Repositories[index])
+		}
+		result.Repositories = append(result.Repositories, applied)
+	}
+
+	switch {
+	case failed == len(result.Repositories):
+		result.StatusCode = result.Repositories[0].Error.Status()
+	case failed > 0:
+		result.StatusCode = http.StatusPartialContent
+	}
+	return &result, nil
+}
+
+func getRepositoryState(token string, owner string, spec manifests.RepositorySpec) (*manifests.RepositoryState, errors.ApiError) {
+	repo, err := github_provider.GetRepo(token, owner, spec.Name)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	result := manifests.RepositoryState{
+		Repository:    *repo,
+		Collaborators: make(map[string]string),
+		Protections:   make(map[string]*manifests.BranchProtectionSpec),
+	}
+
+	if len(spec.Collaborators) > 0 {
+		collaborators, err := github_provider.GetCollaborators(token, owner, spec.Name)
+		if err != nil {
+			return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+		}
+		for _, collaborator := range collaborators {
+			result.Collaborators[strings.ToLower(collaborator.Login)] = collaborator.Permissions.Permission()
+		}
+	}
+
+	for _, protection := range spec.BranchProtection {
+		current, err := github_provider.GetBranchProtection(token, owner, spec.Name, protection.Branch)
+		if err != nil {
+			if err.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+		}
+		result.Protections[protection.Branch] = manifests.NewBranchProtectionSpec(protection.Branch, *current)
+	}
+	return &result, nil
+}
+
+// applyRepositoryPlan carries out the plan of one repository. It returns the
+// id of the approval the creation of the repository waits for, if any. The
+// changes made to a repository, besides its creation, are audited as an
+// update, and a change of visibility has to be allowed by the policies.
+func applyRepositoryPlan(clientId string, token string, plan manifests.RepositoryPlan, spec manifests.RepositorySpec) (string, errors.ApiError) {
+	changes := plan.Changes
+	if plan.Action == manifests.ActionCreate {
+		created, err := createManifestRepo(clientId, spec)
+		if err != nil {
+			return "", err
+		}
+		if created.ApprovalId != "" {
+			return created.ApprovalId, nil
+		}
+		if created.SettingsError != nil {
+			return "", created.SettingsError
+		}
+		changes = changesAfterCreate(plan.Changes)
+		if len(changes) == 0 {
+			return "", nil
+		}
+	}
+
+	audited := repositoryChanges(changes)
+	var err errors.ApiError
+	if audited.Private != nil {
+		err = checkVisibilityPolicy(clientId, plan.Name, *audited.Private)
+	}
+	if err == nil {
+		err = updateRepository(clientId, token, plan, changes)
+	}
+	recordUpdate(clientId, plan.Owner, plan.Name, audited, err)
+	return "", err
+}
+
+// checkVisibilityPolicy fails making an existing repository private, or
+// public, when a policy denies it or holds it for approval, since only
+// creations can wait for an admin.
+func checkVisibilityPolicy(clientId string, name string, private bool) errors.ApiError {
+	decision := policies.PolicyEngine.EvaluateVisibility(clientId, name, private)
+	switch decision.Outcome {
+	case policies.OutcomeDeny:
+		return policyDeniedError(decision)
+	case policies.OutcomeRequireApproval:
+		return errors.NewApiError(http.StatusUnprocessableEntity, fmt.Sprintf("visibility of repository %s requires approval by policy %s and can only be given at creation", name, decision.Rule))
+	}
+	return nil
+}
+
+// updateRepository applies changes to an existing repository, stopping at
+// the first one GitHub refuses.
+func updateRepository(clientId string, token string, plan manifests.RepositoryPlan, changes []manifests.Change) errors.ApiError {
+	if request, changed := updateRepoRequest(changes); changed {
+		if _, err := github_provider.UpdateRepo(token, plan.Owner, plan.Name, request); err != nil {
+			return errors.NewUpstreamError(err, err.StatusCode, err.Message)
+		}
+	}
+
+	for _, change := range changes {
+		var err *github.GithubErrorResponse
+		switch {
+		case change.Field == manifests.FieldTopics:
+			err = github_provider.ReplaceTopics(token, plan.Owner, plan.Name, change.To.([]string))
+		case strings.HasPrefix(change.Field, manifests.FieldCollaboratorPrefix):
+			login := strings.TrimPrefix(change.Field, manifests.FieldCollaboratorPrefix)
+			err = github_provider.AddCollaborator(token, plan.Owner, plan.Name, login, change.To.(string))
+		case strings.HasPrefix(change.Field, manifests.FieldBranchProtectionPrefix):
+			protection := change.To.(*manifests.BranchProtectionSpec)
+			err = github_provider.UpdateBranchProtection(token, plan.Owner, plan.Name, protection.Branch, protection.UpdateBranchProtectionRequest())
+		}
+		if err != nil {
+			option_b.Error("error when trying to apply manifest change", err,
+				option_b.Field("client_id", clientId),
+				option_b.Field("repository", plan.Name),
+				option_b.Field("field", change.Field))
+			return errors.NewUpstreamError(err, err.StatusCode, err.Message)
+		}
+	}
+	return nil
+}
+
+// createManifestRepo creates the repository the way single creations are,
+// policies and audit included. Its topics and protected branches are set
+// along with it, which initializes the repository when branches have to be
+// protected, since GitHub can not protect a branch that does not exist yet.
+func createManifestRepo(clientId string, spec manifests.RepositorySpec) (*repositories.CreateRepoResponse, errors.ApiError) {
+	day, err := consumeRepoQuota(clientId, 1)
+	if err != nil {
+		return nil, err
+	}
+	input := spec.CreateRepoRequest()
+	input.Private = spec.Private
+	input.Topics = spec.Topics
+	for _, protection := range spec.BranchProtection {
+		input.BranchProtection = append(input.BranchProtection, repositories.BranchProtection(protection))
+	}
+
+	result, err := (&reposService{}).provisionRepo(clientId, day, input)
+	if err != nil {
+		clients.UsageDao.RefundRepos(clientId, day, 1)
+		return nil, err
+	}
+	return result, nil
+}
+
+// changesAfterCreate returns the changes of the plan of a new repository that
+// its creation did not apply already.
+func changesAfterCreate(changes []manifests.Change) []manifests.Change {
+	result := make([]manifests.Change, 0, len(changes))
+	for _, change := range changes {
+		switch {
+		case change.Field == "description", change.Field == "homepage", change.Field == "private",
+			change.Field == manifests.FieldTopics, strings.HasPrefix(change.Field, manifests.FieldBranchProtectionPrefix):
+			continue
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+// repositoryChanges returns the settings changes give a repository, the way
+// they are audited.
+func repositoryChanges(changes []manifests.Change) audit.RepositoryChanges {
+	var result audit.RepositoryChanges
+	for _, change := range changes {
+		switch {
+		case change.Field == "description":
+			value := change.To.(string)
+			result.Description = &value
+		case change.Field == "homepage":
+			value := change.To.(string)
+			result.Homepage = &value
+		case change.Field == "private":
+			value := change.To.(bool)
+			result.Private = &value
+		case change.Field == "has_issues":
+			value := change.To.(bool)
+			result.HasIssues = &value
+		case change.Field == "has_projects":
+			value := change.To.(bool)
+			result.HasProjects = &value
+		case change.Field == "has_wiki":
+			value := change.To.(bool)
+			result.HasWiki = &value
+		case change.Field == manifests.FieldTopics:
+			value := change.To.([]string)
+			result.Topics = &value
+		case strings.HasPrefix(change.Field, manifests.FieldCollaboratorPrefix):
+			if result.Collaborators == nil {
+				result.Collaborators = make(map[string]string)
+			}
+			result.Collaborators[strings.TrimPrefix(change.Field, manifests.FieldCollaboratorPrefix)] = change.To.(string)
+		case strings.HasPrefix(change.Field, manifests.FieldBranchProtectionPrefix):
+			protection := change.To.(*manifests.BranchProtectionSpec)
+			result.BranchProtection = append(result.BranchProtection, repositories.BranchProtection(*protection))
+		}
+	}
+	return result
+}
+
+// updateRepoRequest returns the request changing the settings listed in
+// changes, and whether there is any.
+func updateRepoRequest(changes []manifests.Change) (github.UpdateRepoRequest, bool) {
+	var result github.UpdateRepoRequest
+	changed := false
+	for _, change := range changes {
+		switch change.Field {
+		case "description":
+			value := change.To.(string)
+			result.Description = &value
+		case "homepage":
+			value := change.To.(string)
+			result.Homepage = &value
+		case "private":
+			value := change.To.(bool)
+			result.Private = &value
+		case "has_issues":
+			value := change.To.(bool)
+			result.HasIssues = &value
+		case "has_projects":
+			value := change.To.(bool)
+			result.HasProjects = &value
+		case "has_wiki":
+			value := change.To.(bool)
+			result.HasWiki = &value
+		default:
+			continue
+		}
+		changed = true
+	}
+	return result, changed
+}
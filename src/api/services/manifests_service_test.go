@@ -0,0 +1,199 @@
+package services
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/policies"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func addManifestMocks() {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1,"login": "EBKopec"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/new-repo",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/existing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1,"name": "existing","description": "old","topics": ["go"]}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/existing/collaborators?affiliation=direct&per_page=100",
+		HttpMethod: http.MethodGet,
+		BodyText:   `[{"id": 2,"login": "octocat","permissions": {"pull": true}}]`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+}
+
+func testManifest() manifests.Manifest {
+	description := "new"
+	return manifests.Manifest{Repositories: []manifests.RepositorySpec{
+		{Name: "new-repo", Topics: []string{"go"}},
+		{Name: "existing", Description: &description, Topics: []string{"go"}, Collaborators: map[string]string{"octocat": "push"}},
+	}}
+}
+
+func TestManifestPlanInvalidManifest(t *testing.T) {
+	result, err := ManifestsService.Plan("", manifests.Manifest{})
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestManifestPlan(t *testing.T) {
+	addManifestMocks()
+
+	result, err := ManifestsService.Plan("", testManifest())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.Create)
+	assert.EqualValues(t, 1, result.Update)
+	assert.EqualValues(t, manifests.ActionCreate, result.Repositories[0].Action)
+	assert.EqualValues(t, []manifests.Change{
+		{Field: "description", From: "old", To: "new"},
+		{Field: "collaborators.octocat", From: "pull", To: "push"},
+	}, result.Repositories[1].Changes)
+}
+
+func TestManifestPlanErrorGettingState(t *testing.T) {
+	addManifestMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/existing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Server Error"}`,
+		Response:   &http.Response{StatusCode: http.StatusBadGateway},
+	})
+
+	result, err := ManifestsService.Plan("", testManifest())
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.Create)
+	assert.EqualValues(t, 1, result.Failed)
+	assert.EqualValues(t, http.StatusBadGateway, result.Repositories[1].Error.Status())
+}
+
+func TestManifestApply(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+
+	addManifestMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 3,"name": "new-repo","owner": {"login": "EBKopec"}}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/new-repo/topics",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"names": ["go"]}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/existing",
+		HttpMethod: http.MethodPatch,
+		BodyText:   `{"id": 1,"name": "existing","description": "new"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/existing/collaborators/octocat",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"message": "Forbidden"}`,
+		Response:   &http.Response{StatusCode: http.StatusForbidden},
+	})
+
+	result, err := ManifestsService.Apply("client", testManifest())
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, result.StatusCode)
+	assert.True(t, result.Repositories[0].Applied)
+	assert.Nil(t, result.Repositories[0].Error)
+	assert.False(t, result.Repositories[1].Applied)
+	assert.EqualValues(t, http.StatusForbidden, result.Repositories[1].Error.Status())
+
+	records, _ := audit.Store.Find(audit.RecordFilter{ClientId: "client"})
+	assert.EqualValues(t, 2, len(records))
+	assert.EqualValues(t, "new-repo", records[0].Name)
+	assert.EqualValues(t, audit.ActionCreate, records[0].Action)
+	assert.EqualValues(t, audit.StatusSucceeded, records[0].Status)
+
+	description := "new"
+	assert.EqualValues(t, "existing", records[1].Name)
+	assert.EqualValues(t, audit.ActionUpdate, records[1].Action)
+	assert.EqualValues(t, audit.StatusFailed, records[1].Status)
+	assert.EqualValues(t, &audit.RepositoryChanges{Description: &description, Collaborators: map[string]string{"octocat": "push"}}, records[1].Changes)
+}
+
+func TestManifestApplyVisibilityDeniedByPolicy(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+
+	addManifestMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/existing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1,"name": "existing","private": true,"topics": ["go"]}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	rule := policies.Rule{Name: "private", Visibility: policies.VisibilityPrivate, Outcome: policies.OutcomeDeny}
+	withPolicies([]policies.Rule{rule}, func() {
+		public := false
+		manifest := manifests.Manifest{Repositories: []manifests.RepositorySpec{{Name: "existing", Private: &public, Topics: []string{"go", "api"}}}}
+
+		result, err := ManifestsService.Apply("client", manifest)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusForbidden, result.StatusCode)
+		assert.False(t, result.Repositories[0].Applied)
+		assert.EqualValues(t, "repository request denied by policy private", result.Repositories[0].Error.Message())
+
+		records, _ := audit.Store.Find(audit.RecordFilter{ClientId: "client", Action: audit.ActionUpdate})
+		assert.EqualValues(t, 1, len(records))
+		assert.EqualValues(t, audit.StatusFailed, records[0].Status)
+		assert.EqualValues(t, &public, records[0].Changes.Private)
+		assert.EqualValues(t, &[]string{"api", "go"}, records[0].Changes.Topics)
+	})
+}
+
+func TestManifestApplyNothingToDo(t *testing.T) {
+	addManifestMocks()
+	description := "old"
+	manifest := manifests.Manifest{Repositories: []manifests.RepositorySpec{{Name: "existing", Description: &description}}}
+
+	result, err := ManifestsService.Apply("", manifest)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, result.StatusCode)
+	assert.EqualValues(t, manifests.ActionNoOp, result.Repositories[0].Action)
+	assert.False(t, result.Repositories[0].Applied)
+}
+
+func TestManifestApplyCreationGoesThroughPolicies(t *testing.T) {
+	addManifestMocks()
+	rule := policies.Rule{Name: "new", Match: "^new-", Outcome: policies.OutcomeRequireApproval}
+	withPolicies([]policies.Rule{rule}, func() {
+		manifest := manifests.Manifest{Repositories: []manifests.RepositorySpec{{Name: "new-repo", Topics: []string{"go"}}}}
+
+		result, err := ManifestsService.Apply("client", manifest)
+		assert.Nil(t, err)
+		assert.EqualValues(t, http.StatusOK, result.StatusCode)
+		assert.False(t, result.Repositories[0].Applied)
+		assert.Nil(t, result.Repositories[0].Error)
+		assert.NotEqual(t, "", result.Repositories[0].ApprovalId)
+
+		approval, getErr := ApprovalsService.Get("client", result.Repositories[0].ApprovalId)
+		assert.Nil(t, getErr)
+		assert.EqualValues(t, "new-repo", approval.Request.Name)
+		assert.EqualValues(t, []string{"go"}, approval.Request.Topics)
+	})
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
+	"github.com/evertonkopec/golang-microservices-main/src/api/providers/github_provider"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+)
+
+type metadataService struct{}
+
+type metadataServiceInterface interface {
+	GetTopics(clientId string, owner string, name string) (*repositories.Topics, errors.ApiError)
+	ReplaceTopics(clientId string, owner string, name string, request repositories.Topics) (*repositories.Topics, errors.ApiError)
+	CreateLabels(clientId string, owner string, name string, request repositories.CreateLabelsRequest) (*repositories.CreateLabelsResponse, errors.ApiError)
+}
+
+var (
+	MetadataService metadataServiceInterface
+)
+
+func init() {
+	MetadataService = &metadataService{}
+}
+
+func (s *metadataService) GetTopics(clientId string, owner string, name string) (*repositories.Topics, errors.ApiError) {
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	topics, err := github_provider.GetTopics(token, owner, name)
+	if err != nil {
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	return &repositories.Topics{Names: topics}, nil
+}
+
+// ReplaceTopics sets the topics of the repository to exactly the given ones.
+func (s *metadataService) ReplaceTopics(clientId string, owner string, name string, request repositories.Topics) (*repositories.Topics, errors.ApiError) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	topics := manifests.NormalizeTopics(request.Names)
+	changes := audit.RepositoryChanges{Topics: &topics}
+	if err := github_provider.ReplaceTopics(token, owner, name, topics); err != nil {
+		apiErr = errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	recordUpdate(clientId, owner, name, changes, apiErr)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	DriftService.UpdateSpec(owner, name, func(spec *manifests.RepositorySpec) {
+		applyChanges(spec, changes)
+	})
+	return &repositories.Topics{Names: topics}, nil
+}
+
+// recordUpdate audits the settings an update gave the repository, successful
+// or not. Successful updates are replayed when managed repositories are
+// restored.
+func recordUpdate(clientId string, owner string, name string, changes audit.RepositoryChanges, err errors.ApiError) {
+	record := audit.NewRepositoryRecord(clientId, audit.ActionUpdate, owner, name, http.StatusOK, err)
+	record.Changes = &changes
+	saveRecord(record)
+}
+
+// CreateLabels creates every label, updating the ones that already exist.
+// Labels are independent, so one failing does not stop the others.
+func (s *metadataService) CreateLabels(clientId string, owner string, name string, request repositories.CreateLabelsRequest) (*repositories.CreateLabelsResponse, errors.ApiError) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	result := repositories.CreateLabelsResponse{Results: make([]repositories.LabelResult, 0, len(request.Labels))}
+	succeeded := 0
+	for _, label := range request.Labels {
+		current := repositories.LabelResult{Name: label.Name}
+		current.Action, current.Error = ensureLabel(token, owner, name, label)
+		if current.Error == nil {
+			succeeded++
+		}
+		result.Results = append(result.Results, current)
+	}
+
+	switch succeeded {
+	case len(result.Results):
+		result.StatusCode = http.StatusCreated
+	case 0:
+		result.StatusCode = result.Results[0].Error.Status()
+	default:
+		result.StatusCode = http.StatusPartialContent
+	}
+	return &result, nil
+}
+
+// ensureLabel creates the label or, when GitHub reports it already exists,
+// brings its color and description in line.
+func ensureLabel(token string, owner string, name string, label repositories.Label) (string, errors.ApiError) {
+	request := github.Label{Name: label.Name, Color: label.Color, Description: label.Description}
+	_, err := github_provider.CreateLabel(token, owner, name, request)
+	if err == nil {
+		return repositories.LabelActionCreated, nil
+	}
+	if err.StatusCode != http.StatusUnprocessableEntity {
+		option_b.Error("error when trying to create label", err,
+			option_b.Field("repository", fmt.Sprintf("%s/%s", owner, name)),
+			option_b.Field("label", label.Name))
+		return "", errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+
+	if _, err = github_provider.UpdateLabel(token, owner, name, request); err != nil {
+		option_b.Error("error when trying to update label", err,
+			option_b.Field("repository", fmt.Sprintf("%s/%s", owner, name)),
+			option_b.Field("label", label.Name))
+		return "", errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	return repositories.LabelActionUpdated, nil
+}
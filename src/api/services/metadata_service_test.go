@@ -0,0 +1,135 @@
+package services
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/drift"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestReplaceTopicsUpdatesManagedSpec(t *testing.T) {
+	resetManagedRepositories()
+	defer resetManagedRepositories()
+	DriftService.Manage("client", "EBKopec", createdRepoSpec(repositories.CreateRepoRequest{Name: "api"}))
+
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/topics",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"names": ["go", "api"]}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+
+	result, err := MetadataService.ReplaceTopics("client", "EBKopec", "api", repositories.Topics{Names: []string{"Go", "api", "go"}})
+	assert.Nil(t, err)
+	assert.EqualValues(t, []string{"api", "go"}, result.Names)
+	assert.EqualValues(t, []string{"api", "go"}, drift.DriftDao.GetManaged()[0].Spec.Topics)
+}
+
+func TestReplaceTopicsIsAudited(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/topics",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"names": ["go"]}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	MetadataService.ReplaceTopics("client", "EBKopec", "api", repositories.Topics{Names: []string{"go"}})
+
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/topics",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"message": "Not Found"}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+	_, err := MetadataService.ReplaceTopics("client", "EBKopec", "api", repositories.Topics{Names: []string{"api"}})
+	assert.NotNil(t, err)
+
+	records, _ := audit.Store.Find(audit.RecordFilter{Action: audit.ActionUpdate})
+	assert.EqualValues(t, 2, len(records))
+	assert.EqualValues(t, audit.StatusSucceeded, records[0].Status)
+	assert.EqualValues(t, []string{"go"}, *records[0].Changes.Topics)
+	assert.EqualValues(t, audit.StatusFailed, records[1].Status)
+	assert.EqualValues(t, http.StatusNotFound, records[1].StatusCode)
+}
+
+func TestReplaceTopicsInvalid(t *testing.T) {
+	result, err := MetadataService.ReplaceTopics("client", "EBKopec", "api", repositories.Topics{Names: []string{"not valid"}})
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+}
+
+func TestGetTopicsErrorFromGithub(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/topics",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+
+	result, err := MetadataService.GetTopics("client", "EBKopec", "api")
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+}
+
+func TestCreateLabelsUpdatesExistingLabels(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/labels",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"message": "Validation Failed"}`,
+		Response:   &http.Response{StatusCode: http.StatusUnprocessableEntity},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/labels/bug",
+		HttpMethod: http.MethodPatch,
+		BodyText:   `{"id": 1, "name": "bug", "color": "d73a4a"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/labels/wontfix",
+		HttpMethod: http.MethodPatch,
+		BodyText:   `{"message": "Not Found"}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+
+	request := repositories.CreateLabelsRequest{Labels: []repositories.Label{
+		{Name: "bug", Color: "d73a4a"},
+		{Name: "wontfix", Color: "ffffff"},
+	}}
+	result, err := MetadataService.CreateLabels("client", "EBKopec", "api", request)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, result.StatusCode)
+	assert.EqualValues(t, 2, len(result.Results))
+	assert.EqualValues(t, repositories.LabelResult{Name: "bug", Action: repositories.LabelActionUpdated}, result.Results[0])
+	assert.EqualValues(t, http.StatusNotFound, result.Results[1].Error.Status())
+}
+
+func TestCreateLabelsStandardSet(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/labels",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 1}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+
+	result, err := MetadataService.CreateLabels("client", "EBKopec", "api", repositories.CreateLabelsRequest{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusCreated, result.StatusCode)
+	assert.EqualValues(t, len(repositories.StandardLabels), len(result.Results))
+	for _, current := range result.Results {
+		assert.EqualValues(t, repositories.LabelActionCreated, current.Action)
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/providers/github_provider"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"io"
+	"net/http"
+)
+
+const (
+	tagRefPrefix = "refs/tags/"
+)
+
+type releasesService struct{}
+
+type releasesServiceInterface interface {
+	CreateRelease(clientId string, owner string, name string, request repositories.CreateReleaseRequest) (*repositories.Release, errors.ApiError)
+	GetReleases(clientId string, owner string, name string) ([]repositories.Release, errors.ApiError)
+	GetRelease(clientId string, owner string, name string, releaseId int64) (*repositories.Release, errors.ApiError)
+	GetReleaseByTag(clientId string, owner string, name string, tag string) (*repositories.Release, errors.ApiError)
+	UploadAsset(clientId string, owner string, name string, releaseId int64, request repositories.UploadAssetRequest, content io.Reader) (*repositories.ReleaseAsset, errors.ApiError)
+	GetTags(clientId string, owner string, name string) ([]repositories.Tag, errors.ApiError)
+	CreateTag(clientId string, owner string, name string, request repositories.CreateTagRequest) (*repositories.Tag, errors.ApiError)
+}
+
+var (
+	ReleasesService releasesServiceInterface
+)
+
+func init() {
+	ReleasesService = &releasesService{}
+}
+
+func (s *releasesService) CreateRelease(clientId string, owner string, name string, request repositories.CreateReleaseRequest) (*repositories.Release, errors.ApiError) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	release, err := github_provider.CreateRelease(token, owner, name, github.CreateReleaseRequest{
+		TagName:              request.TagName,
+		TargetCommitish:      request.Target,
+		Name:                 request.Name,
+		Body:                 request.Body,
+		Draft:                request.Draft,
+		Prerelease:           request.Prerelease,
+		GenerateReleaseNotes: request.GenerateReleaseNotes,
+	})
+	if err != nil {
+		if err.StatusCode == http.StatusUnprocessableEntity && hasErrorCode(err, "already_exists") {
+			return nil, errors.NewConflictError(fmt.Sprintf("a release of tag %s already exists", request.TagName))
+		}
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	result := toRelease(*release)
+	return &result, nil
+}
+
+func (s *releasesService) GetReleases(clientId string, owner string, name string) ([]repositories.Release, errors.ApiError) {
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	releases, err := github_provider.GetReleases(token, owner, name)
+	if err != nil {
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	result := make([]repositories.Release, 0, len(releases))
+	for _, release := range releases {
+		result = append(result, toRelease(release))
+	}
+	return result, nil
+}
+
+func (s *releasesService) GetRelease(clientId string, owner string, name string, releaseId int64) (*repositories.Release, errors.ApiError) {
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	release, err := github_provider.GetRelease(token, owner, name, releaseId)
+	if err != nil {
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	result := toRelease(*release)
+	return &result, nil
+}
+
+func (s *releasesService) GetReleaseByTag(clientId string, owner string, name string, tag string) (*repositories.Release, errors.ApiError) {
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	release, err := github_provider.GetReleaseByTag(token, owner, name, tag)
+	if err != nil {
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	result := toRelease(*release)
+	return &result, nil
+}
+
+// UploadAsset streams content to GitHub without buffering it, so the size is
+// checked against the configured limit before anything is sent.
+func (s *releasesService) UploadAsset(clientId string, owner string, name string, releaseId int64, request repositories.UploadAssetRequest, content io.Reader) (*repositories.ReleaseAsset, errors.ApiError) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	if maxSize := config.GetReleaseAssetMaxSize(); request.Size > maxSize {
+		return nil, errors.NewApiError(http.StatusRequestEntityTooLarge, fmt.Sprintf("asset must be at most %d bytes long", maxSize))
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	asset, err := github_provider.UploadReleaseAsset(token, owner, name, releaseId, request.Name, request.Label, request.ContentType, content, request.Size)
+	if err != nil {
+		if err.StatusCode == http.StatusUnprocessableEntity && hasErrorCode(err, "already_exists") {
+			return nil, errors.NewConflictError(fmt.Sprintf("the release already has an asset named %s", request.Name))
+		}
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	result := toReleaseAsset(*asset)
+	return &result, nil
+}
+
+func (s *releasesService) GetTags(clientId string, owner string, name string) ([]repositories.Tag, errors.ApiError) {
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	tags, err := github_provider.GetTags(token, owner, name)
+	if err != nil {
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	result := make([]repositories.Tag, 0, len(tags))
+	for _, tag := range tags {
+		result = append(result, repositories.Tag{Name: tag.Name, Sha: tag.Commit.Sha})
+	}
+	return result, nil
+}
+
+// CreateTag points refs/tags/<name> at the commit, through an annotated tag
+// object when the request has a message.
+func (s *releasesService) CreateTag(clientId string, owner string, name string, request repositories.CreateTagRequest) (*repositories.Tag, errors.ApiError) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	target := request.Sha
+	if request.Message != "" {
+		object, err := github_provider.CreateTagObject(token, owner, name, github.CreateTagObjectRequest{
+			Tag:     request.Name,
+			Message: request.Message,
+			Object:  request.Sha,
+			Type:    github.GitObjectCommit,
+		})
+		if err != nil {
+			return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+		}
+		target = object.Sha
+	}
+
+	if _, err := github_provider.CreateRef(token, owner, name, github.CreateRefRequest{Ref: tagRefPrefix + request.Name, Sha: target}); err != nil {
+		if err.StatusCode == http.StatusUnprocessableEntity && err.Message == "Reference already exists" {
+			return nil, errors.NewConflictError(fmt.Sprintf("tag %s already exists", request.Name))
+		}
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	return &repositories.Tag{Name: request.Name, Sha: request.Sha}, nil
+}
+
+func hasErrorCode(err *github.GithubErrorResponse, code string) bool {
+	for _, current := range err.Errors {
+		if current.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func toRelease(release github.Release) repositories.Release {
+	result := repositories.Release{
+		Id:          release.Id,
+		TagName:     release.TagName,
+		Target:      release.TargetCommitish,
+		Name:        release.Name,
+		Body:        release.Body,
+		Draft:       release.Draft,
+		Prerelease:  release.Prerelease,
+		Url:         release.HtmlUrl,
+		CreatedAt:   release.CreatedAt,
+		PublishedAt: release.PublishedAt,
+		Assets:      make([]repositories.ReleaseAsset, 0, len(release.Assets)),
+	}
+	for _, asset := range release.Assets {
+		result.Assets = append(result.Assets, toReleaseAsset(asset))
+	}
+	return result
+}
+
+func toReleaseAsset(asset github.ReleaseAsset) repositories.ReleaseAsset {
+	return repositories.ReleaseAsset{
+		Id:          asset.Id,
+		Name:        asset.Name,
+		Label:       asset.Label,
+		ContentType: asset.ContentType,
+		Size:        asset.Size,
+		DownloadUrl: asset.BrowserDownloadUrl,
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	testCommitSha = "abcdef0123456789abcdef0123456789abcdef01"
+)
+
+func TestCreateReleaseAlreadyExists(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/releases",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"message": "Validation Failed", "errors": [{"resource": "Release", "code": "already_exists", "field": "tag_name"}]}`,
+		Response:   &http.Response{StatusCode: http.StatusUnprocessableEntity},
+	})
+
+	result, err := ReleasesService.CreateRelease("client", "EBKopec", "api", repositories.CreateReleaseRequest{TagName: "v1.0.0"})
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusConflict, err.Status())
+	assert.EqualValues(t, "a release of tag v1.0.0 already exists", err.Message())
+}
+
+func TestGetReleases(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/releases?per_page=100",
+		HttpMethod: http.MethodGet,
+		BodyText:   `[{"id": 1, "tag_name": "v1.0.0", "target_commitish": "main", "html_url": "https://github.com/EBKopec/api/releases/v1.0.0", "assets": [{"id": 9, "name": "app.tar.gz", "size": 4, "browser_download_url": "https://github.com/EBKopec/api/releases/download/v1.0.0/app.tar.gz"}]}]`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+
+	result, err := ReleasesService.GetReleases("client", "EBKopec", "api")
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(result))
+	assert.EqualValues(t, "main", result[0].Target)
+	assert.EqualValues(t, "https://github.com/EBKopec/api/releases/v1.0.0", result[0].Url)
+	assert.EqualValues(t, "https://github.com/EBKopec/api/releases/download/v1.0.0/app.tar.gz", result[0].Assets[0].DownloadUrl)
+}
+
+func TestUploadAssetTooLarge(t *testing.T) {
+	os.Setenv("RELEASE_ASSET_MAX_SIZE", "3")
+	defer os.Unsetenv("RELEASE_ASSET_MAX_SIZE")
+
+	request := repositories.UploadAssetRequest{Name: "app.tar.gz", Size: 4}
+	result, err := ReleasesService.UploadAsset("client", "EBKopec", "api", 1, request, strings.NewReader("data"))
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusRequestEntityTooLarge, err.Status())
+}
+
+func TestCreateAnnotatedTag(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/git/tags",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"sha": "tag-object", "tag": "v1.0.0", "object": {"sha": "` + testCommitSha + `", "type": "commit"}}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/git/refs",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"ref": "refs/tags/v1.0.0", "object": {"sha": "tag-object", "type": "tag"}}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+
+	result, err := ReleasesService.CreateTag("client", "EBKopec", "api", repositories.CreateTagRequest{Name: "v1.0.0", Sha: testCommitSha, Message: "First release"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, repositories.Tag{Name: "v1.0.0", Sha: testCommitSha}, *result)
+}
+
+func TestCreateTagAlreadyExists(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/git/refs",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"message": "Reference already exists"}`,
+		Response:   &http.Response{StatusCode: http.StatusUnprocessableEntity},
+	})
+
+	result, err := ReleasesService.CreateTag("client", "EBKopec", "api", repositories.CreateTagRequest{Name: "v1.0.0", Sha: testCommitSha})
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusConflict, err.Status())
+}
@@ -1,14 +1,25 @@
 package services
 
 import (
+	"context"
+	stderrors "errors"
 	"github.com/evertonkopec/golang-microservices-main/src/api/clients/restclient"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/policies"
 	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/scaffold"
 	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestMain(m *testing.M) {
@@ -19,7 +30,7 @@ func TestMain(m *testing.M) {
 func TestCreateRepoInvalidInputName(t *testing.T) {
 	request := repositories.CreateRepoRequest{}
 
-	result, err := RepositoryService.CreateRepo(request)
+	result, err := RepositoryService.CreateRepo("", request)
 	assert.Nil(t, result)
 	assert.NotNil(t, err)
 	assert.EqualValues(t, http.StatusBadRequest, err.Status())
@@ -31,18 +42,23 @@ func TestCreateRepoErrorFromGithub(t *testing.T) {
 	restclient.AddMockups(restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
 		HttpMethod: http.MethodPost,
-		BodyText: `{ "message": "Requires authentication", "documentation_url": "https://docs.github.com/rest/reference/repos#create-a-repository-for-the-authenticated-user"}`,
+		BodyText:   `{ "message": "Requires authentication", "documentation_url": "https://docs.github.com/rest/reference/repos#create-a-repository-for-the-authenticated-user"}`,
 		Response: &http.Response{
 			StatusCode: http.StatusUnauthorized,
 		},
 	})
 	request := repositories.CreateRepoRequest{Name: "testings"}
 
-	result, err := RepositoryService.CreateRepo(request)
+	result, err := RepositoryService.CreateRepo("", request)
 	assert.Nil(t, result)
 	assert.NotNil(t, err)
 	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
 	assert.EqualValues(t, "Requires authentication", err.Message())
+	assert.True(t, stderrors.Is(err, errors.ErrUnauthorized))
+
+	var githubErr *github.GithubErrorResponse
+	assert.True(t, stderrors.As(err, &githubErr))
+	assert.EqualValues(t, http.StatusUnauthorized, githubErr.StatusCode)
 }
 
 func TestCreateRepoNoError(t *testing.T) {
@@ -57,7 +73,7 @@ func TestCreateRepoNoError(t *testing.T) {
 	})
 	request := repositories.CreateRepoRequest{Name: "testings"}
 
-	result, err := RepositoryService.CreateRepo(request)
+	result, err := RepositoryService.CreateRepo("", request)
 	assert.Nil(t, err)
 	assert.NotNil(t, result)
 	assert.EqualValues(t, 123, result.Id)
@@ -71,7 +87,7 @@ func TestCreateRepoConcurrentInvalidRequest(t *testing.T) {
 	output := make(chan repositories.CreateRepositoriesResult)
 
 	service := reposService{}
-	go service.createRepoConcurrent(request, output)
+	go service.createRepoConcurrent("", "", 0, request, output)
 
 	result := <-output
 	assert.NotNil(t, result)
@@ -86,7 +102,7 @@ func TestCreateRepoConcurrentErrorFromGithub(t *testing.T) {
 	restclient.AddMockups(restclient.Mock{
 		Url:        "https://api.github.com/user/repos",
 		HttpMethod: http.MethodPost,
-		BodyText: `{ "message": "Requires authentication", "documentation_url": "https://docs.github.com/rest/reference/repos#create-a-repository-for-the-authenticated-user"}`,
+		BodyText:   `{ "message": "Requires authentication", "documentation_url": "https://docs.github.com/rest/reference/repos#create-a-repository-for-the-authenticated-user"}`,
 		Response: &http.Response{
 			StatusCode: http.StatusUnauthorized,
 		},
@@ -96,7 +112,7 @@ func TestCreateRepoConcurrentErrorFromGithub(t *testing.T) {
 	output := make(chan repositories.CreateRepositoriesResult)
 
 	service := reposService{}
-	go service.createRepoConcurrent(request, output)
+	go service.createRepoConcurrent("", "", 0, request, output)
 
 	result := <-output
 	assert.NotNil(t, result)
@@ -121,7 +137,7 @@ func TestCreateRepoConcurrentNoError(t *testing.T) {
 	output := make(chan repositories.CreateRepositoriesResult)
 
 	service := reposService{}
-	go service.createRepoConcurrent(request, output)
+	go service.createRepoConcurrent("", "", 0, request, output)
 
 	result := <-output
 	assert.NotNil(t, result)
@@ -141,9 +157,14 @@ func TestHandleRepoResults(t *testing.T) {
 	service := reposService{}
 	go service.handleRepoResults(&wg, input, output)
 
-	wg.Add(1)
+	wg.Add(2)
 	go func() {
 		input <- repositories.CreateRepositoriesResult{
+			Index:    1,
+			Response: &repositories.CreateRepoResponse{Id: 123},
+		}
+		input <- repositories.CreateRepositoriesResult{
+			Index: 0,
 			Error: errors.NewBadRequestError("invalid repository name"),
 		}
 	}()
@@ -155,10 +176,13 @@ func TestHandleRepoResults(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.EqualValues(t, 0, result.StatusCode)
 
-	assert.EqualValues(t, 1, len(result.Results))
+	assert.EqualValues(t, 2, len(result.Results))
+	assert.EqualValues(t, 0, result.Results[0].Index)
 	assert.NotNil(t, result.Results[0].Error)
 	assert.EqualValues(t, http.StatusBadRequest, result.Results[0].Error.Status())
 	assert.EqualValues(t, "invalid repository name", result.Results[0].Error.Message())
+	assert.EqualValues(t, 1, result.Results[1].Index)
+	assert.EqualValues(t, 123, result.Results[1].Response.Id)
 }
 
 func TestCreatReposInvalidRequests(t *testing.T) {
@@ -167,7 +191,7 @@ func TestCreatReposInvalidRequests(t *testing.T) {
 		{Name: "   "},
 	}
 
-	result, err := RepositoryService.CreateRepos(request)
+	result, err := RepositoryService.CreateRepos(context.Background(), "", request, repositories.CreateReposOptions{})
 	assert.Nil(t, err)
 	assert.NotNil(t, result)
 	assert.EqualValues(t, http.StatusBadRequest, result.StatusCode)
@@ -182,6 +206,21 @@ func TestCreatReposInvalidRequests(t *testing.T) {
 	assert.EqualValues(t, "invalid repository name", result.Results[1].Error.Message())
 }
 
+func TestCreateReposValidatesLikeCreateRepo(t *testing.T) {
+	request := repositories.CreateRepoRequest{Name: "..", Homepage: "not a url"}
+
+	_, single := RepositoryService.CreateRepo("", request)
+	batch, err := RepositoryService.CreateRepos(context.Background(), "", []repositories.CreateRepoRequest{request}, repositories.CreateReposOptions{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, len(batch.Results))
+	assert.NotNil(t, single)
+	assert.NotNil(t, batch.Results[0].Error)
+	assert.EqualValues(t, single.Status(), batch.Results[0].Error.Status())
+	assert.EqualValues(t, single.Message(), batch.Results[0].Error.Message())
+	assert.EqualValues(t, single.Causes(), batch.Results[0].Error.Causes())
+	assert.EqualValues(t, 2, len(single.Causes()))
+}
+
 func TestCreatReposOneSuccessOneFail(t *testing.T) {
 	restclient.FlushMocks()
 	restclient.AddMockups(restclient.Mock{
@@ -197,23 +236,22 @@ func TestCreatReposOneSuccessOneFail(t *testing.T) {
 		{Name: "testing"},
 	}
 
-	result, err := RepositoryService.CreateRepos(request)
+	result, err := RepositoryService.CreateRepos(context.Background(), "", request, repositories.CreateReposOptions{})
 	assert.Nil(t, err)
 	assert.NotNil(t, result)
 	assert.EqualValues(t, http.StatusPartialContent, result.StatusCode)
 	assert.EqualValues(t, 2, len(result.Results))
 
-	for _, result := range result.Results {
-		if result.Error != nil {
-			assert.EqualValues(t, http.StatusBadRequest, result.Error.Status())
-			assert.EqualValues(t, "invalid repository name", result.Error.Message())
-			continue
-		}
+	assert.EqualValues(t, 0, result.Results[0].Index)
+	assert.Nil(t, result.Results[0].Response)
+	assert.EqualValues(t, http.StatusBadRequest, result.Results[0].Error.Status())
+	assert.EqualValues(t, "invalid repository name", result.Results[0].Error.Message())
 
-		assert.EqualValues(t, 123, result.Response.Id)
-		assert.EqualValues(t, "testing", result.Response.Name)
-		assert.EqualValues(t, "EBKopec", result.Response.Owner)
-	}
+	assert.EqualValues(t, 1, result.Results[1].Index)
+	assert.Nil(t, result.Results[1].Error)
+	assert.EqualValues(t, 123, result.Results[1].Response.Id)
+	assert.EqualValues(t, "testing", result.Results[1].Response.Name)
+	assert.EqualValues(t, "EBKopec", result.Results[1].Response.Owner)
 }
 
 func TestCreatReposAllSuccess(t *testing.T) {
@@ -228,10 +266,10 @@ func TestCreatReposAllSuccess(t *testing.T) {
 	})
 	requests := []repositories.CreateRepoRequest{
 		{Name: "testing"},
-		{Name: "testing"},
+		{Name: "testing-2"},
 	}
 
-	result, err := RepositoryService.CreateRepos(requests)
+	result, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, result)
@@ -247,3 +285,755 @@ func TestCreatReposAllSuccess(t *testing.T) {
 	assert.EqualValues(t, "EBKopec", result.Results[1].Response.Owner)
 
 }
+
+func TestCreateReposEmptyBatch(t *testing.T) {
+	result, err := RepositoryService.CreateRepos(context.Background(), "", nil, repositories.CreateReposOptions{})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "no repositories to create", err.Message())
+	assert.EqualValues(t, 0, len(result.Results))
+}
+
+func TestCreateReposBatchTooLarge(t *testing.T) {
+	os.Setenv("REPO_BATCH_MAX_SIZE", "2")
+	defer os.Unsetenv("REPO_BATCH_MAX_SIZE")
+
+	requests := []repositories.CreateRepoRequest{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	_, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "at most 2 repositories can be created in a single batch", err.Message())
+}
+
+func TestCreateReposKeepsInputOrder(t *testing.T) {
+	os.Setenv("REPO_BATCH_CONCURRENCY", "3")
+	defer os.Unsetenv("REPO_BATCH_CONCURRENCY")
+
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "id": 123, "name":"testing", "owner":{"login":"EBKopec" }}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+	requests := make([]repositories.CreateRepoRequest, 0)
+	for i := 0; i < 20; i++ {
+		name := "testing-" + strconv.Itoa(i)
+		if i%2 == 0 {
+			name = ""
+		}
+		requests = append(requests, repositories.CreateRepoRequest{Name: name})
+	}
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "client", requests, repositories.CreateReposOptions{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, result.StatusCode)
+	assert.EqualValues(t, 20, len(result.Results))
+	for i, current := range result.Results {
+		assert.EqualValues(t, i, current.Index)
+		assert.EqualValues(t, i%2 == 0, current.Error != nil)
+	}
+}
+
+func TestCreateReposCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	requests := []repositories.CreateRepoRequest{{Name: "a"}, {Name: "b"}}
+	result, err := RepositoryService.CreateRepos(ctx, "", requests, repositories.CreateReposOptions{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusRequestTimeout, result.StatusCode)
+	assert.EqualValues(t, 2, len(result.Results))
+	for i, current := range result.Results {
+		assert.EqualValues(t, i, current.Index)
+		assert.Nil(t, current.Response)
+		assert.NotNil(t, current.Error)
+		assert.True(t, stderrors.Is(current.Error, context.Canceled))
+		assert.EqualValues(t, "repository creation cancelled", current.Error.Message())
+	}
+}
+
+func TestStreamReposInvalidBatch(t *testing.T) {
+	events, err := RepositoryService.StreamRepos(context.Background(), "", nil, repositories.CreateReposOptions{})
+	assert.Nil(t, events)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "no repositories to create", err.Message())
+}
+
+func TestStreamRepos(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "id": 123, "name":"testing", "owner":{"login":"EBKopec" }}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {}, {Name: "testing-2"}}
+
+	events, err := RepositoryService.StreamRepos(context.Background(), "client", requests, repositories.CreateReposOptions{})
+	assert.Nil(t, err)
+
+	received := make([]repositories.CreateReposEvent, 0)
+	for event := range events {
+		received = append(received, event)
+	}
+	assert.EqualValues(t, 4, len(received))
+
+	indexes := make(map[int]bool)
+	for _, event := range received[:3] {
+		assert.NotNil(t, event.Result)
+		assert.Nil(t, event.Summary)
+		indexes[event.Result.Index] = true
+		assert.EqualValues(t, event.Result.Index == 1, event.Result.Error != nil)
+	}
+	assert.EqualValues(t, map[int]bool{0: true, 1: true, 2: true}, indexes)
+
+	summary := received[3].Summary
+	assert.NotNil(t, summary)
+	assert.EqualValues(t, repositories.CreateReposSummary{StatusCode: http.StatusPartialContent, Total: 3, Succeeded: 2, Failed: 1}, *summary)
+}
+
+func TestCreateReposAtomicInvalidRequestCreatesNothing(t *testing.T) {
+	restclient.FlushMocks()
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: ".."}}
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{Atomic: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, result.StatusCode)
+	assert.EqualValues(t, 1, len(result.Results))
+	assert.NotNil(t, result.Failure)
+	assert.EqualValues(t, 1, result.Failure.Index)
+	assert.EqualValues(t, "invalid repository name", result.Failure.Error.Message())
+	assert.EqualValues(t, 0, len(result.Compensations))
+}
+
+func TestCreateReposAtomicAllSuccess(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "id": 123, "name":"testing", "owner":{"login":"EBKopec" }}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: "testing-2"}}
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{Atomic: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusCreated, result.StatusCode)
+	assert.EqualValues(t, 2, len(result.Results))
+	assert.Nil(t, result.Failure)
+	assert.EqualValues(t, 0, len(result.Compensations))
+}
+
+func TestCreateReposAtomicFailureFromGithub(t *testing.T) {
+	os.Setenv("REPO_BATCH_CONCURRENCY", "1")
+	defer os.Unsetenv("REPO_BATCH_CONCURRENCY")
+
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "message": "Repository creation failed."}`,
+		Response: &http.Response{
+			StatusCode: http.StatusUnprocessableEntity,
+		},
+	})
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: "testing-2"}, {Name: "testing-3"}}
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{Atomic: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, result.StatusCode)
+	assert.EqualValues(t, 3, len(result.Results))
+	assert.NotNil(t, result.Failure)
+	assert.EqualValues(t, 0, result.Failure.Index)
+	assert.EqualValues(t, "Repository creation failed.", result.Failure.Error.Message())
+	assert.True(t, stderrors.Is(result.Results[2].Error, context.Canceled))
+	assert.EqualValues(t, 0, len(result.Compensations))
+}
+
+func TestCompensate(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusNoContent,
+		},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/protected",
+		HttpMethod: http.MethodDelete,
+		BodyText:   `{"message": "Must have admin rights to Repository."}`,
+		Response: &http.Response{
+			StatusCode: http.StatusForbidden,
+		},
+	})
+	service := reposService{}
+
+	result := service.compensate("", "", repositories.CreateRepositoriesResult{
+		Index:    1,
+		Response: &repositories.CreateRepoResponse{Id: 1, Owner: "EBKopec", Name: "testing"},
+	})
+	assert.EqualValues(t, repositories.CompensationResult{Index: 1, Owner: "EBKopec", Name: "testing", Deleted: true}, result)
+
+	result = service.compensate("", "", repositories.CreateRepositoriesResult{
+		Index:    2,
+		Response: &repositories.CreateRepoResponse{Id: 2, Owner: "EBKopec", Name: "protected"},
+	})
+	assert.False(t, result.Deleted)
+	assert.NotNil(t, result.Error)
+	assert.EqualValues(t, http.StatusForbidden, result.Error.Status())
+	assert.EqualValues(t, "Must have admin rights to Repository.", result.Error.Message())
+}
+
+func TestCompensateRefundsQuota(t *testing.T) {
+	withClients([]clients.Client{{Id: "limited", Quotas: clients.Quotas{ReposPerDay: 2}}}, func() {
+		restclient.FlushMocks()
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/repos/EBKopec/testing",
+			HttpMethod: http.MethodDelete,
+			Response:   &http.Response{StatusCode: http.StatusNoContent},
+		})
+		restclient.AddMockups(restclient.Mock{
+			Url:        "https://api.github.com/repos/EBKopec/protected",
+			HttpMethod: http.MethodDelete,
+			BodyText:   `{"message": "Must have admin rights to Repository."}`,
+			Response:   &http.Response{StatusCode: http.StatusForbidden},
+		})
+		day, err := clients.UsageDao.ConsumeRepos("limited", 2, 2)
+		assert.Nil(t, err)
+		service := reposService{}
+
+		service.compensate("limited", day, repositories.CreateRepositoriesResult{
+			Response: &repositories.CreateRepoResponse{Id: 1, Owner: "EBKopec", Name: "testing"},
+		})
+		service.compensate("limited", day, repositories.CreateRepositoriesResult{
+			Response: &repositories.CreateRepoResponse{Id: 2, Owner: "EBKopec", Name: "protected"},
+		})
+
+		// the repository that could not be deleted still counts
+		assert.EqualValues(t, 1, clients.UsageDao.Get("limited").ReposCreated)
+	})
+}
+
+func TestDryRunReposErrorGettingUser(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{ "message": "Requires authentication"}`,
+		Response: &http.Response{
+			StatusCode: http.StatusUnauthorized,
+		},
+	})
+
+	_, err := RepositoryService.DryRunRepos("", []repositories.CreateRepoRequest{{Name: "testing"}})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnauthorized, err.Status())
+	assert.EqualValues(t, "Requires authentication", err.Message())
+}
+
+func TestDryRunRepos(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1, "login": "EBKopec"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/new-repo",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/existing",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 123, "name": "existing"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	requests := []repositories.CreateRepoRequest{
+		{Name: " new-repo "},
+		{Name: "existing"},
+		{Name: "New-Repo"},
+		{Name: ".."},
+	}
+
+	result, err := RepositoryService.DryRunRepos("client", requests)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, result.Create)
+	assert.EqualValues(t, 3, result.Reject)
+	assert.EqualValues(t, 4, len(result.Results))
+
+	assert.EqualValues(t, repositories.DryRunResult{Index: 0, Owner: "EBKopec", Name: "new-repo", Action: "create"}, result.Results[0])
+
+	assert.EqualValues(t, "reject", result.Results[1].Action)
+	assert.EqualValues(t, http.StatusConflict, result.Results[1].Error.Status())
+	assert.EqualValues(t, "repository EBKopec/existing already exists", result.Results[1].Error.Message())
+
+	assert.EqualValues(t, "reject", result.Results[2].Action)
+	assert.EqualValues(t, http.StatusConflict, result.Results[2].Error.Status())
+	assert.EqualValues(t, "repository name already used by item 0 of the batch", result.Results[2].Error.Message())
+
+	assert.EqualValues(t, "reject", result.Results[3].Action)
+	assert.EqualValues(t, http.StatusBadRequest, result.Results[3].Error.Status())
+}
+
+func TestDryRunReposReportsPolicies(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"id": 1, "login": "EBKopec"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/prod-api",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "Not Found"}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+
+	withPolicies([]policies.Rule{prefixRule, productionRule}, func() {
+		result, err := RepositoryService.DryRunRepos("team-a", []repositories.CreateRepoRequest{{Name: "service"}})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 1, result.Reject)
+		assert.EqualValues(t, "reject", result.Results[0].Action)
+		assert.EqualValues(t, http.StatusForbidden, result.Results[0].Error.Status())
+		assert.EqualValues(t, "repository request denied by policy prefix", result.Results[0].Error.Message())
+
+		result, err = RepositoryService.DryRunRepos("client", []repositories.CreateRepoRequest{{Name: "prod-api"}})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 0, result.Create)
+		assert.EqualValues(t, 1, result.RequireApproval)
+		assert.EqualValues(t, repositories.DryRunResult{Index: 0, Owner: "EBKopec", Name: "prod-api", Action: "require_approval", Policy: "production"}, result.Results[0])
+	})
+}
+
+func TestCreateRepoRecordsAttempts(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "id": 123, "name":"testing", "owner":{"login":"EBKopec" }}`,
+		Response: &http.Response{
+			StatusCode: http.StatusCreated,
+		},
+	})
+	RepositoryService.CreateRepo("client", repositories.CreateRepoRequest{Name: "testing"})
+	RepositoryService.CreateRepo("client", repositories.CreateRepoRequest{Name: ".."})
+
+	records, _ := audit.Store.Find(audit.RecordFilter{ClientId: "client"})
+	assert.EqualValues(t, 2, len(records))
+
+	assert.EqualValues(t, audit.ActionCreate, records[0].Action)
+	assert.EqualValues(t, audit.StatusSucceeded, records[0].Status)
+	assert.EqualValues(t, http.StatusCreated, records[0].StatusCode)
+	assert.EqualValues(t, "EBKopec", records[0].Owner)
+	assert.EqualValues(t, "testing", records[0].Request.Name)
+	assert.EqualValues(t, 123, records[0].Response.Id)
+
+	assert.EqualValues(t, audit.StatusFailed, records[1].Status)
+	assert.EqualValues(t, http.StatusBadRequest, records[1].StatusCode)
+	assert.EqualValues(t, "invalid repository name", records[1].Error)
+	assert.Nil(t, records[1].Response)
+}
+
+func TestCompensateRecordsDelete(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing",
+		HttpMethod: http.MethodDelete,
+		Response: &http.Response{
+			StatusCode: http.StatusNoContent,
+		},
+	})
+	service := &reposService{}
+	service.compensate("client", "", repositories.CreateRepositoriesResult{
+		Response: &repositories.CreateRepoResponse{Id: 123, Owner: "EBKopec", Name: "testing"},
+	})
+
+	records, _ := audit.Store.Find(audit.RecordFilter{Action: audit.ActionDelete})
+	assert.EqualValues(t, 1, len(records))
+	assert.EqualValues(t, "EBKopec", records[0].Owner)
+	assert.EqualValues(t, "testing", records[0].Name)
+	assert.EqualValues(t, audit.StatusSucceeded, records[0].Status)
+}
+
+func TestCreateRepoWithPreset(t *testing.T) {
+	originalPresets, originalStore := repositories.PresetRegistry, audit.Store
+	defer func() { repositories.PresetRegistry, audit.Store = originalPresets, originalStore }()
+	audit.Store = audit.NewMemoryStore()
+	private := true
+	repositories.PresetRegistry, _ = repositories.NewPresetRegistry(repositories.Preset{
+		Name: "go-service",
+		RepoSettings: repositories.RepoSettings{
+			Private:           &private,
+			GitignoreTemplate: "Go",
+			Topics:            []string{"Go"},
+			BranchProtection:  []repositories.BranchProtection{{Branch: "main", RequiredApprovingReviews: 1}},
+			Webhooks:          []repositories.Webhook{{Url: "https://ci.example.com/hook", Secret: "s3cret"}},
+		},
+	})
+
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "id": 123, "name":"api", "owner":{"login":"EBKopec" }}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/topics",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"names": ["go"]}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/branches/main/protection",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+
+	request := repositories.CreateRepoRequest{
+		Name:         "api",
+		Preset:       "go-service",
+		RepoSettings: repositories.RepoSettings{Topics: []string{"Go"}},
+	}
+	result, err := RepositoryService.CreateRepo("client", request)
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.EqualValues(t, "go-service", result.Preset)
+	assert.NotNil(t, result.Settings)
+	assert.True(t, result.Settings.IsPrivate())
+	assert.EqualValues(t, "Go", result.Settings.GitignoreTemplate)
+	assert.EqualValues(t, "", result.Settings.Webhooks[0].Secret)
+
+	// the webhook could not be created, the repository is kept nonetheless
+	assert.NotNil(t, result.SettingsError)
+	assert.EqualValues(t, 123, result.Id)
+
+	records, _ := audit.Store.Find(audit.RecordFilter{ClientId: "client"})
+	assert.EqualValues(t, 1, len(records))
+	assert.EqualValues(t, audit.StatusSucceeded, records[0].Status)
+	assert.EqualValues(t, "", records[0].Request.Webhooks[0].Secret)
+
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/hooks",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"id": 1}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+	result, err = RepositoryService.CreateRepo("client", request)
+	assert.Nil(t, err)
+	assert.Nil(t, result.SettingsError)
+}
+
+func TestCreateRepoLabelFailureStopsSettings(t *testing.T) {
+	addCreateRepoMock()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/testing/labels",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"message": "Must have admin rights to Repository."}`,
+		Response:   &http.Response{StatusCode: http.StatusForbidden},
+	})
+	request := repositories.CreateRepoRequest{
+		Name: "testing",
+		RepoSettings: repositories.RepoSettings{
+			Labels:   []repositories.Label{{Name: "bug", Color: "d73a4a"}},
+			Webhooks: []repositories.Webhook{{Url: "https://example.com/hook"}},
+		},
+	}
+
+	result, err := RepositoryService.CreateRepo("client", request)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 123, result.Id)
+	assert.NotNil(t, result.SettingsError)
+	assert.EqualValues(t, http.StatusForbidden, result.SettingsError.Status())
+	assert.EqualValues(t, "Must have admin rights to Repository.", result.SettingsError.Message())
+}
+
+func TestCreateRepoUnknownPreset(t *testing.T) {
+	result, err := RepositoryService.CreateRepo("client", repositories.CreateRepoRequest{Name: "api", Preset: "missing"})
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, []interface{}{repositories.FieldError{Field: "preset", Message: "unknown preset 'missing'"}}, err.Causes())
+}
+
+func TestCreateRepoScaffoldsFiles(t *testing.T) {
+	originalTemplates := scaffold.TemplateRegistry
+	defer func() { scaffold.TemplateRegistry = originalTemplates }()
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "README.md.tmpl"), []byte("# {{.FullName}}\n"), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte("* @{{.Owner}}\n"), 0600)
+	scaffold.TemplateRegistry, _ = scaffold.LoadTemplates(dir)
+
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "id": 123, "name":"api", "owner":{"login":"EBKopec" }}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/contents/README.md",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"type": "file", "path": "README.md", "sha": "initial"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/contents/README.md",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"content": {"path": "README.md", "sha": "abc"}, "commit": {"sha": "readme-commit"}}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/contents/CODEOWNERS",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"message": "This repository is empty."}`,
+		Response:   &http.Response{StatusCode: http.StatusNotFound},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/repos/EBKopec/api/contents/CODEOWNERS",
+		HttpMethod: http.MethodPut,
+		BodyText:   `{"message": "Invalid request."}`,
+		Response:   &http.Response{StatusCode: http.StatusUnprocessableEntity},
+	})
+
+	request := repositories.CreateRepoRequest{Name: "api", RepoSettings: repositories.RepoSettings{Scaffold: []string{"README.md", "CODEOWNERS"}}}
+	result, err := RepositoryService.CreateRepo("client", request)
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+	assert.EqualValues(t, 2, len(result.Files))
+	assert.EqualValues(t, repositories.ScaffoldResult{Path: "README.md", Committed: true, CommitSha: "readme-commit"}, result.Files[0])
+	assert.EqualValues(t, "CODEOWNERS", result.Files[1].Path)
+	assert.False(t, result.Files[1].Committed)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, result.Files[1].Error.Status())
+	assert.EqualValues(t, []string{"README.md", "CODEOWNERS"}, result.Settings.Scaffold)
+}
+
+func TestCreateRepoUnknownScaffoldTemplate(t *testing.T) {
+	request := repositories.CreateRepoRequest{Name: "api", RepoSettings: repositories.RepoSettings{Scaffold: []string{"LICENSE"}}}
+	result, err := RepositoryService.CreateRepo("client", request)
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, []interface{}{repositories.FieldError{Field: "scaffold[0]", Message: "unknown scaffold template 'LICENSE'"}}, err.Causes())
+}
+
+func addCreateRepoMock() {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"login": "EBKopec"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{ "id": 123, "name":"testing", "owner":{"login":"EBKopec" }}`,
+		Response:   &http.Response{StatusCode: http.StatusCreated},
+	})
+}
+
+func TestCreateReposRejectsDuplicates(t *testing.T) {
+	addCreateRepoMock()
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: " Testing "}, {Name: "other"}}
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, result.StatusCode)
+	assert.EqualValues(t, 3, len(result.Results))
+	assert.NotNil(t, result.Results[0].Response)
+	assert.NotNil(t, result.Results[2].Response)
+
+	duplicate := result.Results[1]
+	assert.Nil(t, duplicate.Response)
+	assert.NotNil(t, duplicate.Error)
+	assert.EqualValues(t, http.StatusConflict, duplicate.Error.Status())
+	assert.EqualValues(t, "repository name already used by item 0 of the batch", duplicate.Error.Message())
+	assert.EqualValues(t, repositories.SkipReasonDuplicate, duplicate.Skipped)
+	assert.EqualValues(t, 0, *duplicate.DuplicateOf)
+}
+
+func TestCreateReposCollapsesDuplicates(t *testing.T) {
+	addCreateRepoMock()
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: "TESTING"}}
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{Duplicates: repositories.DuplicatesCollapse})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusCreated, result.StatusCode)
+	assert.NotNil(t, result.Results[0].Response)
+	assert.Nil(t, result.Results[1].Response)
+	assert.Nil(t, result.Results[1].Error)
+	assert.EqualValues(t, repositories.SkipReasonDuplicate, result.Results[1].Skipped)
+	assert.EqualValues(t, 0, *result.Results[1].DuplicateOf)
+}
+
+func TestCreateReposDuplicatesOfInvalidRequests(t *testing.T) {
+	addCreateRepoMock()
+	requests := []repositories.CreateRepoRequest{{Name: "testing", Homepage: "not a url"}, {Name: "testing"}}
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, result.StatusCode)
+	assert.NotNil(t, result.Results[0].Error)
+	assert.EqualValues(t, "", result.Results[0].Skipped)
+	assert.NotNil(t, result.Results[1].Response)
+}
+
+func TestCreateReposInvalidDuplicatesOption(t *testing.T) {
+	_, err := RepositoryService.CreateRepos(context.Background(), "", []repositories.CreateRepoRequest{{Name: "testing"}}, repositories.CreateReposOptions{Duplicates: "merge"})
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, "duplicates must be one of reject or collapse", err.Message())
+}
+
+func TestCreateReposAtomicRejectsDuplicates(t *testing.T) {
+	restclient.FlushMocks()
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: "testing"}}
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{Atomic: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusConflict, result.StatusCode)
+	assert.NotNil(t, result.Failure)
+	assert.EqualValues(t, 1, result.Failure.Index)
+	assert.EqualValues(t, repositories.SkipReasonDuplicate, result.Failure.Skipped)
+	assert.EqualValues(t, 0, len(result.Compensations))
+}
+
+func TestCreateReposCheckExisting(t *testing.T) {
+	addCreateRepoMock()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"login": "EBKopec"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/graphql",
+		HttpMethod: http.MethodPost,
+		BodyText: `{
+			"data": {"r0": {"id": "R_1"}, "r1": null, "rateLimit": {"cost": 1, "remaining": 4999}},
+			"errors": [{"type": "NOT_FOUND", "path": ["r1"], "message": "Could not resolve to a Repository with the name 'EBKopec/testing-2'."}]
+		}`,
+		Response: &http.Response{StatusCode: http.StatusOK},
+	})
+	requests := []repositories.CreateRepoRequest{{Name: "testing"}, {Name: "testing-2"}, {Name: "testing"}}
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "", requests, repositories.CreateReposOptions{CheckExisting: true})
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusPartialContent, result.StatusCode)
+
+	assert.NotNil(t, result.Results[0].Error)
+	assert.EqualValues(t, http.StatusConflict, result.Results[0].Error.Status())
+	assert.EqualValues(t, "repository EBKopec/testing already exists", result.Results[0].Error.Message())
+	assert.EqualValues(t, repositories.SkipReasonExists, result.Results[0].Skipped)
+	assert.NotNil(t, result.Results[1].Response)
+	assert.EqualValues(t, repositories.SkipReasonDuplicate, result.Results[2].Skipped)
+}
+
+func TestCreateReposCheckExistingFails(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user",
+		HttpMethod: http.MethodGet,
+		BodyText:   `{"login": "EBKopec"}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/graphql",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded for user ID 1."}]}`,
+		Response:   &http.Response{StatusCode: http.StatusOK},
+	})
+
+	result, err := RepositoryService.CreateRepos(context.Background(), "", []repositories.CreateRepoRequest{{Name: "testing"}}, repositories.CreateReposOptions{CheckExisting: true})
+	assert.NotNil(t, err)
+	assert.True(t, stderrors.Is(err, errors.ErrRateLimited))
+	assert.EqualValues(t, 0, len(result.Results))
+}
+
+func TestCreateRepoNameTaken(t *testing.T) {
+	restclient.FlushMocks()
+	restclient.AddMockups(restclient.Mock{
+		Url:        "https://api.github.com/user/repos",
+		HttpMethod: http.MethodPost,
+		BodyText:   `{"message": "Repository creation failed.", "errors": [{"resource": "Repository", "code": "custom", "field": "name", "message": "name already exists on this account"}]}`,
+		Response:   &http.Response{StatusCode: http.StatusUnprocessableEntity},
+	})
+
+	result, err := RepositoryService.CreateRepo("", repositories.CreateRepoRequest{Name: "testing"})
+	assert.Nil(t, result)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusConflict, err.Status())
+	assert.EqualValues(t, "repository testing already exists", err.Message())
+}
+
+func TestCreateRepoWaitsForCreationOfSameName(t *testing.T) {
+	addCreateRepoMock()
+	unlock := creationLocks.Lock(creationLockKey("EBKopec", "testing"))
+
+	done := make(chan errors.ApiError)
+	go func() {
+		_, err := RepositoryService.CreateRepo("other-client", repositories.CreateRepoRequest{Name: " Testing ", Description: "other"})
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("creation did not wait for the one of the same name")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	assert.Nil(t, <-done)
+}
+
+func TestCreateRepoCoalescesIdenticalRequests(t *testing.T) {
+	originalStore := audit.Store
+	defer func() { audit.Store = originalStore }()
+	audit.Store = audit.NewMemoryStore()
+
+	addCreateRepoMock()
+	unlock := creationLocks.Lock(creationLockKey("EBKopec", "testing"))
+
+	var wg sync.WaitGroup
+	results := make([]*repositories.CreateRepoResponse, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := RepositoryService.CreateRepo("coalesced", repositories.CreateRepoRequest{Name: "testing"})
+			assert.Nil(t, err)
+			results[i] = result
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	unlock()
+	wg.Wait()
+
+	for _, result := range results {
+		assert.NotNil(t, result)
+		assert.EqualValues(t, 123, result.Id)
+	}
+	records, _ := audit.Store.Find(audit.RecordFilter{Action: audit.ActionCreate})
+	assert.EqualValues(t, 1, len(records))
+	assert.EqualValues(t, 1, clients.UsageDao.Get("coalesced").ReposCreated)
+}
@@ -1,25 +1,50 @@
 package services
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"github.com/evertonkopec/golang-microservices-main/src/api/config"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/audit"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/clients"
 	"github.com/evertonkopec/golang-microservices-main/src/api/domain/github"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/manifests"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/policies"
 	"github.com/evertonkopec/golang-microservices-main/src/api/domain/repositories"
+	"github.com/evertonkopec/golang-microservices-main/src/api/domain/scaffold"
 	"github.com/evertonkopec/golang-microservices-main/src/api/log/option_b"
 	"github.com/evertonkopec/golang-microservices-main/src/api/providers/github_provider"
 	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/locks"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 type reposService struct{}
 
 type reposServiceInterface interface {
 	CreateRepo(clientId string, request repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError)
-	CreateRepos(request []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError)
+	CreateRepos(ctx context.Context, clientId string, request []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (repositories.CreateReposResponse, errors.ApiError)
+	StreamRepos(ctx context.Context, clientId string, request []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (<-chan repositories.CreateReposEvent, errors.ApiError)
+	DryRunRepos(clientId string, request []repositories.CreateRepoRequest) (repositories.DryRunResponse, errors.ApiError)
 }
 
 var (
 	RepositoryService reposServiceInterface
+
+	// creations coalesces the identical creations a client asks for at once
+	// and creationLocks runs the other creations of an owner/name one at a
+	// time, so GitHub never sees two of them racing.
+	creations     locks.Group
+	creationLocks locks.KeyedMutex
+	// creationOwners caches, by hash of the token, the account repositories
+	// created with a token belong to.
+	creationOwners sync.Map
 )
 
 func init() {
@@ -27,6 +52,263 @@ func init() {
 }
 
 func (s *reposService) CreateRepo(clientId string, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	day, err := consumeRepoQuota(clientId, 1)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.provisionRepo(clientId, day, input)
+	if err != nil {
+		clients.UsageDao.RefundRepos(clientId, day, 1)
+	}
+	return result, err
+}
+
+// provisionRepo checks the request against the policies and creates the
+// repository, or queues it for an admin when a policy asks for approval.
+// Quotas are up to the caller, who counted the repository on day; a queued
+// request keeps its quota until it is decided.
+func (s *reposService) provisionRepo(clientId string, day string, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	if input.Validate() == nil {
+		decision := policies.PolicyEngine.Evaluate(clientId, input)
+		switch decision.Outcome {
+		case policies.OutcomeDeny:
+			err := policyDeniedError(decision)
+			recordCreate(clientId, input, nil, err)
+			return nil, err
+		case policies.OutcomeRequireApproval:
+			return ApprovalsService.Request(clientId, day, input, decision)
+		}
+	}
+	return s.createAndRecord(clientId, day, input)
+}
+
+// createAndRecord creates the repository on GitHub and records the attempt,
+// whatever its outcome, in the audit store. A request identical to one of the
+// same client still in flight gets the outcome of that one instead, and its
+// quota, counted on day, back; failures, shared or not, are refunded by the
+// caller. Creations are not coalesced across clients: the repository,
+// its audit record and its drift checks belong to the client that created
+// it, so the creation of another client waits and is refused as a conflict.
+func (s *reposService) createAndRecord(clientId string, day string, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	name := strings.ToLower(strings.TrimSpace(input.Name))
+	request, _ := json.Marshal(input)
+	key := fmt.Sprintf("%s:%s:%x", clientId, name, sha256.Sum256(request))
+
+	value, err, shared := creations.Do(key, func() (interface{}, errors.ApiError) {
+		unlock := creationLocks.Lock(creationLockKey(creationOwner(clientId), name))
+		defer unlock()
+		return s.createAndRecordLocked(clientId, input)
+	})
+	if err != nil {
+		return nil, err
+	}
+	created, isResponse := value.(*repositories.CreateRepoResponse)
+	if !isResponse || created == nil {
+		return nil, errors.NewInternalServerError("error when trying to create repository")
+	}
+	result := *created
+	if shared {
+		clients.UsageDao.RefundRepos(clientId, day, 1)
+		// private keys of generated deploy keys only go to the caller that
+		// created them
+		result = result.WithoutPrivateKeys()
+		option_b.Info("repository creation shared with a concurrent identical request",
+			option_b.Field("client_id", clientId),
+			option_b.Field("repository", fmt.Sprintf("%s/%s", result.Owner, result.Name)))
+	}
+	return &result, nil
+}
+
+func creationLockKey(owner string, name string) string {
+	return strings.ToLower(fmt.Sprintf("%s/%s", owner, strings.TrimSpace(name)))
+}
+
+// creationOwner returns the account the repositories of clientId are created
+// under. It is empty when GitHub can not tell, in which case the creations of
+// a name wait for each other whatever their owner.
+func creationOwner(clientId string) string {
+	token, err := CredentialsService.AccessToken(clientId)
+	if err != nil {
+		return ""
+	}
+	tokenHash := sha256.Sum256([]byte(token))
+	if owner, cached := creationOwners.Load(tokenHash); cached {
+		return owner.(string)
+	}
+	user, userErr := github_provider.GetAuthenticatedUser(token)
+	if userErr != nil {
+		return ""
+	}
+	creationOwners.Store(tokenHash, user.Login)
+	return user.Login
+}
+
+func (s *reposService) createAndRecordLocked(clientId string, input repositories.CreateRepoRequest) (*repositories.CreateRepoResponse, errors.ApiError) {
+	response, err := s.createRepo(clientId, &input)
+	recordCreate(clientId, input, response, err)
+	if err != nil {
+		return nil, err
+	}
+	DriftService.Manage(clientId, response.Owner.Login, createdRepoSpec(input))
+
+	// files go first, protected branches would reject the commits
+	result := repositories.CreateRepoResponse{
+		Id:     response.Id,
+		Name:   response.Name,
+		Owner:  response.Owner.Login,
+		Preset: input.Preset,
+		Files:  scaffoldFiles(clientId, response.Owner.Login, input),
+	}
+	result.SettingsError = applySettings(clientId, response.Owner.Login, response.Name, input.RepoSettings)
+	if result.SettingsError == nil {
+		result.DeployKeys, result.SettingsError = provisionAccess(clientId, response.Owner.Login, response.Name, input.RepoSettings)
+	}
+	if input.Preset != "" || !input.RepoSettings.IsZero() {
+		settings := input.RepoSettings.Redacted()
+		result.Settings = &settings
+	}
+	return &result, nil
+}
+
+// applySettings applies what can only be set once the repository exists,
+// stopping at the first failure. The repository is kept either way, so the
+// failure is reported along with it rather than failing the creation.
+func applySettings(clientId string, owner string, name string, settings repositories.RepoSettings) errors.ApiError {
+	if len(settings.Topics) == 0 && len(settings.Labels) == 0 && len(settings.BranchProtection) == 0 && len(settings.Webhooks) == 0 {
+		return nil
+	}
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	var err errors.ApiError
+	if len(settings.Topics) > 0 {
+		err = upstreamError(github_provider.ReplaceTopics(token, owner, name, manifests.NormalizeTopics(settings.Topics)))
+	}
+	for _, label := range repositories.NormalizeLabels(settings.Labels) {
+		if err != nil {
+			break
+		}
+		_, err = ensureLabel(token, owner, name, label)
+	}
+	for _, protection := range settings.BranchProtection {
+		if err != nil {
+			break
+		}
+		request := manifests.BranchProtectionSpec(protection).UpdateBranchProtectionRequest()
+		err = upstreamError(github_provider.UpdateBranchProtection(token, owner, name, protection.Branch, request))
+	}
+	for _, webhook := range settings.Webhooks {
+		if err != nil {
+			break
+		}
+		_, hookErr := github_provider.CreateHook(token, owner, name, createHookRequest(webhook))
+		err = upstreamError(hookErr)
+	}
+
+	if err != nil {
+		option_b.Error("error when trying to apply repository settings", err,
+			option_b.Field("client_id", clientId),
+			option_b.Field("repository", fmt.Sprintf("%s/%s", owner, name)))
+	}
+	return err
+}
+
+// upstreamError reports what GitHub refused, if anything.
+func upstreamError(err *github.GithubErrorResponse) errors.ApiError {
+	if err == nil {
+		return nil
+	}
+	return errors.NewUpstreamError(err, err.StatusCode, err.Message)
+}
+
+// scaffoldFiles renders and commits every scaffolded file of the request. A
+// file that fails does not stop the others.
+func scaffoldFiles(clientId string, owner string, input repositories.CreateRepoRequest) []repositories.ScaffoldResult {
+	if len(input.Scaffold) == 0 {
+		return nil
+	}
+	result := make([]repositories.ScaffoldResult, 0, len(input.Scaffold))
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	data := scaffold.Data{
+		Owner:       owner,
+		Name:        input.Name,
+		FullName:    fmt.Sprintf("%s/%s", owner, input.Name),
+		Description: input.Description,
+		Homepage:    input.Homepage,
+		Private:     input.IsPrivate(),
+		Topics:      manifests.NormalizeTopics(input.Topics),
+		ClientId:    clientId,
+		Preset:      input.Preset,
+		Year:        time.Now().UTC().Year(),
+	}
+
+	for _, path := range input.Scaffold {
+		current := repositories.ScaffoldResult{Path: path, Error: apiErr}
+		if apiErr == nil {
+			current.CommitSha, current.Error = commitFile(token, owner, input.Name, path, data)
+			current.Committed = current.Error == nil
+		}
+		if current.Error != nil {
+			option_b.Error("error when trying to scaffold file", current.Error,
+				option_b.Field("client_id", clientId),
+				option_b.Field("repository", data.FullName),
+				option_b.Field("path", path))
+		}
+		result = append(result, current)
+	}
+	return result
+}
+
+// commitFile creates the file at path, replacing the one an initial commit
+// may have created already, and returns the sha of the commit.
+func commitFile(token string, owner string, name string, path string, data scaffold.Data) (string, errors.ApiError) {
+	content, renderErr := scaffold.TemplateRegistry.Render(path, data)
+	if renderErr != nil {
+		return "", errors.Wrap(renderErr, http.StatusInternalServerError, fmt.Sprintf("error when trying to render scaffold template %s", path))
+	}
+
+	request := github.CreateFileRequest{
+		Message: fmt.Sprintf("Add %s", path),
+		Content: base64.StdEncoding.EncodeToString(content),
+	}
+	existing, err := github_provider.GetContent(token, owner, name, path)
+	switch {
+	case err == nil:
+		request.Sha = existing.Sha
+	case err.StatusCode != http.StatusNotFound:
+		return "", errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+
+	response, err := github_provider.CreateOrUpdateFile(token, owner, name, path, request)
+	if err != nil {
+		return "", errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	return response.Commit.Sha, nil
+}
+
+func createHookRequest(webhook repositories.Webhook) github.CreateHookRequest {
+	result := github.CreateHookRequest{
+		Name:   github.HookNameWeb,
+		Active: true,
+		Events: webhook.Events,
+		Config: github.HookConfig{
+			Url:         webhook.Url,
+			ContentType: webhook.ContentType,
+			Secret:      webhook.Secret,
+		},
+	}
+	if len(result.Events) == 0 {
+		result.Events = []string{"push"}
+	}
+	if result.Config.ContentType == "" {
+		result.Config.ContentType = repositories.WebhookContentTypeJson
+	}
+	return result
+}
+
+func (s *reposService) createRepo(clientId string, input *repositories.CreateRepoRequest) (*github.CreateRepoResponse, errors.ApiError) {
 	if err := input.Validate(); err != nil {
 		return nil, err
 	}
@@ -34,7 +316,12 @@ func (s *reposService) CreateRepo(clientId string, input repositories.CreateRepo
 	request := github.CreateRepoRequest{
 		Name:        input.Name,
 		Description: input.Description,
-		Private:     false,
+		Homepage:    input.Homepage,
+		Private:     input.IsPrivate(),
+		AutoInit:    input.NeedsInitialCommit(),
+
+		LicenseTemplate:   input.LicenseTemplate,
+		GitignoreTemplate: input.GitignoreTemplate,
 	}
 	//option_a.Info("about to send request to external api", fmt.Sprintf("client_id:%s",clientId), "status:pending")
 	option_b.Info("about to send request to external api",
@@ -42,13 +329,20 @@ func (s *reposService) CreateRepo(clientId string, input repositories.CreateRepo
 		option_b.Field("status", "pending"),
 		option_b.Field("authenticated", clientId != ""))
 
-	response, err := github_provider.CreateRepo(config.GetGithubAccessToken(), request)
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	response, err := github_provider.CreateRepo(token, request)
 	if err != nil {
 		option_b.Error("response obtained from external api", err,
 			option_b.Field("client_id", clientId),
 			option_b.Field("status", "error"),
 			option_b.Field("authenticated", clientId != ""))
-		return nil, errors.NewApiError(err.StatusCode, err.Message)
+		if isNameTaken(err) {
+			return nil, errors.NewConflictError(fmt.Sprintf("repository %s already exists", input.Name))
+		}
+		return nil, errors.NewUpstreamError(err, err.StatusCode, err.Message)
 	}
 
 	//option_a.Info("response obtained from external api", fmt.Sprintf("client_id:%s",clientId), "status:success")
@@ -56,16 +350,76 @@ func (s *reposService) CreateRepo(clientId string, input repositories.CreateRepo
 		option_b.Field("client_id", clientId),
 		option_b.Field("status", "success"),
 		option_b.Field("authenticated", clientId != ""))
+	return response, nil
+}
 
-	result := repositories.CreateRepoResponse{
-		Id:    response.Id,
-		Name:  response.Name,
-		Owner: response.Owner.Login,
+// isNameTaken tells whether GitHub refused a creation because the account
+// already has a repository with that name, which is what the loser of a race
+// between two creations gets.
+func isNameTaken(err *github.GithubErrorResponse) bool {
+	if err.StatusCode != http.StatusUnprocessableEntity {
+		return false
 	}
-	return &result, nil
+	for _, current := range err.Errors {
+		if current.Field == "name" && strings.Contains(current.Message, "already exists") {
+			return true
+		}
+	}
+	return false
+}
+
+func recordCreate(clientId string, input repositories.CreateRepoRequest, response *github.CreateRepoResponse, err errors.ApiError) {
+	input.RepoSettings = input.RepoSettings.Redacted()
+	record := audit.NewRepositoryRecord(clientId, audit.ActionCreate, "", input.Name, http.StatusCreated, err)
+	record.Request = &input
+	record.Response = response
+	if response != nil {
+		record.Owner = response.Owner.Login
+	}
+	saveRecord(record)
+}
+
+func policyDeniedError(decision policies.Decision) errors.ApiError {
+	causes := make([]interface{}, 0, len(decision.Violations))
+	for _, violation := range decision.Violations {
+		causes = append(causes, violation)
+	}
+	return errors.NewApiErrorWithCauses(http.StatusForbidden, fmt.Sprintf("repository request denied by policy %s", decision.Rule), causes...)
 }
 
-func (s *reposService) CreateRepos(requests []repositories.CreateRepoRequest) (repositories.CreateReposResponse, errors.ApiError) {
+// saveRecord stores record in the audit store. Failing to audit an attempt
+// must not fail the attempt itself, so errors are only logged.
+func saveRecord(record audit.RepositoryRecord) {
+	if _, err := audit.Store.Save(record); err != nil {
+		option_b.Error("error when trying to save audit record", err,
+			option_b.Field("client_id", record.ClientId),
+			option_b.Field("action", record.Action),
+			option_b.Field("repository", record.Name))
+	}
+}
+
+func (s *reposService) CreateRepos(ctx context.Context, clientId string, requests []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (repositories.CreateReposResponse, errors.ApiError) {
+	if err := validateBatch(clientId, requests); err != nil {
+		return repositories.CreateReposResponse{}, err
+	}
+	if err := options.Validate(); err != nil {
+		return repositories.CreateReposResponse{}, err
+	}
+	if _, err := CredentialsService.AccessToken(clientId); err != nil {
+		return repositories.CreateReposResponse{}, err
+	}
+	skipped, err := screenBatch(clientId, requests, options)
+	if err != nil {
+		return repositories.CreateReposResponse{}, err
+	}
+	day, err := consumeRepoQuota(clientId, len(requests))
+	if err != nil {
+		return repositories.CreateReposResponse{}, err
+	}
+	if options.Atomic {
+		return s.createReposAtomic(ctx, clientId, day, requests, skipped), nil
+	}
+
 	input := make(chan repositories.CreateRepositoriesResult)
 	output := make(chan repositories.CreateReposResponse)
 	defer close(output)
@@ -73,59 +427,460 @@ func (s *reposService) CreateRepos(requests []repositories.CreateRepoRequest) (r
 	var wg sync.WaitGroup
 	go s.handleRepoResults(&wg, input, output)
 
-	// n requests to process
-	for _, current := range requests {
-		wg.Add(1)
-		go s.createRepoConcurrent(current, input)
-	}
+	wg.Add(len(requests))
+	s.dispatchRepos(ctx, clientId, day, requests, skipped, input, nil)
 	wg.Wait()
 	close(input)
 
 	result := <-output
+	result.StatusCode = batchStatusCode(result.Results)
+	return result, nil
 
-	successCreations := 0
+}
+
+// StreamRepos validates the batch and processes it in the background. Every
+// result is sent as soon as it is available, followed by the summary of the
+// batch, and the channel is closed afterwards. Callers must drain the channel.
+func (s *reposService) StreamRepos(ctx context.Context, clientId string, requests []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (<-chan repositories.CreateReposEvent, errors.ApiError) {
+	if err := validateBatch(clientId, requests); err != nil {
+		return nil, err
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := CredentialsService.AccessToken(clientId); err != nil {
+		return nil, err
+	}
+	skipped, err := screenBatch(clientId, requests, options)
+	if err != nil {
+		return nil, err
+	}
+	day, err := consumeRepoQuota(clientId, len(requests))
+	if err != nil {
+		return nil, err
+	}
+
+	input := make(chan repositories.CreateRepositoriesResult)
+	output := make(chan repositories.CreateReposEvent)
+	go s.dispatchRepos(ctx, clientId, day, requests, skipped, input, nil)
+	go func() {
+		defer close(output)
+
+		results := make([]repositories.CreateRepositoriesResult, 0, len(requests))
+		for i := 0; i < len(requests); i++ {
+			result := <-input
+			results = append(results, result)
+			output <- repositories.CreateReposEvent{Result: &result}
+		}
+
+		summary := repositories.CreateReposSummary{
+			StatusCode: batchStatusCode(results),
+			Total:      len(results),
+		}
+		for _, current := range results {
+			switch {
+			case current.Response != nil:
+				summary.Succeeded++
+			case current.Error != nil:
+				summary.Failed++
+			default:
+				summary.Skipped++
+			}
+		}
+		output <- repositories.CreateReposEvent{Summary: &summary}
+	}()
+	return output, nil
+}
+
+// createReposAtomic creates the whole batch or nothing: the first failure stops
+// the remaining creations and every repository already created is deleted.
+func (s *reposService) createReposAtomic(ctx context.Context, clientId string, day string, requests []repositories.CreateRepoRequest, skipped map[int]repositories.CreateRepositoriesResult) repositories.CreateReposResponse {
+	var result repositories.CreateReposResponse
+
+	// nothing is created when any request is invalid or not allowed right away
+	for index := range requests {
+		current, isSkipped := skipped[index]
+		if !isSkipped {
+			current = repositories.CreateRepositoriesResult{Index: index, Error: checkAtomicPolicy(clientId, requests[index])}
+		}
+		if current.Error != nil {
+			result.Results = append(result.Results, current)
+			if result.Failure == nil {
+				result.Failure = &current
+			}
+		}
+	}
+	if result.Failure != nil {
+		clients.UsageDao.RefundRepos(clientId, day, len(requests))
+		result.StatusCode = result.Failure.Error.Status()
+		return result
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	input := make(chan repositories.CreateRepositoriesResult)
+	go s.dispatchRepos(ctx, clientId, day, requests, skipped, input, nil)
+
+	result.Results = make([]repositories.CreateRepositoriesResult, len(requests))
+	for i := 0; i < len(requests); i++ {
+		current := <-input
+		result.Results[current.Index] = current
+		if current.Error != nil && result.Failure == nil {
+			result.Failure = &current
+			cancel()
+		}
+	}
+
+	if result.Failure == nil {
+		result.StatusCode = http.StatusCreated
+		return result
+	}
+
+	option_b.Info("atomic batch failed, about to delete created repositories",
+		option_b.Field("client_id", clientId),
+		option_b.Field("failed_index", result.Failure.Index))
 	for _, current := range result.Results {
 		if current.Response != nil {
-			successCreations++
+			result.Compensations = append(result.Compensations, s.compensate(clientId, day, current))
 		}
 	}
+	result.StatusCode = result.Failure.Error.Status()
+	return result
+}
 
-	if successCreations == 0 {
-		result.StatusCode = result.Results[0].Error.Status()
-	} else if successCreations == len(requests) {
-		result.StatusCode = http.StatusCreated
+// checkAtomicPolicy fails requests a policy denies or holds for approval,
+// since an atomic batch can not wait for an admin.
+func checkAtomicPolicy(clientId string, request repositories.CreateRepoRequest) errors.ApiError {
+	decision := policies.PolicyEngine.Evaluate(clientId, request)
+	switch decision.Outcome {
+	case policies.OutcomeDeny:
+		return policyDeniedError(decision)
+	case policies.OutcomeRequireApproval:
+		return errors.NewApiError(http.StatusUnprocessableEntity, fmt.Sprintf("repository requires approval by policy %s and can not be part of an atomic batch", decision.Rule))
+	}
+	return nil
+}
+
+func (s *reposService) compensate(clientId string, day string, created repositories.CreateRepositoriesResult) repositories.CompensationResult {
+	result := repositories.CompensationResult{
+		Index: created.Index,
+		Owner: created.Response.Owner,
+		Name:  created.Response.Name,
+	}
+
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		result.Error = apiErr
+		return result
+	}
+	if err := github_provider.DeleteRepo(token, result.Owner, result.Name); err != nil {
+		option_b.Error("error when trying to delete repository of failed atomic batch", err,
+			option_b.Field("client_id", clientId),
+			option_b.Field("repository", fmt.Sprintf("%s/%s", result.Owner, result.Name)))
+		result.Error = errors.NewUpstreamError(err, err.StatusCode, err.Message)
 	} else {
-		result.StatusCode = http.StatusPartialContent
+		result.Deleted = true
+		DriftService.Unmanage(result.Owner, result.Name)
+		// a rolled back repository no longer counts against the quota
+		clients.UsageDao.RefundRepos(clientId, day, 1)
+	}
+
+	saveRecord(audit.NewRepositoryRecord(clientId, audit.ActionDelete, result.Owner, result.Name, http.StatusNoContent, result.Error))
+	return result
+}
+
+// DryRunRepos reports what creating the batch would do without creating
+// anything: every request is validated, checked against the rest of the batch
+// and looked up on GitHub.
+func (s *reposService) DryRunRepos(clientId string, requests []repositories.CreateRepoRequest) (repositories.DryRunResponse, errors.ApiError) {
+	var result repositories.DryRunResponse
+	if err := validateBatch(clientId, requests); err != nil {
+		return result, err
+	}
+
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return result, apiErr
+	}
+	user, err := github_provider.GetAuthenticatedUser(token)
+	if err != nil {
+		return result, errors.NewUpstreamError(err, err.StatusCode, err.Message)
 	}
 
+	duplicates := repositories.FindDuplicates(requests)
+	for index, request := range requests {
+		current := repositories.DryRunResult{
+			Index:  index,
+			Owner:  user.Login,
+			Name:   strings.TrimSpace(request.Name),
+			Action: repositories.DryRunActionReject,
+		}
+		var decision policies.Decision
+		if err := request.Validate(); err != nil {
+			current.Error = err
+		} else if first, isDuplicate := duplicates[index]; isDuplicate {
+			current.Error = errors.NewConflictError(fmt.Sprintf("repository name already used by item %d of the batch", first))
+		} else if decision = policies.PolicyEngine.Evaluate(clientId, request); decision.Outcome == policies.OutcomeDeny {
+			current.Error = policyDeniedError(decision)
+		} else if err := checkRepoAvailable(token, user.Login, request.Name); err != nil {
+			current.Error = err
+		} else if decision.Outcome == policies.OutcomeRequireApproval {
+			current.Action = repositories.DryRunActionRequireApproval
+			current.Policy = decision.Rule
+		} else {
+			current.Action = repositories.DryRunActionCreate
+		}
+
+		switch current.Action {
+		case repositories.DryRunActionCreate:
+			result.Create++
+		case repositories.DryRunActionRequireApproval:
+			result.RequireApproval++
+		default:
+			result.Reject++
+		}
+		result.Results = append(result.Results, current)
+	}
+
+	option_b.Info("dry run completed",
+		option_b.Field("client_id", clientId),
+		option_b.Field("create", result.Create),
+		option_b.Field("require_approval", result.RequireApproval),
+		option_b.Field("reject", result.Reject))
 	return result, nil
+}
 
+// checkRepoAvailable looks the repository up on GitHub and fails when it
+// already exists or when GitHub can not tell.
+func checkRepoAvailable(token string, owner string, name string) errors.ApiError {
+	_, err := github_provider.GetRepo(token, owner, name)
+	switch {
+	case err == nil:
+		return errors.NewConflictError(fmt.Sprintf("repository %s/%s already exists", owner, name))
+	case err.StatusCode == http.StatusNotFound:
+		return nil
+	}
+	return errors.NewUpstreamError(err, err.StatusCode, err.Message)
+}
+
+// screenBatch validates every request of the batch and returns, by index, the
+// results of the ones that must not reach GitHub: invalid requests, names
+// already used earlier in the batch and, with options.CheckExisting,
+// repositories that already exist. Names are compared once trimmed by
+// Validate and ignoring case, the way GitHub does.
+func screenBatch(clientId string, requests []repositories.CreateRepoRequest, options repositories.CreateReposOptions) (map[int]repositories.CreateRepositoriesResult, errors.ApiError) {
+	result := make(map[int]repositories.CreateRepositoriesResult)
+	names := make([]repositories.CreateRepoRequest, len(requests))
+	for index := range requests {
+		if err := requests[index].Validate(); err != nil {
+			result[index] = repositories.CreateRepositoriesResult{Index: index, Error: err}
+			continue
+		}
+		names[index].Name = requests[index].Name
+	}
+
+	for index, first := range repositories.FindDuplicates(names) {
+		first := first
+		current := repositories.CreateRepositoriesResult{
+			Index:       index,
+			Skipped:     repositories.SkipReasonDuplicate,
+			DuplicateOf: &first,
+		}
+		if options.Duplicates != repositories.DuplicatesCollapse {
+			current.Error = errors.NewConflictError(fmt.Sprintf("repository name already used by item %d of the batch", first))
+		}
+		result[index] = current
+	}
+
+	if !options.CheckExisting || len(result) == len(requests) {
+		return result, nil
+	}
+	return result, checkReposExist(clientId, requests, result)
+}
+
+// checkReposExist looks up on GitHub, in as few queries as possible, every
+// request not skipped yet and skips the ones that already exist.
+func checkReposExist(clientId string, requests []repositories.CreateRepoRequest, skipped map[int]repositories.CreateRepositoriesResult) errors.ApiError {
+	token, apiErr := CredentialsService.AccessToken(clientId)
+	if apiErr != nil {
+		return apiErr
+	}
+	user, err := github_provider.GetAuthenticatedUser(token)
+	if err != nil {
+		return errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+
+	indexes := make([]int, 0, len(requests))
+	refs := make([]github.RepositoryRef, 0, len(requests))
+	for index, request := range requests {
+		if _, isSkipped := skipped[index]; !isSkipped {
+			indexes = append(indexes, index)
+			refs = append(refs, github.RepositoryRef{Owner: user.Login, Name: request.Name})
+		}
+	}
+
+	exist, err := github_provider.RepositoriesExist(token, refs)
+	if err != nil {
+		return errors.NewUpstreamError(err, err.StatusCode, err.Message)
+	}
+	for position, index := range indexes {
+		if exist[position] {
+			skipped[index] = repositories.CreateRepositoriesResult{
+				Index:   index,
+				Error:   errors.NewConflictError(fmt.Sprintf("repository %s/%s already exists", user.Login, requests[index].Name)),
+				Skipped: repositories.SkipReasonExists,
+			}
+		}
+	}
+	return nil
+}
+
+// consumeRepoQuota counts count repositories against the daily quota of the
+// client and returns the day they were counted on. Callers refund the ones
+// that end up not being created against that day.
+func consumeRepoQuota(clientId string, count int) (string, errors.ApiError) {
+	quotas := clients.ClientRegistry.Get(clientId).Quotas
+	day, err := clients.UsageDao.ConsumeRepos(clientId, count, quotas.ReposPerDay)
+	if err != nil {
+		option_b.Info("repository quota exceeded",
+			option_b.Field("client_id", clientId),
+			option_b.Field("requested", count),
+			option_b.Field("repos_per_day", quotas.ReposPerDay))
+		return "", err
+	}
+	return day, nil
+}
+
+func validateBatch(clientId string, requests []repositories.CreateRepoRequest) errors.ApiError {
+	if len(requests) == 0 {
+		return errors.NewBadRequestError("no repositories to create")
+	}
+	if maxSize := clients.ClientRegistry.Get(clientId).Quotas.MaxBatchSize; len(requests) > maxSize {
+		return errors.NewBadRequestError(fmt.Sprintf("at most %d repositories can be created in a single batch", maxSize))
+	}
+	return nil
+}
+
+// batchStatusCode reports 202 instead of 201 when every request went through
+// but some of them are waiting for approval.
+func batchStatusCode(results []repositories.CreateRepositoriesResult) int {
+	successCreations := 0
+	pendingApprovals := 0
+	for _, current := range results {
+		if current.Response != nil {
+			successCreations++
+			if current.Response.ApprovalId != "" {
+				pendingApprovals++
+			}
+		}
+	}
+
+	// collapsed duplicates share the outcome of the request they repeat
+	expected := len(results)
+	for _, current := range results {
+		if current.Response == nil && current.Error == nil {
+			expected--
+		}
+	}
+
+	if successCreations == 0 {
+		return firstError(results).Status()
+	} else if successCreations == expected && pendingApprovals > 0 {
+		return http.StatusAccepted
+	} else if successCreations == expected {
+		return http.StatusCreated
+	}
+	return http.StatusPartialContent
+}
+
+func firstError(results []repositories.CreateRepositoriesResult) errors.ApiError {
+	for _, current := range results {
+		if current.Error != nil {
+			return current.Error
+		}
+	}
+	return nil
+}
+
+// dispatchRepos creates every request with at most n of them in flight and
+// sends one result per request to output. Requests in skipped are not created
+// and their result is sent as is. The quota of every request not created,
+// counted on day, is given back. Requests not started once ctx is done are
+// reported as cancelled. started, when set, is called right before a request
+// is processed. It returns once every request has been dispatched.
+func (s *reposService) dispatchRepos(ctx context.Context, clientId string, day string, requests []repositories.CreateRepoRequest, skipped map[int]repositories.CreateRepositoriesResult, output chan repositories.CreateRepositoriesResult, started func(index int)) {
+	buffer := make(chan bool, config.GetRepoBatchConcurrency())
+	for index, current := range requests {
+		if result, isSkipped := skipped[index]; isSkipped {
+			clients.UsageDao.RefundRepos(clientId, day, 1)
+			output <- result
+			continue
+		}
+		if !acquire(ctx, buffer) {
+			clients.UsageDao.RefundRepos(clientId, day, 1)
+			output <- repositories.CreateRepositoriesResult{
+				Index: index,
+				Error: errors.Wrap(ctx.Err(), http.StatusRequestTimeout, "repository creation cancelled"),
+			}
+			continue
+		}
+		if started != nil {
+			started(index)
+		}
+		go func(index int, current repositories.CreateRepoRequest) {
+			defer func() { <-buffer }()
+			s.createRepoConcurrent(clientId, day, index, current, output)
+		}(index, current)
+	}
+}
+
+// acquire waits for a free slot in buffer and reports false once ctx is done.
+func acquire(ctx context.Context, buffer chan bool) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case buffer <- true:
+		return true
+	case <-ctx.Done():
+		return false
+	}
 }
 
+// handleRepoResults collects every result and hands them back in input order.
 func (s *reposService) handleRepoResults(wg *sync.WaitGroup, input chan repositories.CreateRepositoriesResult, output chan repositories.CreateReposResponse) {
 	var results repositories.CreateReposResponse
 
 	for incomingEvent := range input {
 
 		repoResult := repositories.CreateRepositoriesResult{
-			Response: incomingEvent.Response,
-			Error:    incomingEvent.Error,
+			Index:       incomingEvent.Index,
+			Response:    incomingEvent.Response,
+			Error:       incomingEvent.Error,
+			Skipped:     incomingEvent.Skipped,
+			DuplicateOf: incomingEvent.DuplicateOf,
 		}
 		results.Results = append(results.Results, repoResult)
 		wg.Done()
 	}
+	sort.Slice(results.Results, func(i, j int) bool {
+		return results.Results[i].Index < results.Results[j].Index
+	})
 	output <- results
 }
 
-func (s *reposService) createRepoConcurrent(input repositories.CreateRepoRequest, output chan repositories.CreateRepositoriesResult) {
+func (s *reposService) createRepoConcurrent(clientId string, day string, index int, input repositories.CreateRepoRequest, output chan repositories.CreateRepositoriesResult) {
 	if err := input.Validate(); err != nil {
-		output <- repositories.CreateRepositoriesResult{Error: err}
+		clients.UsageDao.RefundRepos(clientId, day, 1)
+		output <- repositories.CreateRepositoriesResult{Index: index, Error: err}
 		return
 	}
-	result, err := s.CreateRepo("TODO_client_id", input)
+	result, err := s.provisionRepo(clientId, day, input)
 	if err != nil {
-		output <- repositories.CreateRepositoriesResult{Error: err}
+		clients.UsageDao.RefundRepos(clientId, day, 1)
+		output <- repositories.CreateRepositoriesResult{Index: index, Error: err}
 		return
 	}
-	output <- repositories.CreateRepositoriesResult{Response: result}
+	output <- repositories.CreateRepositoriesResult{Index: index, Response: result}
 }
@@ -0,0 +1,53 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := NewKey()
+	assert.Nil(t, err)
+
+	ciphertext, err := Encrypt(key, []byte("secret"), []byte("team-a"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(ciphertext), "secret")
+
+	plaintext, err := Decrypt(key, ciphertext, []byte("team-a"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "secret", string(plaintext))
+
+	other, _ := Encrypt(key, []byte("secret"), []byte("team-a"))
+	assert.NotEqual(t, ciphertext, other)
+}
+
+func TestDecryptWrongContext(t *testing.T) {
+	key, _ := NewKey()
+	ciphertext, _ := Encrypt(key, []byte("secret"), []byte("team-a"))
+
+	plaintext, err := Decrypt(key, ciphertext, []byte("team-b"))
+	assert.Nil(t, plaintext)
+	assert.EqualValues(t, ErrInvalidCiphertext, err)
+
+	otherKey, _ := NewKey()
+	_, err = Decrypt(otherKey, ciphertext, []byte("team-a"))
+	assert.EqualValues(t, ErrInvalidCiphertext, err)
+
+	_, err = Decrypt(key, ciphertext[:4], []byte("team-a"))
+	assert.EqualValues(t, ErrInvalidCiphertext, err)
+}
+
+func TestDecodeKey(t *testing.T) {
+	key, _ := NewKey()
+	decoded, err := DecodeKey(base64.StdEncoding.EncodeToString(key))
+	assert.Nil(t, err)
+	assert.EqualValues(t, key, decoded)
+
+	_, err = DecodeKey("not base64!")
+	assert.NotNil(t, err)
+
+	_, err = DecodeKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "key must be 32 bytes long, got 5", err.Error())
+}
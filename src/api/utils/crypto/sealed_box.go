@@ -0,0 +1,23 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"fmt"
+	"golang.org/x/crypto/nacl/box"
+)
+
+const (
+	BoxPublicKeySize = 32
+)
+
+// SealAnonymous encrypts message for the owner of the curve25519 public key
+// as a libsodium crypto_box_seal sealed box: only the key owner can open it
+// and the sender stays anonymous.
+func SealAnonymous(publicKey []byte, message []byte) ([]byte, error) {
+	if len(publicKey) != BoxPublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes long, got %d", BoxPublicKeySize, len(publicKey))
+	}
+	var recipient [BoxPublicKeySize]byte
+	copy(recipient[:], publicKey)
+	return box.SealAnonymous(nil, message, &recipient, rand.Reader)
+}
@@ -0,0 +1,22 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/nacl/box"
+	"testing"
+)
+
+func TestSealAnonymous(t *testing.T) {
+	public, private, err := box.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	sealed, err := SealAnonymous(public[:], []byte("s3cret"))
+	assert.Nil(t, err)
+	opened, ok := box.OpenAnonymous(nil, sealed, public, private)
+	assert.True(t, ok)
+	assert.EqualValues(t, "s3cret", string(opened))
+
+	_, err = SealAnonymous(public[:16], []byte("s3cret"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"golang.org/x/crypto/ssh"
+	"strings"
+)
+
+const (
+	opensshMagic     = "openssh-key-v1\x00"
+	opensshBlockSize = 8
+	pemTypeOpenssh   = "OPENSSH PRIVATE KEY"
+)
+
+// GenerateSSHKey returns a new ed25519 key pair: the public key in the
+// authorized_keys format and the unencrypted private key in the OpenSSH
+// format, both ending with comment.
+func GenerateSSHKey(comment string) (string, string, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	authorizedKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey)))
+	if comment != "" {
+		authorizedKey += " " + comment
+	}
+	private, err := marshalOpensshKey(sshPublicKey, publicKey, privateKey, comment)
+	if err != nil {
+		return "", "", err
+	}
+	return authorizedKey, private, nil
+}
+
+// marshalOpensshKey encodes an ed25519 key as described in OpenSSH's
+// PROTOCOL.key, without encryption.
+func marshalOpensshKey(sshPublicKey ssh.PublicKey, publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey, comment string) (string, error) {
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return "", err
+	}
+
+	private := make([]byte, 0, 256)
+	private = append(private, check[:]...)
+	private = append(private, check[:]...)
+	private = appendSSHString(private, []byte(ssh.KeyAlgoED25519))
+	private = appendSSHString(private, publicKey)
+	private = appendSSHString(private, privateKey)
+	private = appendSSHString(private, []byte(comment))
+	for padding := byte(1); len(private)%opensshBlockSize != 0; padding++ {
+		private = append(private, padding)
+	}
+
+	result := []byte(opensshMagic)
+	result = appendSSHString(result, []byte("none"))
+	result = appendSSHString(result, []byte("none"))
+	result = appendSSHString(result, nil)
+	result = appendUint32(result, 1)
+	result = appendSSHString(result, sshPublicKey.Marshal())
+	result = appendSSHString(result, private)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: pemTypeOpenssh, Bytes: result})), nil
+}
+
+func appendSSHString(buffer []byte, value []byte) []byte {
+	return append(appendUint32(buffer, uint32(len(value))), value...)
+}
+
+func appendUint32(buffer []byte, value uint32) []byte {
+	var encoded [4]byte
+	binary.BigEndian.PutUint32(encoded[:], value)
+	return append(buffer, encoded[:]...)
+}
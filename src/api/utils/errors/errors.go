@@ -2,20 +2,55 @@ package errors
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	problemTypeDefault = "about:blank"
+)
+
+// Sentinel kinds every ApiError can be matched against with errors.Is, so
+// callers can branch on the class of a failure instead of its status code.
+var (
+	ErrNotFound     = errors.New("not_found")
+	ErrConflict     = errors.New("conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrRateLimited  = errors.New("rate_limited")
+	ErrUpstream     = errors.New("upstream")
+	ErrValidation   = errors.New("validation")
+
+	kinds = []error{ErrNotFound, ErrConflict, ErrUnauthorized, ErrRateLimited, ErrUpstream, ErrValidation}
 )
 
 type ApiError interface {
 	Status() int
 	Message() string
 	Error() string
+	Causes() []interface{}
 }
 
+// apiError is rendered as an RFC 7807 problem document. Type, title, status,
+// detail and instance are the standard members; everything else is an extension.
 type apiError struct {
-	AStatus  int    `json:"status"`
-	AMessage string `json:"message"`
-	AnError   string `json:"error,omitempty"`
+	XMLName    xml.Name      `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	AType      string        `json:"type" xml:"type"`
+	ATitle     string        `json:"title" xml:"title"`
+	AStatus    int           `json:"status" xml:"status"`
+	AMessage   string        `json:"detail" xml:"detail"`
+	AInstance  string        `json:"instance,omitempty" xml:"instance,omitempty"`
+	ACode      string        `json:"code,omitempty" xml:"code,omitempty"`
+	ARequestId string        `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	ACauses    []interface{} `json:"causes,omitempty" xml:"causes>cause,omitempty"`
+	ARetry     int           `json:"retry_after,omitempty" xml:"retry_after,omitempty"`
+
+	kind  error
+	cause error
 }
 
 func (e *apiError) Status() int {
@@ -27,42 +62,176 @@ func (e *apiError) Message() string {
 }
 
 func (e *apiError) Error() string {
-	return e.AnError
+	message := e.AMessage
+	if message == "" {
+		message = http.StatusText(e.AStatus)
+	}
+	if e.cause != nil && e.cause.Error() != message {
+		return fmt.Sprintf("%s: %s", message, e.cause.Error())
+	}
+	return message
 }
 
-func NewApiError(statusCode int, message string) ApiError {
-	return &apiError{
+func (e *apiError) Causes() []interface{} {
+	return e.ACauses
+}
+
+// RetryAfter returns how long the client should wait before trying again,
+// zero when there is no hint.
+func (e *apiError) RetryAfter() time.Duration {
+	return time.Duration(e.ARetry) * time.Second
+}
+
+func (e *apiError) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target is the kind of this error. Errors decoded from
+// bytes carry no kind, so it is recovered from the code or the status.
+func (e *apiError) Is(target error) bool {
+	return target != nil && target == e.Kind()
+}
+
+func (e *apiError) Kind() error {
+	if e.kind != nil {
+		return e.kind
+	}
+	for _, kind := range kinds {
+		if kind.Error() == e.ACode {
+			return kind
+		}
+	}
+	return kindFromStatus(e.AStatus)
+}
+
+func kindFromStatus(statusCode int) error {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusConflict:
+		return ErrConflict
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity:
+		return ErrValidation
+	case statusCode == http.StatusBadGateway || statusCode == http.StatusServiceUnavailable || statusCode == http.StatusGatewayTimeout:
+		return ErrUpstream
+	}
+	return nil
+}
+
+func newApiError(statusCode int, code string, message string) *apiError {
+	result := &apiError{
+		AType:    problemTypeDefault,
+		ATitle:   http.StatusText(statusCode),
 		AStatus:  statusCode,
 		AMessage: message,
+		ACode:    code,
 	}
+	result.kind = result.Kind()
+	if result.ACode == "" && result.kind != nil {
+		result.ACode = result.kind.Error()
+	}
+	return result
 }
 
+func newKindError(kind error, statusCode int, message string, cause error) *apiError {
+	result := newApiError(statusCode, kind.Error(), message)
+	result.kind = kind
+	result.cause = cause
+	return result
+}
+
+func NewApiError(statusCode int, message string) ApiError {
+	return newApiError(statusCode, "", message)
+}
+
+func NewApiErrorWithCode(statusCode int, code string, message string) ApiError {
+	return newApiError(statusCode, code, message)
+}
+
+func NewApiErrorWithCauses(statusCode int, message string, causes ...interface{}) ApiError {
+	result := newApiError(statusCode, "", message)
+	result.ACauses = causes
+	return result
+}
+
+// Wrap returns an ApiError whose kind follows statusCode and whose Unwrap
+// returns cause.
+func Wrap(cause error, statusCode int, message string) ApiError {
+	result := newApiError(statusCode, "", message)
+	result.cause = cause
+	return result
+}
+
+// NewUpstreamError reports a failure of an external dependency. Rate limits
+// reported upstream keep their own kind so callers can back off.
+func NewUpstreamError(cause error, statusCode int, message string) ApiError {
+	if statusCode == http.StatusTooManyRequests ||
+		(statusCode == http.StatusForbidden && strings.Contains(strings.ToLower(message), "rate limit")) {
+		return newKindError(ErrRateLimited, statusCode, message, cause)
+	}
+	if statusCode < http.StatusInternalServerError {
+		return Wrap(cause, statusCode, message)
+	}
+	return newKindError(ErrUpstream, statusCode, message, cause)
+}
+
+// NewApiErrorFromBytes decodes a problem document. Bodies in the former
+// {"message": ...} shape are still understood.
 func NewApiErrorFromBytes(body []byte) (ApiError, error) {
 	var result apiError
 
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, errors.New("invalid json body")
 	}
+	if result.AMessage == "" {
+		var legacy struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &legacy); err == nil {
+			result.AMessage = legacy.Message
+		}
+	}
 	return &result, nil
 }
 
 func NewInternalServerError(message string) ApiError {
-	return &apiError{
-		AStatus:  http.StatusInternalServerError,
-		AMessage: message,
-	}
+	return newApiError(http.StatusInternalServerError, "", message)
 }
 
 func NewNotFoundApiError(message string) ApiError {
-	return &apiError{
-		AStatus:  http.StatusNotFound,
-		AMessage: message,
-	}
+	return newApiError(http.StatusNotFound, "", message)
 }
 
 func NewBadRequestError(message string) ApiError {
-	return &apiError{
-		AStatus:  http.StatusBadRequest,
-		AMessage: message,
-	}
+	return newApiError(http.StatusBadRequest, "", message)
+}
+
+func NewConflictError(message string) ApiError {
+	return newApiError(http.StatusConflict, "", message)
+}
+
+func NewUnauthorizedError(message string) ApiError {
+	return newApiError(http.StatusUnauthorized, "", message)
+}
+
+func NewTooManyRequestsError(message string) ApiError {
+	return newApiError(http.StatusTooManyRequests, "", message)
+}
+
+// NewTooManyRequestsErrorWithRetry reports an exhausted quota that frees up
+// after retryAfter, rounded up to whole seconds.
+func NewTooManyRequestsErrorWithRetry(message string, retryAfter time.Duration) ApiError {
+	result := newApiError(http.StatusTooManyRequests, "", message)
+	result.ARetry = int(math.Ceil(retryAfter.Seconds()))
+	return result
+}
+
+func NewValidationError(message string, causes ...interface{}) ApiError {
+	result := newApiError(http.StatusBadRequest, "", message)
+	result.ACauses = causes
+	return result
 }
@@ -0,0 +1,167 @@
+package errors
+
+import (
+	"encoding/xml"
+	"errors"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/test_utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewApiError(t *testing.T) {
+	err := NewApiError(http.StatusNotFound, "repository not found")
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	assert.EqualValues(t, "repository not found", err.Message())
+	assert.Nil(t, err.Causes())
+}
+
+func TestApiErrorKinds(t *testing.T) {
+	assert.True(t, errors.Is(NewNotFoundApiError("missing"), ErrNotFound))
+	assert.True(t, errors.Is(NewConflictError("name already exists"), ErrConflict))
+	assert.True(t, errors.Is(NewUnauthorizedError("bad credentials"), ErrUnauthorized))
+	assert.True(t, errors.Is(NewApiError(http.StatusForbidden, "forbidden"), ErrUnauthorized))
+	assert.True(t, errors.Is(NewTooManyRequestsError("slow down"), ErrRateLimited))
+	assert.True(t, errors.Is(NewBadRequestError("invalid repository name"), ErrValidation))
+	assert.True(t, errors.Is(NewValidationError("invalid repository name"), ErrValidation))
+	assert.False(t, errors.Is(NewBadRequestError("invalid repository name"), ErrNotFound))
+	assert.False(t, errors.Is(NewInternalServerError("boom"), ErrUpstream))
+}
+
+func TestApiErrorKindFromBytes(t *testing.T) {
+	err, parseErr := NewApiErrorFromBytes([]byte(`{"status":404,"detail":"missing"}`))
+	assert.Nil(t, parseErr)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	err, parseErr = NewApiErrorFromBytes([]byte(`{"status":403,"code":"rate_limited"}`))
+	assert.Nil(t, parseErr)
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.False(t, errors.Is(err, ErrUnauthorized))
+}
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("connection reset by peer")
+	err := Wrap(cause, http.StatusNotFound, "repository not found")
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	assert.EqualValues(t, "repository not found: connection reset by peer", err.Error())
+	assert.True(t, errors.Is(err, cause))
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.EqualValues(t, cause, errors.Unwrap(err))
+}
+
+func TestNewUpstreamError(t *testing.T) {
+	cause := errors.New("Server Error")
+	err := NewUpstreamError(cause, http.StatusInternalServerError, "Server Error")
+	assert.EqualValues(t, http.StatusInternalServerError, err.Status())
+	assert.EqualValues(t, "Server Error", err.Error())
+	assert.True(t, errors.Is(err, ErrUpstream))
+	assert.True(t, errors.Is(err, cause))
+
+	err = NewUpstreamError(cause, http.StatusForbidden, "API rate limit exceeded for user ID 1.")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	assert.False(t, errors.Is(err, ErrUnauthorized))
+
+	err = NewUpstreamError(cause, http.StatusUnauthorized, "Requires authentication")
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+}
+
+func TestApiErrorErrorMessage(t *testing.T) {
+	assert.EqualValues(t, "invalid json body", NewBadRequestError("invalid json body").Error())
+	assert.EqualValues(t, "Not Found", NewApiError(http.StatusNotFound, "").Error())
+
+	var target interface{ Kind() error }
+	assert.True(t, errors.As(NewConflictError("exists"), &target))
+	assert.EqualValues(t, ErrConflict, target.Kind())
+}
+
+func TestNewApiErrorWithCauses(t *testing.T) {
+	err := NewApiErrorWithCauses(http.StatusBadRequest, "invalid repository name", "name is required")
+	assert.EqualValues(t, http.StatusBadRequest, err.Status())
+	assert.EqualValues(t, 1, len(err.Causes()))
+	assert.EqualValues(t, "name is required", err.Causes()[0])
+}
+
+func TestNewApiErrorFromBytesLegacyMessage(t *testing.T) {
+	err, parseErr := NewApiErrorFromBytes([]byte(`{"status":404,"message":"repository not found"}`))
+	assert.Nil(t, parseErr)
+	assert.EqualValues(t, http.StatusNotFound, err.Status())
+	assert.EqualValues(t, "repository not found", err.Message())
+
+	err, parseErr = NewApiErrorFromBytes([]byte(`{"status":404,"detail":"missing","message":"repository not found"}`))
+	assert.Nil(t, parseErr)
+	assert.EqualValues(t, "missing", err.Message())
+}
+
+func TestNewApiErrorFromBytesInvalidJson(t *testing.T) {
+	err, parseErr := NewApiErrorFromBytes([]byte(`{"status":"404"}`))
+	assert.Nil(t, err)
+	assert.NotNil(t, parseErr)
+	assert.EqualValues(t, "invalid json body", parseErr.Error())
+}
+
+func TestRespondErrorProblemJson(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/repository?verbose=true", nil)
+	request.Header.Set("X-Request-Id", "abc-123")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	RespondError(c, NewApiErrorWithCauses(http.StatusBadRequest, "invalid repository name", "name is required"))
+
+	assert.EqualValues(t, http.StatusBadRequest, response.Code)
+	assert.EqualValues(t, ContentTypeProblemJson, response.Header().Get("Content-Type"))
+
+	problem, err := NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, problem.Status())
+	assert.EqualValues(t, "invalid repository name", problem.Message())
+
+	result := problem.(*apiError)
+	assert.EqualValues(t, "about:blank", result.AType)
+	assert.EqualValues(t, "Bad Request", result.ATitle)
+	assert.EqualValues(t, "/repository?verbose=true", result.AInstance)
+	assert.EqualValues(t, "abc-123", result.ARequestId)
+	assert.EqualValues(t, []interface{}{"name is required"}, result.ACauses)
+}
+
+func TestRespondErrorProblemXml(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/users/0", nil)
+	request.Header.Set("Accept", "application/xml")
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	RespondError(c, NewApiErrorWithCode(http.StatusNotFound, "not_found", "user: 0 does not exists!"))
+
+	assert.EqualValues(t, http.StatusNotFound, response.Code)
+	assert.EqualValues(t, ContentTypeProblemXml, response.Header().Get("Content-Type"))
+
+	var result apiError
+	err := xml.Unmarshal(response.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "urn:ietf:rfc:7807", result.XMLName.Space)
+	assert.EqualValues(t, "problem", result.XMLName.Local)
+	assert.EqualValues(t, http.StatusNotFound, result.AStatus)
+	assert.EqualValues(t, "Not Found", result.ATitle)
+	assert.EqualValues(t, "not_found", result.ACode)
+	assert.True(t, errors.Is(&result, ErrNotFound))
+	assert.EqualValues(t, "user: 0 does not exists!", result.AMessage)
+	assert.EqualValues(t, "/users/0", result.AInstance)
+}
+
+func TestRespondErrorRetryAfter(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/repository", nil)
+	response := httptest.NewRecorder()
+	c := test_utils.GetMockedContext(request, response)
+
+	err := NewTooManyRequestsErrorWithRetry("daily quota exceeded", 1500*time.Millisecond)
+	assert.True(t, errors.Is(err, ErrRateLimited))
+	RespondError(c, err)
+
+	assert.EqualValues(t, http.StatusTooManyRequests, response.Code)
+	assert.EqualValues(t, "2", response.Header().Get("Retry-After"))
+	problem, parseErr := NewApiErrorFromBytes(response.Body.Bytes())
+	assert.Nil(t, parseErr)
+	assert.EqualValues(t, 2*time.Second, problem.(*apiError).RetryAfter())
+}
@@ -0,0 +1,65 @@
+package errors
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"net/http"
+	"strconv"
+)
+
+const (
+	ContentTypeProblemJson = "application/problem+json"
+	ContentTypeProblemXml  = "application/problem+xml"
+
+	headerContentType = "Content-Type"
+	headerRequestId   = "X-Request-Id"
+	headerRetryAfter  = "Retry-After"
+)
+
+// RespondError renders err as an application/problem+json document, or as
+// application/problem+xml when the client asks for XML.
+func RespondError(c *gin.Context, err ApiError) {
+	problem := toProblem(err)
+	problem.AInstance = c.Request.URL.RequestURI()
+	problem.ARequestId = c.GetHeader(headerRequestId)
+	if problem.ARetry > 0 {
+		c.Header(headerRetryAfter, strconv.Itoa(problem.ARetry))
+	}
+
+	switch c.NegotiateFormat(binding.MIMEJSON, ContentTypeProblemJson, binding.MIMEXML, binding.MIMEXML2, ContentTypeProblemXml) {
+	case binding.MIMEXML, binding.MIMEXML2, ContentTypeProblemXml:
+		c.Header(headerContentType, ContentTypeProblemXml)
+		c.XML(problem.AStatus, problem)
+	default:
+		c.Header(headerContentType, ContentTypeProblemJson)
+		c.JSON(problem.AStatus, problem)
+	}
+}
+
+// toProblem returns a copy of err that can be decorated with request data
+// without touching the original value.
+func toProblem(err ApiError) *apiError {
+	var problem apiError
+	if current, ok := err.(*apiError); ok {
+		problem = *current
+	} else {
+		problem = apiError{
+			AStatus:  err.Status(),
+			AMessage: err.Message(),
+			ACauses:  err.Causes(),
+			cause:    err,
+		}
+		problem.kind = problem.Kind()
+	}
+
+	if problem.AStatus == 0 {
+		problem.AStatus = http.StatusInternalServerError
+	}
+	if problem.AType == "" {
+		problem.AType = problemTypeDefault
+	}
+	if problem.ATitle == "" {
+		problem.ATitle = http.StatusText(problem.AStatus)
+	}
+	return &problem
+}
@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+
+	headerClientId    = "X-Client-Id"
+	headerContentType = "Content-Type"
+	maxKeyLength      = 255
+	maxBodySize       = 1 << 20
+	storedBodyKey     = "idempotency_stored_body"
+)
+
+// SetStoredBody makes the middleware store body, encoded as JSON, instead of
+// the response the handler writes, for responses holding secrets that must
+// only be returned once.
+func SetStoredBody(c *gin.Context, body interface{}) {
+	c.Set(storedBodyKey, body)
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// Middleware replays the stored outcome of requests sent again with the same
+// Idempotency-Key by the same client. Requests without the header go through.
+func Middleware(store *Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			errors.RespondError(c, errors.NewBadRequestError(fmt.Sprintf("idempotency key must be at most %d characters long", maxKeyLength)))
+			c.Abort()
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+		if err != nil {
+			if len(body) >= maxBodySize {
+				errors.RespondError(c, errors.NewApiError(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must be at most %d bytes long", maxBodySize)))
+			} else {
+				errors.RespondError(c, errors.NewBadRequestError("invalid request body"))
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		storeKey := fmt.Sprintf("%s:%s", c.GetHeader(headerClientId), key)
+		stored, apiErr := store.Begin(c.Request.Context(), storeKey, fingerprint(c, body))
+		if apiErr != nil {
+			errors.RespondError(c, apiErr)
+			c.Abort()
+			return
+		}
+		if stored != nil {
+			c.Header(HeaderReplayed, "true")
+			c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				store.Abandon(storeKey)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// server and upstream failures are usually transient, a retry must
+		// get a chance to succeed
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		body = recorder.body.Bytes()
+		if replacement, exists := c.Get(storedBodyKey); exists {
+			if body, err = json.Marshal(replacement); err != nil {
+				return
+			}
+		}
+		store.Complete(storeKey, Response{
+			StatusCode:  recorder.Status(),
+			ContentType: recorder.Header().Get(headerContentType),
+			Body:        body,
+		})
+		completed = true
+	}
+}
+
+func fingerprint(c *gin.Context, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method))
+	hash.Write([]byte(c.Request.URL.RequestURI()))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,122 @@
+package idempotency
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getRouter(calls *int) *gin.Engine {
+	router := gin.New()
+	router.POST("/repository", Middleware(NewStore(time.Hour)), func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusCreated, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func doRequest(router *gin.Engine, clientId string, key string, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/repository", strings.NewReader(body))
+	request.Header.Set("X-Client-Id", clientId)
+	if key != "" {
+		request.Header.Set(HeaderIdempotencyKey, key)
+	}
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	return response
+}
+
+func TestMiddlewareWithoutKey(t *testing.T) {
+	calls := 0
+	router := getRouter(&calls)
+
+	doRequest(router, "client", "", `{"name":"testing"}`)
+	response := doRequest(router, "client", "", `{"name":"testing"}`)
+	assert.EqualValues(t, 2, calls)
+	assert.EqualValues(t, `{"call":2}`, response.Body.String())
+}
+
+func TestMiddlewareReplaysResponse(t *testing.T) {
+	calls := 0
+	router := getRouter(&calls)
+
+	first := doRequest(router, "client", "key-1", `{"name":"testing"}`)
+	second := doRequest(router, "client", "key-1", `{"name":"testing"}`)
+
+	assert.EqualValues(t, 1, calls)
+	assert.EqualValues(t, http.StatusCreated, second.Code)
+	assert.EqualValues(t, first.Body.String(), second.Body.String())
+	assert.EqualValues(t, "true", second.Header().Get(HeaderReplayed))
+	assert.EqualValues(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+}
+
+func TestMiddlewareKeysAreScopedByClient(t *testing.T) {
+	calls := 0
+	router := getRouter(&calls)
+
+	doRequest(router, "client-a", "key-1", `{"name":"testing"}`)
+	response := doRequest(router, "client-b", "key-1", `{"name":"testing"}`)
+	assert.EqualValues(t, 2, calls)
+	assert.EqualValues(t, "", response.Header().Get(HeaderReplayed))
+}
+
+func TestMiddlewareRejectsDifferentBody(t *testing.T) {
+	calls := 0
+	router := getRouter(&calls)
+
+	doRequest(router, "client", "key-1", `{"name":"testing"}`)
+	response := doRequest(router, "client", "key-1", `{"name":"other"}`)
+	assert.EqualValues(t, 1, calls)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, response.Code)
+	assert.EqualValues(t, "application/problem+json", response.Header().Get("Content-Type"))
+}
+
+func TestMiddlewareDoesNotReplayServerErrors(t *testing.T) {
+	calls := 0
+	router := gin.New()
+	router.POST("/repository", Middleware(NewStore(time.Hour)), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.JSON(http.StatusBadGateway, gin.H{"call": calls})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	first := doRequest(router, "client", "key-1", `{"name":"testing"}`)
+	second := doRequest(router, "client", "key-1", `{"name":"testing"}`)
+
+	assert.EqualValues(t, http.StatusBadGateway, first.Code)
+	assert.EqualValues(t, http.StatusCreated, second.Code)
+	assert.EqualValues(t, "", second.Header().Get(HeaderReplayed))
+	assert.EqualValues(t, 2, calls)
+}
+
+func TestMiddlewareBodyTooLarge(t *testing.T) {
+	calls := 0
+	router := getRouter(&calls)
+
+	response := doRequest(router, "client", "key-1", strings.Repeat("a", maxBodySize+1))
+	assert.EqualValues(t, 0, calls)
+	assert.EqualValues(t, http.StatusRequestEntityTooLarge, response.Code)
+}
+
+func TestMiddlewareStoresReplacedBody(t *testing.T) {
+	router := gin.New()
+	router.POST("/repository", Middleware(NewStore(time.Hour)), func(c *gin.Context) {
+		SetStoredBody(c, gin.H{"private_key": ""})
+		c.JSON(http.StatusCreated, gin.H{"private_key": "secret"})
+	})
+
+	response := doRequest(router, "client", "key", `{"name":"testing"}`)
+	assert.EqualValues(t, `{"private_key":"secret"}`, response.Body.String())
+
+	response = doRequest(router, "client", "key", `{"name":"testing"}`)
+	assert.EqualValues(t, http.StatusCreated, response.Code)
+	assert.EqualValues(t, "true", response.Header().Get(HeaderReplayed))
+	assert.EqualValues(t, `{"private_key":""}`, response.Body.String())
+}
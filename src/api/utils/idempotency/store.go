@@ -0,0 +1,109 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Response is the stored outcome of the first request sent with a key.
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+type entry struct {
+	fingerprint string
+	done        chan struct{}
+	response    *Response
+	expires     time.Time
+}
+
+type Store struct {
+	lock    sync.Mutex
+	ttl     time.Duration
+	entries map[string]*entry
+	now     func() time.Time
+}
+
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:     ttl,
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+}
+
+// Begin registers a request for key. A nil response means the caller owns the
+// key and must call Complete or Abandon once it is done; otherwise the response
+// of the first request is returned, waiting for it if it is still in flight.
+func (s *Store) Begin(ctx context.Context, key string, fingerprint string) (*Response, errors.ApiError) {
+	for {
+		s.lock.Lock()
+		s.purgeExpired()
+
+		current := s.entries[key]
+		if current == nil {
+			s.entries[key] = &entry{
+				fingerprint: fingerprint,
+				done:        make(chan struct{}),
+			}
+			s.lock.Unlock()
+			return nil, nil
+		}
+		s.lock.Unlock()
+
+		if current.fingerprint != fingerprint {
+			return nil, errors.NewApiError(http.StatusUnprocessableEntity, "idempotency key already used with a different request body")
+		}
+
+		select {
+		case <-current.done:
+			if current.response != nil {
+				return current.response, nil
+			}
+			// The first request was abandoned without an outcome, try to take over.
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), http.StatusConflict, "a request with the same idempotency key is still in progress")
+		}
+	}
+}
+
+// Complete stores the outcome for key and releases every request waiting on it.
+func (s *Store) Complete(key string, response Response) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	current := s.entries[key]
+	if current == nil || current.response != nil {
+		return
+	}
+	current.response = &response
+	current.expires = s.now().Add(s.ttl)
+	close(current.done)
+}
+
+func (s *Store) purgeExpired() {
+	now := s.now()
+	for key, current := range s.entries {
+		if current.response != nil && now.After(current.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// Abandon releases key without storing an outcome so that a retry can run.
+func (s *Store) Abandon(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	current := s.entries[key]
+	if current == nil || current.response != nil {
+		return
+	}
+	delete(s.entries, key)
+	close(current.done)
+}
@@ -0,0 +1,91 @@
+package idempotency
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBeginFirstRequestOwnsKey(t *testing.T) {
+	store := NewStore(time.Hour)
+
+	response, err := store.Begin(context.Background(), "client:key", "abc")
+	assert.Nil(t, err)
+	assert.Nil(t, response)
+}
+
+func TestBeginReplaysCompletedResponse(t *testing.T) {
+	store := NewStore(time.Hour)
+	store.Begin(context.Background(), "client:key", "abc")
+	store.Complete("client:key", Response{StatusCode: http.StatusCreated, Body: []byte(`{"id":123}`)})
+
+	response, err := store.Begin(context.Background(), "client:key", "abc")
+	assert.Nil(t, err)
+	assert.NotNil(t, response)
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+	assert.EqualValues(t, `{"id":123}`, string(response.Body))
+}
+
+func TestBeginDifferentFingerprint(t *testing.T) {
+	store := NewStore(time.Hour)
+	store.Begin(context.Background(), "client:key", "abc")
+
+	response, err := store.Begin(context.Background(), "client:key", "def")
+	assert.Nil(t, response)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusUnprocessableEntity, err.Status())
+	assert.EqualValues(t, "idempotency key already used with a different request body", err.Message())
+}
+
+func TestBeginWaitsForInFlightRequest(t *testing.T) {
+	store := NewStore(time.Hour)
+	store.Begin(context.Background(), "client:key", "abc")
+
+	output := make(chan *Response)
+	go func() {
+		response, _ := store.Begin(context.Background(), "client:key", "abc")
+		output <- response
+	}()
+
+	store.Complete("client:key", Response{StatusCode: http.StatusCreated})
+	response := <-output
+	assert.NotNil(t, response)
+	assert.EqualValues(t, http.StatusCreated, response.StatusCode)
+}
+
+func TestBeginCancelledWhileWaiting(t *testing.T) {
+	store := NewStore(time.Hour)
+	store.Begin(context.Background(), "client:key", "abc")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	response, err := store.Begin(ctx, "client:key", "abc")
+	assert.Nil(t, response)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusConflict, err.Status())
+}
+
+func TestAbandonLetsNextRequestOwnKey(t *testing.T) {
+	store := NewStore(time.Hour)
+	store.Begin(context.Background(), "client:key", "abc")
+	store.Abandon("client:key")
+
+	response, err := store.Begin(context.Background(), "client:key", "abc")
+	assert.Nil(t, err)
+	assert.Nil(t, response)
+}
+
+func TestCompletedResponseExpires(t *testing.T) {
+	now := time.Now()
+	store := NewStore(time.Minute)
+	store.now = func() time.Time { return now }
+	store.Begin(context.Background(), "client:key", "abc")
+	store.Complete("client:key", Response{StatusCode: http.StatusCreated})
+
+	now = now.Add(2 * time.Minute)
+	response, err := store.Begin(context.Background(), "client:key", "def")
+	assert.Nil(t, err)
+	assert.Nil(t, response)
+}
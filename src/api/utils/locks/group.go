@@ -0,0 +1,83 @@
+package locks
+
+import (
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"runtime/debug"
+	"sync"
+)
+
+type call struct {
+	done  chan struct{}
+	value interface{}
+	err   errors.ApiError
+	// panic is set when the call panicked instead of returning.
+	panic *PanicError
+	// waiters counts the callers waiting for the outcome of the call.
+	waiters int
+}
+
+// PanicError is what callers waiting for a call that panicked panic with.
+// Stack is the one of the goroutine that made the call.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v\n\n%s", e.Value, e.Stack)
+}
+
+// Group coalesces concurrent calls made with the same key into a single one
+// whose outcome every caller gets. Calls made once it has returned run again.
+// The zero value is ready to use.
+type Group struct {
+	lock  sync.Mutex
+	calls map[string]*call
+}
+
+// Do runs fn unless a call with the same key is already in flight, in which
+// case it waits for that call and returns its outcome. shared reports whether
+// the outcome is the one of a call made by another caller. When fn panics,
+// the caller that made the call panics again with the same value and every
+// waiting caller panics with a *PanicError.
+func (g *Group) Do(key string, fn func() (interface{}, errors.ApiError)) (value interface{}, err errors.ApiError, shared bool) {
+	g.lock.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if current, inFlight := g.calls[key]; inFlight {
+		current.waiters++
+		g.lock.Unlock()
+		<-current.done
+		if current.panic != nil {
+			panic(current.panic)
+		}
+		return current.value, current.err, true
+	}
+	current := &call{done: make(chan struct{})}
+	g.calls[key] = current
+	g.lock.Unlock()
+
+	returned := false
+	defer func() {
+		if !returned {
+			if recovered := recover(); recovered != nil {
+				current.panic = &PanicError{Value: recovered, Stack: debug.Stack()}
+			} else {
+				// runtime.Goexit stopped the call
+				current.err = errors.NewInternalServerError("call stopped before returning")
+			}
+		}
+		g.lock.Lock()
+		delete(g.calls, key)
+		g.lock.Unlock()
+		close(current.done)
+		if current.panic != nil {
+			panic(current.panic.Value)
+		}
+	}()
+	current.value, current.err = fn()
+	returned = true
+	return current.value, current.err, false
+}
@@ -0,0 +1,127 @@
+package locks
+
+import (
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestGroupCoalescesConcurrentCalls(t *testing.T) {
+	var g Group
+	release := make(chan bool)
+	calls := 0
+
+	leader := make(chan interface{})
+	go func() {
+		value, _, shared := g.Do("EBKopec/testing", func() (interface{}, errors.ApiError) {
+			calls++
+			<-release
+			return 123, nil
+		})
+		assert.False(t, shared)
+		leader <- value
+	}()
+
+	waitFor(&g, "EBKopec/testing", 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err, shared := g.Do("EBKopec/testing", func() (interface{}, errors.ApiError) {
+				calls++
+				return 456, nil
+			})
+			assert.Nil(t, err)
+			assert.True(t, shared)
+			assert.EqualValues(t, 123, value)
+		}()
+	}
+
+	waitFor(&g, "EBKopec/testing", 10)
+	close(release)
+	assert.EqualValues(t, 123, <-leader)
+	wg.Wait()
+	assert.EqualValues(t, 1, calls)
+}
+
+func TestGroupSharesErrors(t *testing.T) {
+	var g Group
+	value, err, shared := g.Do("EBKopec/testing", func() (interface{}, errors.ApiError) {
+		return nil, errors.NewConflictError("repository EBKopec/testing already exists")
+	})
+
+	assert.Nil(t, value)
+	assert.False(t, shared)
+	assert.EqualValues(t, http.StatusConflict, err.Status())
+}
+
+func TestGroupRunsAgainOnceReturned(t *testing.T) {
+	var g Group
+	calls := 0
+	for i := 0; i < 2; i++ {
+		_, _, shared := g.Do("EBKopec/testing", func() (interface{}, errors.ApiError) {
+			calls++
+			return nil, nil
+		})
+		assert.False(t, shared)
+	}
+	assert.EqualValues(t, 2, calls)
+}
+
+func TestGroupPanicsInEveryCaller(t *testing.T) {
+	var g Group
+	release := make(chan bool)
+
+	leader := make(chan interface{})
+	go func() {
+		defer func() { leader <- recover() }()
+		g.Do("EBKopec/testing", func() (interface{}, errors.ApiError) {
+			<-release
+			panic("boom")
+		})
+	}()
+	waitFor(&g, "EBKopec/testing", 0)
+
+	waiter := make(chan interface{})
+	go func() {
+		defer func() { waiter <- recover() }()
+		g.Do("EBKopec/testing", func() (interface{}, errors.ApiError) {
+			return 456, nil
+		})
+	}()
+	waitFor(&g, "EBKopec/testing", 1)
+	close(release)
+
+	assert.EqualValues(t, "boom", <-leader)
+	recovered, isPanicError := (<-waiter).(*PanicError)
+	assert.True(t, isPanicError)
+	assert.EqualValues(t, "boom", recovered.Value)
+	assert.NotEmpty(t, recovered.Stack)
+
+	value, err, shared := g.Do("EBKopec/testing", func() (interface{}, errors.ApiError) {
+		return 789, nil
+	})
+	assert.Nil(t, err)
+	assert.False(t, shared)
+	assert.EqualValues(t, 789, value)
+}
+
+// waitFor returns once the call made with key is in flight with waiters
+// callers waiting for it.
+func waitFor(g *Group, key string, waiters int) {
+	for {
+		g.lock.Lock()
+		current, inFlight := g.calls[key]
+		ready := inFlight && current.waiters == waiters
+		g.lock.Unlock()
+		if ready {
+			return
+		}
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,54 @@
+package locks
+
+import (
+	"sync"
+)
+
+type keyedEntry struct {
+	lock sync.Mutex
+	// holders counts the goroutines holding or waiting for lock, so the entry
+	// is dropped once nobody needs it anymore.
+	holders int
+}
+
+// KeyedMutex is a set of mutexes, one per key, created on first use. Holding
+// the lock of a key never blocks other keys. The zero value is ready to use.
+type KeyedMutex struct {
+	lock    sync.Mutex
+	entries map[string]*keyedEntry
+}
+
+// Lock waits until the lock of key is free and returns the function releasing
+// it, which must be called exactly once.
+func (m *KeyedMutex) Lock(key string) func() {
+	m.lock.Lock()
+	if m.entries == nil {
+		m.entries = make(map[string]*keyedEntry)
+	}
+	entry, exists := m.entries[key]
+	if !exists {
+		entry = &keyedEntry{}
+		m.entries[key] = entry
+	}
+	entry.holders++
+	m.lock.Unlock()
+
+	entry.lock.Lock()
+	return func() {
+		entry.lock.Unlock()
+
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		entry.holders--
+		if entry.holders == 0 {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// Len returns the number of keys currently locked or waited for.
+func (m *KeyedMutex) Len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.entries)
+}
@@ -0,0 +1,66 @@
+package locks
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeyedMutexSerializesSameKey(t *testing.T) {
+	var m KeyedMutex
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := m.Lock("EBKopec/testing")
+			defer unlock()
+			counter++
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 100, counter)
+	assert.EqualValues(t, 0, m.Len())
+}
+
+func TestKeyedMutexOtherKeysDoNotWait(t *testing.T) {
+	var m KeyedMutex
+	unlock := m.Lock("EBKopec/testing")
+	defer unlock()
+
+	done := make(chan bool)
+	go func() {
+		m.Lock("EBKopec/other")()
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("lock of another key blocked")
+	}
+	assert.EqualValues(t, 1, m.Len())
+}
+
+func TestKeyedMutexWaitsForHolder(t *testing.T) {
+	var m KeyedMutex
+	unlock := m.Lock("EBKopec/testing")
+
+	acquired := make(chan bool)
+	go func() {
+		m.Lock("EBKopec/testing")()
+		acquired <- true
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while held")
+	case <-time.After(10 * time.Millisecond):
+	}
+	unlock()
+	<-acquired
+	assert.EqualValues(t, 0, m.Len())
+}
@@ -0,0 +1,156 @@
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/crypto"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+// fileContent is what is written to disk. KeyId identifies the master key the
+// secrets are encrypted with, so opening the file with another key fails
+// clearly instead of on the first read.
+type fileContent struct {
+	KeyId   string            `json:"key_id"`
+	Secrets map[string][]byte `json:"secrets"`
+}
+
+type fileStore struct {
+	lock    sync.RWMutex
+	path    string
+	key     []byte
+	secrets map[string][]byte
+}
+
+// NewFileStore opens the secrets file at path, creating it on the first Set.
+func NewFileStore(path string, key []byte) (Store, error) {
+	result := &fileStore{path: path, key: key, secrets: make(map[string][]byte)}
+
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var content fileContent
+	if err := json.Unmarshal(bytes, &content); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", path, err)
+	}
+	if content.KeyId != keyId(key) {
+		return nil, fmt.Errorf("secrets file %s is encrypted with another master key", path)
+	}
+	if content.Secrets != nil {
+		result.secrets = content.Secrets
+	}
+	return result, nil
+}
+
+func (s *fileStore) Get(name string) (string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	sealed, exists := s.secrets[name]
+	if !exists {
+		return "", ErrNotFound
+	}
+	plaintext, err := crypto.Decrypt(s.key, sealed, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("error when trying to decrypt secret %s: %w", name, err)
+	}
+	return string(plaintext), nil
+}
+
+func (s *fileStore) Set(name string, value string) error {
+	if name == "" {
+		return errors.New("secret name is required")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	sealed, err := crypto.Encrypt(s.key, []byte(value), []byte(name))
+	if err != nil {
+		return err
+	}
+	secrets := s.copySecrets()
+	secrets[name] = sealed
+	return s.write(s.key, secrets)
+}
+
+func (s *fileStore) Delete(name string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, exists := s.secrets[name]; !exists {
+		return ErrNotFound
+	}
+	secrets := s.copySecrets()
+	delete(secrets, name)
+	return s.write(s.key, secrets)
+}
+
+// Rotate decrypts every secret first, so nothing is written when any of them
+// can not be read, then writes them all encrypted under newKey at once.
+func (s *fileStore) Rotate(newKey []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	names := make([]string, 0, len(s.secrets))
+	for name := range s.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	secrets := make(map[string][]byte, len(s.secrets))
+	for _, name := range names {
+		plaintext, err := crypto.Decrypt(s.key, s.secrets[name], []byte(name))
+		if err != nil {
+			return fmt.Errorf("error when trying to decrypt secret %s: %w", name, err)
+		}
+		if secrets[name], err = crypto.Encrypt(newKey, plaintext, []byte(name)); err != nil {
+			return err
+		}
+	}
+	return s.write(newKey, secrets)
+}
+
+func (s *fileStore) copySecrets() map[string][]byte {
+	result := make(map[string][]byte, len(s.secrets)+1)
+	for name, sealed := range s.secrets {
+		result[name] = sealed
+	}
+	return result
+}
+
+// write replaces the file through a temporary one, so a crash never leaves a
+// truncated file behind, and only then swaps the state in memory.
+func (s *fileStore) write(key []byte, secrets map[string][]byte) error {
+	bytes, err := json.MarshalIndent(fileContent{KeyId: keyId(key), Secrets: secrets}, "", "  ")
+	if err != nil {
+		return err
+	}
+	temporary := s.path + ".tmp"
+	if err := ioutil.WriteFile(temporary, bytes, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(temporary, s.path); err != nil {
+		return err
+	}
+	s.key = key
+	s.secrets = secrets
+	return nil
+}
+
+// keyId is a short fingerprint of key that does not reveal it.
+func keyId(key []byte) string {
+	sum := sha256.Sum256(append([]byte("secrets-key-id:"), key...))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/crypto"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStoreSetGet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	key, _ := crypto.NewKey()
+	store, err := NewFileStore(path, key)
+	assert.Nil(t, err)
+
+	value, err := store.Get("github_access_token")
+	assert.EqualValues(t, "", value)
+	assert.EqualValues(t, ErrNotFound, err)
+
+	assert.Nil(t, store.Set("github_access_token", "ghp_secret"))
+	bytes, _ := ioutil.ReadFile(path)
+	assert.NotContains(t, string(bytes), "ghp_secret")
+
+	reopened, err := NewFileStore(path, key)
+	assert.Nil(t, err)
+	value, err = reopened.Get("github_access_token")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "ghp_secret", value)
+
+	assert.Nil(t, reopened.Delete("github_access_token"))
+	assert.EqualValues(t, ErrNotFound, reopened.Delete("github_access_token"))
+	_, err = reopened.Get("github_access_token")
+	assert.EqualValues(t, ErrNotFound, err)
+}
+
+func TestFileStoreWrongKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	key, _ := crypto.NewKey()
+	store, _ := NewFileStore(path, key)
+	assert.Nil(t, store.Set("token", "value"))
+
+	otherKey, _ := crypto.NewKey()
+	store, err := NewFileStore(path, otherKey)
+	assert.Nil(t, store)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "encrypted with another master key")
+}
+
+func TestFileStoreRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	oldKey, _ := crypto.NewKey()
+	store, _ := NewFileStore(path, oldKey)
+	assert.Nil(t, store.Set("first", "one"))
+	assert.Nil(t, store.Set("second", "two"))
+
+	newKey, _ := crypto.NewKey()
+	assert.Nil(t, store.Rotate(newKey))
+
+	value, err := store.Get("first")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "one", value)
+
+	_, err = NewFileStore(path, oldKey)
+	assert.NotNil(t, err)
+
+	reopened, err := NewFileStore(path, newKey)
+	assert.Nil(t, err)
+	value, err = reopened.Get("second")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "two", value)
+}
+
+func TestLoadMasterKey(t *testing.T) {
+	key, _ := crypto.NewKey()
+	encoded := base64.StdEncoding.EncodeToString(key)
+
+	loaded, err := LoadMasterKey(encoded, "")
+	assert.Nil(t, err)
+	assert.EqualValues(t, key, loaded)
+
+	keyFile := filepath.Join(t.TempDir(), "master.key")
+	ioutil.WriteFile(keyFile, []byte(encoded+"\n"), 0600)
+	loaded, err = LoadMasterKey("", keyFile)
+	assert.Nil(t, err)
+	assert.EqualValues(t, key, loaded)
+
+	_, err = LoadMasterKey("", "")
+	assert.EqualValues(t, "master key is not set", err.Error())
+
+	_, err = LoadMasterKey("", filepath.Join(t.TempDir(), "missing.key"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,40 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"github.com/evertonkopec/golang-microservices-main/src/api/utils/crypto"
+	"io/ioutil"
+	"strings"
+)
+
+var (
+	ErrNotFound = errors.New("secret not found")
+)
+
+// Store keeps secrets encrypted under a master key.
+type Store interface {
+	// Get returns the plaintext of the secret, ErrNotFound when it is not set.
+	Get(name string) (string, error)
+	Set(name string, value string) error
+	Delete(name string) error
+	// Rotate encrypts every secret again under newKey, which replaces the
+	// current master key.
+	Rotate(newKey []byte) error
+}
+
+// LoadMasterKey returns the base64 encoded AES-256 key given as value or, when
+// value is empty, stored in keyFile.
+func LoadMasterKey(value string, keyFile string) ([]byte, error) {
+	if value == "" && keyFile != "" {
+		bytes, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error when trying to read master key file: %w", err)
+		}
+		value = string(bytes)
+	}
+	if value == "" {
+		return nil, errors.New("master key is not set")
+	}
+	return crypto.DecodeKey(strings.TrimSpace(value))
+}